@@ -0,0 +1,4 @@
+// Package userv1 contains the protobuf definitions and generated gRPC code for the user api.
+package userv1
+
+//go:generate protoc -I ../../.. --go_out=../../.. --go_opt=paths=source_relative --go-grpc_out=../../.. --go-grpc_opt=paths=source_relative api/user/v1/user.proto
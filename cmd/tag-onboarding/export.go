@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/mongodb"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+)
+
+// exportUsers streams the users of the configured repository to a CSV, NDJSON or Parquet file
+func exportUsers(ctx context.Context, cfg config.Config, args []string, stdout io.Writer) (err error) {
+	flags := newFlagSet("export", "[-format csv|ndjson|parquet] [-o FILE] [-first-name NAME] [-last-name NAME] [-email EMAIL] [-status STATUS]")
+	formatName := flags.String("format", "", "file format, inferred from the -o extension when empty and csv for stdout")
+	output := flags.String("o", "-", "output file, - for stdout")
+	var filter userEntity.ListFilter
+	flags.StringVar(&filter.FirstName, "first-name", "", "only export users with this first name")
+	flags.StringVar(&filter.LastName, "last-name", "", "only export users with this last name")
+	flags.StringVar(&filter.Email, "email", "", "only export users with this email")
+	flags.Func("status", "only export users with this status", func(value string) (err error) {
+		filter.Status, err = userEntity.ParseStatus(value)
+		return err
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		flags.Usage()
+		return errors.New("unexpected arguments")
+	}
+
+	if *formatName == "" && *output == "-" {
+		*formatName = string(bulk.FormatCSV)
+	}
+	format, err := fileFormat(*formatName, *output)
+	if err != nil {
+		return err
+	}
+
+	client, err := connectMongo(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close(context.Background())
+	cursor, err := mongodb.NewRepository(client).Stream(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	out := stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+		out = file
+	}
+	buffered := bufio.NewWriter(out)
+
+	count, err := writeUsers(ctx, cursor, format, buffered, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+	if *output != "-" {
+		fmt.Fprintf(stdout, "exported %d users to %s\n", count, *output)
+	}
+	return nil
+}
+
+func writeUsers(ctx context.Context, cursor userEntity.Cursor, format bulk.Format, w io.Writer, now time.Time) (int, error) {
+	writer, err := bulk.NewWriter(format, w, now)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for cursor.Next(ctx) {
+		if err := writer.Write(cursor.User()); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
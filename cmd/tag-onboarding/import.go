@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/mongodb"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+)
+
+// cliActor is the audit actor of changes made by the CLI
+const cliActor = "cli"
+
+// errRowsRejected is returned when an import rejected at least one row so scripts can detect partial imports
+var errRowsRejected = errors.New("some rows were rejected")
+
+// importUsers imports the users of a CSV or NDJSON file into the configured repository
+func importUsers(ctx context.Context, cfg config.Config, args []string, stdout io.Writer) error {
+	flags := newFlagSet("import", "[-format csv|ndjson] [-dry-run] FILE")
+	formatName := flags.String("format", "", "file format, inferred from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "validate the rows without saving them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("expected exactly one FILE, use - for stdin")
+	}
+	path := flags.Arg(0)
+
+	format, err := fileFormat(*formatName, path)
+	if err != nil {
+		return err
+	}
+	input, err := openInput(path)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	reader, err := newImportRowReader(format, input)
+	if err != nil {
+		return err
+	}
+
+	validationService, err := newValidationService(cfg)
+	if err != nil {
+		return err
+	}
+	client, err := connectMongo(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close(context.Background())
+	transactor, err := newTransactor(ctx, cfg, client)
+	if err != nil {
+		return err
+	}
+	userService := userApplication.NewService(validationService,
+		mongodb.NewRepository(client), mongodb.NewAuditRepository(client), mongodb.NewOutbox(client), transactor,
+		userApplication.WithDuplicateDetection(cfg.Duplicates))
+
+	ctx = shared.WithActor(ctx, cliActor)
+	if requestID, err := newRequestID(); err == nil {
+		ctx = shared.WithRequestID(ctx, requestID)
+	}
+	report, err := userService.Import(ctx, reader, *dryRun)
+	if err != nil {
+		return err
+	}
+	printImportReport(stdout, report)
+	if report.Rejected > 0 {
+		return errRowsRejected
+	}
+	return nil
+}
+
+func newImportRowReader(format bulk.Format, r io.Reader) (userApplication.ImportRowReader, error) {
+	switch format {
+	case bulk.FormatCSV:
+		return bulk.NewCSVReader(r), nil
+	case bulk.FormatNDJSON:
+		return bulk.NewNDJSONReader(r), nil
+	default:
+		return nil, fmt.Errorf("importing %s files is not supported, use csv or ndjson", format)
+	}
+}
+
+func printImportReport(w io.Writer, report *userApplication.ImportReport) {
+	for _, row := range report.Rows {
+		for _, importError := range row.Errors {
+			fmt.Fprintf(w, "line %d: %s: %s\n", row.Line, importError.Code, importError.Message)
+		}
+		for _, warning := range row.Warnings {
+			fmt.Fprintf(w, "line %d: warning: %s: %s\n", row.Line, warning.Code, warning.Message)
+		}
+	}
+	mode := ""
+	if report.DryRun {
+		mode = " (dry run)"
+	}
+	fmt.Fprintf(w, "created %d, updated %d, rejected %d%s\n", report.Created, report.Updated, report.Rejected, mode)
+}
+
+// fileFormat returns the named format, or the format matching the file extension when name is empty
+func fileFormat(name string, path string) (bulk.Format, error) {
+	if name == "" {
+		name = strings.TrimPrefix(filepath.Ext(path), ".")
+		if name == "jsonl" {
+			name = string(bulk.FormatNDJSON)
+		}
+		if name == "" {
+			return "", fmt.Errorf("cannot infer the format of %q, set -format", path)
+		}
+	}
+	return bulk.ParseFormat(strings.ToLower(name))
+}
+
+// openInput opens the file at path, or stdin for -
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// newRequestID generates the request ID grouping the audit records of a command run
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
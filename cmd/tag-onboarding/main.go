@@ -1,57 +1,95 @@
+// Command tag-onboarding serves the user API and runs maintenance tasks against the configured storage.
 package main
 
 import (
 	"context"
-	"log"
-	"net/http"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
-	"time"
-
-	"github.com/gorilla/mux"
-	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
-	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
-	userInfra "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/in-memory"
-	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/mongodb"
-	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/middleware"
-	userInterface "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/user"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
 )
 
-func main() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	// config
-	uri := os.Getenv("MONGO_URI")
-	var userRepository userEntity.Repository
-
-	collection := os.Getenv("MONGO_COLLECTION")
-	if collection == "" {
-		log.Default().Println("MONGO_COLLECTION is not set, defaulting to user")
-		collection = "user"
-	}
-	client, err := mongodb.NewMongoDBClient(ctx, uri, collection)
-	if err != nil {
-		log.Default().Printf("Failed to create MongoDB client: %v\n Defaulting to inmemory storage", err)
-		userRepository = userInfra.NewRepository()
-	} else {
+// command is a subcommand of the CLI
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, cfg config.Config, args []string, stdout io.Writer) error
+}
 
-		userRepository = mongodb.NewRepository(client)
-	}
+var commands = []command{
+	{name: "serve", summary: "start the HTTP and gRPC servers (default)", run: serve},
+	{name: "import", summary: "import users from a CSV or NDJSON file", run: importUsers},
+	{name: "export", summary: "export users to a CSV, NDJSON or Parquet file", run: exportUsers},
+	{name: "validate", summary: "validate users in JSON files and print the error codes", run: validateUsers},
+	{name: "migrate", summary: "apply pending storage migrations", run: migrate},
+}
 
-	// services
-	userValidationService := userEntity.NewValidationService()
-	userService := userApplication.NewService(userValidationService, userRepository)
-	userHandler := userInterface.NewHandler(userService)
+func main() {
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage(os.Stdout)
+		return
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
 
-	// HTTP Server Setup
-	mux := mux.NewRouter()
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
 
-	// Middleware Setup - Apply before routes
-	mux.Use(middleware.RequestLogger)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err = cmd.run(ctx, cfg, args, os.Stdout)
+	stop()
+	switch {
+	case errors.Is(err, flag.ErrHelp):
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
 
-	userHandler.Find().AddRoute(mux)
-	userHandler.Save().AddRoute(mux)
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
 
-	log.Println("Starting HTTP server on port 8080")
-	http.ListenAndServe(":8080", mux)
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tag-onboarding <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'tag-onboarding <command> -h' for the flags of a command.")
+	fmt.Fprintln(w, "All commands are configured through the environment, see the Readme.")
+}
 
+// newFlagSet creates the flag set of a command, errors are returned rather than exiting
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: tag-onboarding %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+	return flags
 }
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/mongodb"
+)
+
+// migrate applies the pending MongoDB migrations, in-memory storage needs none
+func migrate(ctx context.Context, cfg config.Config, args []string, stdout io.Writer) error {
+	flags := newFlagSet("migrate", "")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return errors.New("unexpected arguments")
+	}
+
+	client, err := connectMongo(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer client.Close(context.Background())
+
+	ran, err := mongodb.Migrate(ctx, client, newMigrations(cfg))
+	for _, migration := range ran {
+		fmt.Fprintf(stdout, "applied %d: %s\n", migration.Version, migration.Description)
+	}
+	if err != nil {
+		return err
+	}
+	if len(ran) == 0 {
+		fmt.Fprintln(stdout, "no pending migrations")
+	}
+	return nil
+}
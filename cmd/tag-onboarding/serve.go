@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/outbox"
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	verificationApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/verification"
+	webhookApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/webhook"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	webhookEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/mail"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/messaging"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/cache"
+	userInfra "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/in-memory"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/mongodb"
+	grpcInterface "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/grpc"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/middleware"
+	userInterface "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/user"
+	verificationInterface "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/verification"
+	webhookInterface "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/webhook"
+	"google.golang.org/grpc"
+)
+
+// serve starts the HTTP and gRPC servers and blocks until either fails or ctx is done
+func serve(ctx context.Context, cfg config.Config, args []string, _ io.Writer) error {
+	if err := newFlagSet("serve", "").Parse(args); err != nil {
+		return err
+	}
+
+	var userRepository userEntity.Repository
+	var auditRepository userEntity.AuditRepository
+	var eventOutbox userEntity.Outbox
+	var transactor shared.Transactor
+	var subscriptionRepository webhookEntity.SubscriptionRepository
+	var deliveryRepository webhookEntity.DeliveryRepository
+	connectCtx, cancel := context.WithTimeout(ctx, connectTimeout)
+	client, err := mongodb.NewMongoDBClient(connectCtx, cfg.MongoURI, cfg.MongoCollection)
+	cancel()
+	if err != nil {
+		log.Default().Printf("Failed to create MongoDB client: %v\n Defaulting to inmemory storage", err)
+		userRepository = userInfra.NewRepository()
+		auditRepository = userInfra.NewAuditRepository()
+		eventOutbox = userInfra.NewOutbox()
+		transactor = userInfra.NewTransactor(userRepository, auditRepository, eventOutbox)
+		subscriptionRepository = userInfra.NewWebhookSubscriptionRepository()
+		deliveryRepository = userInfra.NewWebhookDeliveryRepository()
+	} else {
+		defer client.Close(context.Background())
+		if transactor, err = newTransactor(ctx, cfg, client); err != nil {
+			return err
+		}
+		// the repositories rely on the indexes and fields of every migration
+		ran, err := mongodb.Migrate(ctx, client, newMigrations(cfg))
+		for _, migration := range ran {
+			log.Default().Printf("Applied migration %d: %s\n", migration.Version, migration.Description)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply the pending migrations: %w", err)
+		}
+		userRepository = mongodb.NewRepository(client)
+		auditRepository = mongodb.NewAuditRepository(client)
+		eventOutbox = mongodb.NewOutbox(client)
+		subscriptionRepository = mongodb.NewWebhookSubscriptionRepository(client)
+		deliveryRepository = mongodb.NewWebhookDeliveryRepository(client)
+	}
+
+	if cfg.Cache.Enabled {
+		cachedRepository := cache.NewRepository(userRepository, cache.Config{
+			Size:        cfg.Cache.Size,
+			TTL:         cfg.Cache.TTL,
+			NegativeTTL: cfg.Cache.NegativeTTL,
+		})
+		expvar.Publish("user_cache", expvar.Func(func() any { return cachedRepository.Stats() }))
+		userRepository = cachedRepository
+		transactor = cache.NewTransactor(transactor, cachedRepository)
+	}
+
+	// services
+	userValidationService, err := newValidationService(cfg)
+	if err != nil {
+		return err
+	}
+	userService := userApplication.NewService(userValidationService, userRepository, auditRepository, eventOutbox, transactor,
+		userApplication.WithDuplicateDetection(cfg.Duplicates))
+	userHandlerOptions := []userInterface.HandlerOption{userInterface.WithCacheControl(cfg.UserCacheControl)}
+	if cfg.RejectedValues {
+		userHandlerOptions = append(userHandlerOptions, userInterface.WithRejectedValues())
+	}
+	userHandler := userInterface.NewHandler(userService, userHandlerOptions...)
+	webhookService := webhookApplication.NewService(subscriptionRepository, deliveryRepository)
+	webhookHandler := webhookInterface.NewHandler(webhookService)
+	verificationSecret, err := newVerificationSecret(cfg)
+	if err != nil {
+		return err
+	}
+	verificationService := verificationApplication.NewService(userService, newMailer(cfg), verificationApplication.Config{
+		Secret: verificationSecret,
+		TTL:    cfg.EmailVerification.TTL,
+		URL:    cfg.EmailVerification.URL,
+		From:   cfg.Mail.From,
+	})
+	verificationHandler := verificationInterface.NewHandler(verificationService)
+
+	// events
+	// in-process subscribers register on the event bus
+	eventBus := messaging.NewInProcessPublisher()
+	eventBus.Subscribe(webhookService.HandleEvent)
+	publishers := []outbox.Publisher{eventBus}
+	if cfg.Outbox.LogEvents {
+		publishers = append(publishers, messaging.NewLogPublisher(nil))
+	}
+	relay := outbox.NewRelay(eventOutbox, outbox.RelayConfig{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+	}, publishers...)
+	dispatcher := webhookApplication.NewDispatcher(subscriptionRepository, deliveryRepository, nil, webhookApplication.DispatcherConfig{
+		PollInterval:   cfg.Webhooks.PollInterval,
+		Timeout:        cfg.Webhooks.Timeout,
+		MaxAttempts:    cfg.Webhooks.MaxAttempts,
+		InitialBackoff: cfg.Webhooks.InitialBackoff,
+		MaxBackoff:     cfg.Webhooks.MaxBackoff,
+	})
+
+	// HTTP Server Setup
+	mux := mux.NewRouter()
+
+	// Middleware Setup - Apply before routes
+	mux.Use(middleware.RequestID)
+	mux.Use(middleware.RequestLogger)
+	mux.Use(middleware.Actor)
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		middleware.NewCORS(middleware.CORSConfig{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   cfg.CORS.ExposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		}).Register(mux)
+	}
+
+	userHandler.Find().AddRoute(mux)
+	userHandler.Save().AddRoute(mux)
+	userHandler.Import().AddRoute(mux)
+	userHandler.Export().AddRoute(mux)
+	userHandler.Validate().AddRoute(mux)
+	userHandler.Replace().AddRoute(mux)
+	userHandler.Patch().AddRoute(mux)
+	userHandler.Delete().AddRoute(mux)
+	userHandler.AuditTrail().AddRoute(mux)
+	userHandler.List().AddRoute(mux)
+	userHandler.Verify().AddRoute(mux)
+	userHandler.Activate().AddRoute(mux)
+	userHandler.Suspend().AddRoute(mux)
+	userHandler.Offboard().AddRoute(mux)
+	userHandler.StatusHistory().AddRoute(mux)
+	userHandler.Duplicates().AddRoute(mux)
+	userHandler.Merge().AddRoute(mux)
+	verificationHandler.SendVerification().AddRoute(mux)
+	verificationHandler.Verify().AddRoute(mux)
+	webhookHandler.Create().AddRoute(mux)
+	webhookHandler.List().AddRoute(mux)
+	webhookHandler.Find().AddRoute(mux)
+	webhookHandler.Delete().AddRoute(mux)
+	webhookHandler.Deliveries().AddRoute(mux)
+
+	// gRPC Server Setup
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcInterface.RequestContextInterceptor))
+	grpcInterface.NewServer(userService).Register(grpcServer)
+
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		return fmt.Errorf("failed to listen on gRPC port %s: %w", cfg.GRPCPort, err)
+	}
+	httpServer := &http.Server{Addr: ":" + cfg.Port, Handler: mux}
+	// the cache counters are served on an internal listener, away from the public routes
+	var adminServer *http.Server
+	if cfg.Cache.Enabled && cfg.AdminAddr != "" {
+		adminMux := http.NewServeMux()
+		adminMux.Handle("GET /debug/vars", expvar.Handler())
+		adminServer = &http.Server{Addr: cfg.AdminAddr, Handler: adminMux}
+	}
+
+	relayCtx, stopRelay := context.WithCancel(ctx)
+	defer stopRelay()
+	go relay.Run(relayCtx)
+	go dispatcher.Run(relayCtx)
+
+	errs := make(chan error, 3)
+	go func() {
+		log.Printf("Starting gRPC server on port %s", cfg.GRPCPort)
+		errs <- grpcServer.Serve(grpcListener)
+	}()
+	go func() {
+		log.Printf("Starting HTTP server on port %s", cfg.Port)
+		errs <- httpServer.ListenAndServe()
+	}()
+	if adminServer != nil {
+		go func() {
+			log.Printf("Starting admin server on %s", cfg.AdminAddr)
+			errs <- adminServer.ListenAndServe()
+		}()
+	}
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+		log.Printf("Shutting down")
+	}
+	grpcServer.Stop()
+	httpServer.Close()
+	if adminServer != nil {
+		adminServer.Close()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// newMailer creates the configured mailer, config.Load rejects unknown ones
+func newMailer(cfg config.Config) shared.Mailer {
+	switch cfg.Mail.Mailer {
+	case config.MailerSMTP:
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Addr:     cfg.Mail.SMTPAddr,
+			Username: cfg.Mail.SMTPUsername,
+			Password: cfg.Mail.SMTPPassword,
+		})
+	case config.MailerMemory:
+		return mail.NewInMemoryMailer()
+	default:
+		return mail.NewFileMailer(cfg.Mail.Dir)
+	}
+}
+
+// newVerificationSecret returns the configured secret signing the verification tokens, or a random one
+func newVerificationSecret(cfg config.Config) ([]byte, error) {
+	if cfg.EmailVerification.Secret != "" {
+		return []byte(cfg.EmailVerification.Secret), nil
+	}
+	log.Default().Printf("EMAIL_VERIFICATION_SECRET is not set, verification links sent before a restart will be invalid")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate the email verification secret: %w", err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/infrastructure/persistence/mongodb"
+)
+
+// connectTimeout bounds how long connecting to MongoDB may take
+const connectTimeout = 10 * time.Second
+
+// connectMongo connects to the configured MongoDB and checks it is reachable.
+// Unlike serve, the maintenance commands never fall back to in-memory storage as their changes would be lost.
+func connectMongo(ctx context.Context, cfg config.Config) (*mongodb.MongoDBClient, error) {
+	if cfg.MongoURI == "" {
+		return nil, errors.New("MONGO_URI is not set")
+	}
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	client, err := mongodb.NewMongoDBClient(ctx, cfg.MongoURI, cfg.MongoCollection)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.GetClient().Ping(ctx, nil); err != nil {
+		client.Close(context.Background())
+		return nil, fmt.Errorf("failed to reach MongoDB: %w", err)
+	}
+	return client, nil
+}
+
+// newTransactor creates the MongoDB transactor, refusing a standalone server unless MONGO_ALLOW_STANDALONE is set
+// as changes and their audit records and events could then be partly written
+func newTransactor(ctx context.Context, cfg config.Config, client *mongodb.MongoDBClient) (shared.Transactor, error) {
+	ctx, cancel := context.WithTimeout(ctx, connectTimeout)
+	defer cancel()
+
+	supported, err := mongodb.TransactionsSupported(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if supported {
+		return mongodb.NewTransactor(client), nil
+	}
+	if !cfg.MongoAllowStandalone {
+		return nil, errors.New("MongoDB is a standalone server without transactions, run it as a replica set " +
+			"or set MONGO_ALLOW_STANDALONE=true to accept partly written changes")
+	}
+	log.Default().Println("WARNING: MongoDB is a standalone server without transactions, " +
+		"failed changes may leave partly written users, audit records and events")
+	return mongodb.NewTransactor(client, mongodb.AllowStandalone()), nil
+}
+
+// newMigrations returns the MongoDB migrations configured like the application
+func newMigrations(cfg config.Config) []mongodb.Migration {
+	return mongodb.NewMigrations(mongodb.MigrationConfig{GmailCanonicalization: cfg.Validation.GmailCanonicalization})
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+)
+
+// errInvalidUsers is returned when at least one file holds an invalid user
+var errInvalidUsers = errors.New("some users are invalid")
+
+// validateUsers runs the user validation over JSON files and prints the error codes of each file
+func validateUsers(_ context.Context, cfg config.Config, args []string, stdout io.Writer) error {
+	flags := newFlagSet("validate", "FILE...")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("expected at least one FILE")
+	}
+
+	validationService, err := newValidationService(cfg)
+	if err != nil {
+		return err
+	}
+	invalid := false
+	for _, path := range flags.Args() {
+		user, err := readUserFile(path)
+		if err != nil {
+			fmt.Fprintf(stdout, "%s: %v\n", path, err)
+			invalid = true
+			continue
+		}
+		validationService.Normalize(user)
+		if err := validationService.ValidateUser(*user); err != nil {
+			printValidationErrors(stdout, path, err)
+			invalid = true
+			continue
+		}
+		fmt.Fprintf(stdout, "%s: OK\n", path)
+	}
+	if invalid {
+		return errInvalidUsers
+	}
+	return nil
+}
+
+// readUserFile strictly decodes the single user of a JSON file
+func readUserFile(path string) (*userEntity.User, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var record bulk.Record
+	if err := decoder.Decode(&record); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, errors.New("invalid JSON: unexpected data after the user")
+	}
+	return record.ToEntity()
+}
+
+func printValidationErrors(w io.Writer, path string, err error) {
+	validationErrors := shared.ValidationErrors(err)
+	if len(validationErrors) == 0 {
+		fmt.Fprintf(w, "%s: %v\n", path, err)
+		return
+	}
+	for _, validationError := range validationErrors {
+		fmt.Fprintf(w, "%s: %s: %s\n", path, validationError.Code, validationError.Message)
+	}
+}
+
+// userValidator normalizes and validates users with the configured rules
+type userValidator interface {
+	Normalize(user *userEntity.User)
+	ValidateUser(user userEntity.User) error
+}
+
+// newValidationService creates the validation service of the configured rules
+func newValidationService(cfg config.Config) (userValidator, error) {
+	return userEntity.NewConfiguredValidationService(cfg.Validation)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/config"
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+)
+
+func TestValidateUsers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name           string
+		content        string
+		expectedOutput []string
+		expectedErr    error
+	}{
+		{
+			name:           "valid user",
+			content:        `{"id": "1", "first_name": "John", "last_name": "Doe", "email": "john@example.com", "age": 25}`,
+			expectedOutput: []string{"OK"},
+		},
+		{
+			name:           "invalid user",
+			content:        `{"id": "1", "first_name": "John", "last_name": "Doe", "email": "john", "age": 12}`,
+			expectedOutput: []string{"AGE_MINIMUM", "EMAIL_FORMAT"},
+			expectedErr:    errInvalidUsers,
+		},
+		{
+			name:           "unknown field",
+			content:        `{"id": "1", "nickname": "Johnny"}`,
+			expectedOutput: []string{"invalid JSON"},
+			expectedErr:    errInvalidUsers,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(strings.ReplaceAll(test.name, " ", "_")+".json", test.content)
+			var stdout bytes.Buffer
+
+			err := validateUsers(context.Background(), config.Config{Validation: userEntity.DefaultValidationConfig()}, []string{path}, &stdout)
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("validateUsers() error = %v, want %v", err, test.expectedErr)
+			}
+			for _, expected := range test.expectedOutput {
+				if !strings.Contains(stdout.String(), expected) {
+					t.Errorf("validateUsers() output = %q, want it to contain %q", stdout.String(), expected)
+				}
+			}
+		})
+	}
+}
+
+func TestFileFormat(t *testing.T) {
+	tests := []struct {
+		name           string
+		formatName     string
+		path           string
+		expectedFormat bulk.Format
+		expectErr      bool
+	}{
+		{name: "from extension", path: "users.CSV", expectedFormat: bulk.FormatCSV},
+		{name: "jsonl extension", path: "users.jsonl", expectedFormat: bulk.FormatNDJSON},
+		{name: "explicit format wins", formatName: "parquet", path: "users.csv", expectedFormat: bulk.FormatParquet},
+		{name: "no extension", path: "-", expectErr: true},
+		{name: "unknown extension", path: "users.txt", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			format, err := fileFormat(test.formatName, test.path)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("fileFormat() error = %v, expectErr %v", err, test.expectErr)
+			}
+			if format != test.expectedFormat {
+				t.Errorf("fileFormat() = %v, want %v", format, test.expectedFormat)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+// Package outbox contains the relay delivering the events of the user outbox to publishers.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// Publisher delivers events to downstream systems.
+// Events are delivered at least once, so publishers and their consumers must tolerate duplicates.
+type Publisher interface {
+	Publish(ctx context.Context, event *user.Event) error
+}
+
+// RelayConfig configures how often and how much the relay reads from the outbox
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+type relay struct {
+	outbox     user.Outbox
+	publishers []Publisher
+	config     RelayConfig
+}
+
+// NewRelay creates a relay delivering the outbox events to every publisher
+func NewRelay(outbox user.Outbox, config RelayConfig, publishers ...Publisher) *relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	return &relay{outbox: outbox, publishers: publishers, config: config}
+}
+
+// Run relays the pending events every poll interval until ctx is done
+func (r *relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+			log.Printf(`{"level":"error","msg":"outbox relay failed","error":%q}`, err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending delivers the pending events in the order they occurred and returns how many were delivered.
+// It stops at the first event that cannot be delivered so later events are not delivered before it,
+// the event is retried by the next call.
+func (r *relay) RelayPending(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		events, err := r.outbox.Pending(ctx, r.config.BatchSize)
+		if err != nil {
+			return delivered, fmt.Errorf("outbox: failed to read pending events: %w", err)
+		}
+		for _, event := range events {
+			if err := r.publish(ctx, event); err != nil {
+				return delivered, fmt.Errorf("outbox: failed to publish event %q: %w", event.ID, err)
+			}
+			if err := r.outbox.MarkDelivered(ctx, event.ID); err != nil {
+				return delivered, fmt.Errorf("outbox: failed to mark event %q delivered: %w", event.ID, err)
+			}
+			delivered++
+		}
+		if len(events) < r.config.BatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// publish sends the event to every publisher, an event is only delivered once all publishers accepted it
+func (r *relay) publish(ctx context.Context, event *user.Event) error {
+	var errs []error
+	for _, publisher := range r.publishers {
+		if err := publisher.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
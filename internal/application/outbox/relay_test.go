@@ -0,0 +1,113 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type mockOutbox struct {
+	PendingFunc       func(ctx context.Context, limit int) ([]*user.Event, error)
+	MarkDeliveredFunc func(ctx context.Context, id string) error
+}
+
+func (m *mockOutbox) Add(ctx context.Context, event *user.Event) error {
+	return errors.New("not implemented")
+}
+func (m *mockOutbox) Pending(ctx context.Context, limit int) ([]*user.Event, error) {
+	return m.PendingFunc(ctx, limit)
+}
+func (m *mockOutbox) MarkDelivered(ctx context.Context, id string) error {
+	return m.MarkDeliveredFunc(ctx, id)
+}
+
+type mockPublisher struct {
+	PublishFunc func(ctx context.Context, event *user.Event) error
+}
+
+func (m *mockPublisher) Publish(ctx context.Context, event *user.Event) error {
+	return m.PublishFunc(ctx, event)
+}
+
+// newSliceOutbox creates a mock outbox over events where delivered events are removed
+func newSliceOutbox(events []*user.Event) *mockOutbox {
+	return &mockOutbox{
+		PendingFunc: func(ctx context.Context, limit int) ([]*user.Event, error) {
+			if len(events) > limit {
+				return events[:limit], nil
+			}
+			return events, nil
+		},
+		MarkDeliveredFunc: func(ctx context.Context, id string) error {
+			for i, event := range events {
+				if event.ID == id {
+					events = append(events[:i:i], events[i+1:]...)
+					return nil
+				}
+			}
+			return fmt.Errorf("event %q not pending", id)
+		},
+	}
+}
+
+func TestRelay_RelayPending(t *testing.T) {
+	events := []*user.Event{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	tests := []struct {
+		name              string
+		failOn            string
+		expectedDelivered int
+		expectedPublished string
+		expectErr         bool
+	}{
+		{name: "all events delivered across batches", expectedDelivered: 3, expectedPublished: "[1 2 3]"},
+		{name: "stops at the first failed event", failOn: "2", expectedDelivered: 1, expectedPublished: "[1 2]", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var published []string
+			publisher := &mockPublisher{PublishFunc: func(ctx context.Context, event *user.Event) error {
+				published = append(published, event.ID)
+				if event.ID == test.failOn {
+					return errors.New("downstream unavailable")
+				}
+				return nil
+			}}
+			relay := NewRelay(newSliceOutbox(append([]*user.Event(nil), events...)), RelayConfig{BatchSize: 2}, publisher)
+
+			delivered, err := relay.RelayPending(context.Background())
+
+			if (err != nil) != test.expectErr {
+				t.Fatalf("RelayPending() error = %v, expectErr %v", err, test.expectErr)
+			}
+			if delivered != test.expectedDelivered {
+				t.Errorf("RelayPending() delivered = %d, want %d", delivered, test.expectedDelivered)
+			}
+			if fmt.Sprint(published) != test.expectedPublished {
+				t.Errorf("RelayPending() published = %v, want %v", published, test.expectedPublished)
+			}
+		})
+	}
+}
+
+func TestRelay_RedeliversAfterFailure(t *testing.T) {
+	outbox := newSliceOutbox([]*user.Event{{ID: "1"}})
+	attempts := 0
+	relay := NewRelay(outbox, RelayConfig{}, &mockPublisher{PublishFunc: func(ctx context.Context, event *user.Event) error {
+		if attempts++; attempts == 1 {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	}})
+
+	if _, err := relay.RelayPending(context.Background()); err == nil {
+		t.Fatal("RelayPending() first call expected error")
+	}
+	delivered, err := relay.RelayPending(context.Background())
+	if err != nil || delivered != 1 {
+		t.Errorf("RelayPending() retry delivered = %d, err = %v, want 1 and nil", delivered, err)
+	}
+}
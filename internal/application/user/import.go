@@ -0,0 +1,229 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// ImportStatus is the outcome of importing a row
+type ImportStatus string
+
+const (
+	ImportStatusCreated  ImportStatus = "created"
+	ImportStatusUpdated  ImportStatus = "updated"
+	ImportStatusRejected ImportStatus = "rejected"
+)
+
+const (
+	ImportErrorRowMalformed          = "ROW_MALFORMED"
+	ImportErrorRowFailed             = "ROW_FAILED"
+	ImportErrorNameCombinationExists = user.ErrorNameCombinationExists
+	ImportErrorPossibleDuplicate     = user.ErrorPossibleDuplicate
+)
+
+// ImportRow is a user read from an import source
+type ImportRow struct {
+	// Line is the line number of the row in the source
+	Line int
+	User *user.User
+	// Err is set when the row could not be parsed
+	Err error
+}
+
+// ImportRowReader reads import rows one at a time.
+// Read returns io.EOF when there are no more rows and any other error when the source cannot be read.
+type ImportRowReader interface {
+	Read() (ImportRow, error)
+}
+
+// ImportError is a reason a row was rejected, validation failures carry the field and params of their ValidationError
+type ImportError struct {
+	Code    string
+	Message string
+	Field   string
+	Params  map[string]string
+}
+
+// ImportRowResult is the outcome of importing a single row
+type ImportRowResult struct {
+	Line   int
+	ID     string
+	Status ImportStatus
+	Errors []ImportError
+	// Warnings are the concerns about an accepted row, such as its possible duplicates in advisory mode
+	Warnings []ImportError
+}
+
+// ImportReport is the outcome of an import
+type ImportReport struct {
+	DryRun   bool
+	Created  int
+	Updated  int
+	Rejected int
+	Rows     []ImportRowResult
+}
+
+// Import validates and saves every row read from the reader.
+// Rows are checked with the same validation and uniqueness rules as Save; in dry run mode nothing is saved.
+// Possible duplicates are looked for among the stored users only, rows of the same import are not compared.
+// A row that cannot be saved is rejected and the import goes on, only reader and context errors abort it.
+func (s *service) Import(ctx context.Context, reader ImportRowReader, dryRun bool) (*ImportReport, error) {
+	report := &ImportReport{DryRun: dryRun}
+	batch := newImportBatch()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("service: import aborted: %w", err)
+		}
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to read import: %w", err)
+		}
+
+		result, err := s.importRow(ctx, row, batch, dryRun)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, fmt.Errorf("service: import aborted: %w", ctxErr)
+			}
+			result = rejected(result, importRowError(ctx, row.Line, err))
+		}
+		switch result.Status {
+		case ImportStatusCreated:
+			report.Created++
+		case ImportStatusUpdated:
+			report.Updated++
+		case ImportStatusRejected:
+			report.Rejected++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+}
+
+func (s *service) importRow(ctx context.Context, row ImportRow, batch *importBatch, dryRun bool) (ImportRowResult, error) {
+	result := ImportRowResult{Line: row.Line}
+	if row.Err != nil {
+		return rejected(result, ImportError{Code: ImportErrorRowMalformed, Message: row.Err.Error()}), nil
+	}
+	importedUser := row.User
+	result.ID = importedUser.ID
+
+	s.userValidationService.Normalize(importedUser)
+	if err := s.userValidationService.ValidateUser(*importedUser); err != nil {
+		var importErrors []ImportError
+		for _, validationError := range shared.ValidationErrors(err) {
+			importErrors = append(importErrors, ImportError{
+				Code:    validationError.Code,
+				Message: validationError.Message,
+				Field:   validationError.Field,
+				Params:  validationError.Params,
+			})
+		}
+		if len(importErrors) == 0 {
+			importErrors = append(importErrors, ImportError{Code: ImportErrorRowMalformed, Message: err.Error()})
+		}
+		return rejected(result, importErrors...), nil
+	}
+
+	existingUser, err := s.importedUserExisting(ctx, importedUser.ID, batch)
+	if err != nil {
+		return result, err
+	}
+	if s.nameCombinationExists(ctx, importedUser) || batch.nameTaken(importedUser) {
+		return rejected(result, ImportError{Code: ImportErrorNameCombinationExists, Message: user.ErrNameCombinationExists.Error()}), nil
+	}
+	if s.duplicateConfig.Mode != user.DuplicateModeOff {
+		candidates, err := s.duplicates(ctx, importedUser)
+		if err != nil {
+			return result, fmt.Errorf("failed to look for duplicates: %w", err)
+		}
+		var duplicateErrors []ImportError
+		for _, candidate := range candidates {
+			warning := user.NewPossibleDuplicateWarning(candidate)
+			duplicateErrors = append(duplicateErrors, ImportError{Code: warning.Code, Message: warning.Message, Params: warning.Params})
+		}
+		if len(duplicateErrors) > 0 && s.duplicateConfig.Mode == user.DuplicateModeBlocking {
+			return rejected(result, duplicateErrors...), nil
+		}
+		result.Warnings = duplicateErrors
+	}
+
+	if importedUser.ID == "" {
+		if importedUser.ID, err = newID(); err != nil {
+			return result, fmt.Errorf("failed to generate user ID: %w", err)
+		}
+		result.ID = importedUser.ID
+	}
+	importedUser.Version = 0
+	if existingUser != nil {
+		importedUser.Version = existingUser.Version
+	}
+	if !dryRun {
+		// later rows of the same user replace the saved version
+		if importedUser, err = s.store(ctx, importedUser, existingUser); err != nil {
+			return result, err
+		}
+	}
+	batch.add(importedUser)
+
+	result.Status = ImportStatusCreated
+	if existingUser != nil {
+		result.Status = ImportStatusUpdated
+	}
+	return result, nil
+}
+
+// importedUserExisting returns the user an imported row replaces, nil when the row creates a user
+func (s *service) importedUserExisting(ctx context.Context, id string, batch *importBatch) (*user.User, error) {
+	if existingUser, ok := batch.users[id]; ok {
+		return existingUser, nil
+	}
+	return s.findExisting(ctx, id)
+}
+
+// importRowError is the reason a row that failed to import is rejected.
+// The error is logged rather than reported, it may describe the storage.
+func importRowError(ctx context.Context, line int, err error) ImportError {
+	if errors.Is(err, user.ErrNameCombinationExists) {
+		return ImportError{Code: ImportErrorNameCombinationExists, Message: user.ErrNameCombinationExists.Error()}
+	}
+	log.Printf(`{"level":"error","msg":"import row failed","line":%d,"request_id":"%s","error":%q}`,
+		line, shared.RequestIDFromContext(ctx), err.Error())
+	return ImportError{Code: ImportErrorRowFailed, Message: "the row could not be imported"}
+}
+
+func rejected(result ImportRowResult, importErrors ...ImportError) ImportRowResult {
+	result.Status = ImportStatusRejected
+	result.Errors = importErrors
+	return result
+}
+
+// importBatch tracks the rows accepted so far so rows of the same import are checked against each other,
+// which the repository cannot do in dry run mode
+type importBatch struct {
+	users map[string]*user.User
+	// names maps the user.NameKey of the accepted rows to their user ID
+	names map[string]string
+}
+
+func newImportBatch() *importBatch {
+	return &importBatch{users: make(map[string]*user.User), names: make(map[string]string)}
+}
+
+func (b *importBatch) add(importedUser *user.User) {
+	b.users[importedUser.ID] = importedUser
+	b.names[user.NameKey(importedUser.FirstName, importedUser.LastName)] = importedUser.ID
+}
+
+func (b *importBatch) nameTaken(importedUser *user.User) bool {
+	id, ok := b.names[user.NameKey(importedUser.FirstName, importedUser.LastName)]
+	return ok && (importedUser.ID == "" || id != importedUser.ID)
+}
@@ -0,0 +1,258 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type sliceRowReader struct {
+	rows []ImportRow
+}
+
+func (r *sliceRowReader) Read() (ImportRow, error) {
+	if len(r.rows) == 0 {
+		return ImportRow{}, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	return row, nil
+}
+
+// newMapUserRepository creates a mock repository backed by a map
+func newMapUserRepository(users map[string]*userDomain.User) *mockUserRepository {
+	nameExists := func(firstName string, lastName string, id string) bool {
+		for _, user := range users {
+			if userDomain.NameKey(user.FirstName, user.LastName) == userDomain.NameKey(firstName, lastName) && user.ID != id {
+				return true
+			}
+		}
+		return false
+	}
+	tombstones := make(map[string]*userDomain.Tombstone)
+	return &mockUserRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			user, ok := users[id]
+			if !ok {
+				return nil, userDomain.ErrNotFound
+			}
+			return user, nil
+		},
+		SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+			users[user.ID] = user
+			return user, nil
+		},
+		DeleteFunc: func(ctx context.Context, id string) error {
+			if _, ok := users[id]; !ok {
+				return userDomain.ErrNotFound
+			}
+			delete(users, id)
+			return nil
+		},
+		ExistsByFirstNameAndLastNameFunc: func(ctx context.Context, firstName string, lastName string) bool {
+			return nameExists(firstName, lastName, "")
+		},
+		ExistsByFirstNameAndLastNameAndIDNotFunc: func(ctx context.Context, firstName string, lastName string, id string) bool {
+			return nameExists(firstName, lastName, id)
+		},
+		// every other user is a candidate, the service scores them
+		ListDuplicateCandidatesFunc: func(ctx context.Context, user *userDomain.User, limit int) ([]*userDomain.User, error) {
+			var candidates []*userDomain.User
+			for _, candidate := range users {
+				if candidate.ID != user.ID {
+					candidates = append(candidates, candidate)
+				}
+			}
+			return candidates, nil
+		},
+		TombstoneFunc: func(ctx context.Context, tombstone *userDomain.Tombstone, version int64) error {
+			user, ok := users[tombstone.ID]
+			if !ok {
+				return userDomain.ErrNotFound
+			}
+			if user.Version != version {
+				return userDomain.ErrVersionConflict
+			}
+			delete(users, tombstone.ID)
+			tombstones[tombstone.ID] = tombstone
+			return nil
+		},
+		FindTombstoneFunc: func(ctx context.Context, id string) (*userDomain.Tombstone, error) {
+			tombstone, ok := tombstones[id]
+			if !ok {
+				return nil, userDomain.ErrNotFound
+			}
+			return tombstone, nil
+		},
+	}
+}
+
+func TestService_Import(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		users := map[string]*userDomain.User{
+			"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+		}
+		reader := &sliceRowReader{rows: []ImportRow{
+			{Line: 2, User: &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 26}},
+			{Line: 3, User: &userDomain.User{ID: "2", FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 30}},
+			{Line: 4, User: &userDomain.User{ID: "3", FirstName: "Jim", LastName: "Doe", Email: "jim", Age: 12}},
+			{Line: 5, User: &userDomain.User{ID: "4", FirstName: "Jane", LastName: "Doe", Email: "jane2@example.com", Age: 30}},
+			{Line: 6, User: &userDomain.User{FirstName: "Jack", LastName: "Doe", Email: "jack@example.com", Age: 40}},
+			{Line: 7, Err: io.ErrUnexpectedEOF},
+		}}
+		service := NewService(userDomain.NewValidationService(), newMapUserRepository(users), newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+		report, err := service.Import(context.Background(), reader, dryRun)
+		if err != nil {
+			t.Fatalf("Import() unexpected error: %v", err)
+		}
+
+		if report.Created != 2 || report.Updated != 1 || report.Rejected != 3 {
+			t.Errorf("Import(dryRun=%v) created/updated/rejected = %d/%d/%d, want 2/1/3", dryRun, report.Created, report.Updated, report.Rejected)
+		}
+		expected := []struct {
+			status ImportStatus
+			codes  []string
+		}{
+			{status: ImportStatusUpdated},
+			{status: ImportStatusCreated},
+			{status: ImportStatusRejected, codes: []string{userDomain.ErrorAgeMinimum, userDomain.ErrorEmailFormat}},
+			{status: ImportStatusRejected, codes: []string{ImportErrorNameCombinationExists}},
+			{status: ImportStatusCreated},
+			{status: ImportStatusRejected, codes: []string{ImportErrorRowMalformed}},
+		}
+		for i, row := range report.Rows {
+			if row.Line != i+2 || row.Status != expected[i].status || len(row.Errors) != len(expected[i].codes) {
+				t.Errorf("Import(dryRun=%v) row %d = %+v, want %+v", dryRun, i, row, expected[i])
+				continue
+			}
+			for j, importError := range row.Errors {
+				if importError.Code != expected[i].codes[j] {
+					t.Errorf("Import(dryRun=%v) row %d error %d = %s, want %s", dryRun, i, j, importError.Code, expected[i].codes[j])
+				}
+			}
+		}
+		if report.Rows[4].ID == "" {
+			t.Errorf("Import(dryRun=%v) expected an ID to be generated for line 6", dryRun)
+		}
+
+		expectedUsers := 3
+		if dryRun {
+			expectedUsers = 1
+		}
+		if len(users) != expectedUsers {
+			t.Errorf("Import(dryRun=%v) saved %d users, want %d", dryRun, len(users), expectedUsers)
+		}
+	}
+}
+
+func TestService_Import_Duplicates(t *testing.T) {
+	tests := []struct {
+		name           string
+		mode           userDomain.DuplicateMode
+		expectedStatus ImportStatus
+		expectedErrors int
+		expectedWarns  int
+	}{
+		{name: "advisory", mode: userDomain.DuplicateModeAdvisory, expectedStatus: ImportStatusCreated, expectedWarns: 1},
+		{name: "blocking", mode: userDomain.DuplicateModeBlocking, expectedStatus: ImportStatusRejected, expectedErrors: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := map[string]*userDomain.User{
+				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", CanonicalEmail: "john@example.com", Age: 25},
+			}
+			reader := &sliceRowReader{rows: []ImportRow{
+				{Line: 2, User: &userDomain.User{ID: "2", FirstName: "Max", LastName: "Mustermann", Email: "JOHN@example.com", Age: 30}},
+			}}
+			service := NewService(userDomain.NewValidationService(), newMapUserRepository(users), newSliceAuditRepository(new([]*userDomain.AuditRecord)),
+				newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{}, WithDuplicateDetection(userDomain.DuplicateConfig{Mode: test.mode, Threshold: 0.9}))
+
+			report, err := service.Import(context.Background(), reader, true)
+			if err != nil {
+				t.Fatalf("Import() unexpected error: %v", err)
+			}
+
+			row := report.Rows[0]
+			if row.Status != test.expectedStatus || len(row.Errors) != test.expectedErrors || len(row.Warnings) != test.expectedWarns {
+				t.Fatalf("Import() row = %+v, want %s with %d errors and %d warnings", row, test.expectedStatus, test.expectedErrors, test.expectedWarns)
+			}
+			for _, importError := range append(row.Errors, row.Warnings...) {
+				if importError.Code != ImportErrorPossibleDuplicate || importError.Params["user_id"] != "1" {
+					t.Errorf("Import() row error = %+v, want a possible duplicate of user 1", importError)
+				}
+			}
+		})
+	}
+}
+
+func TestService_Import_RowFailures(t *testing.T) {
+	tests := []struct {
+		name         string
+		saveErr      error
+		expectedCode string
+	}{
+		{name: "name taken concurrently", saveErr: userDomain.ErrNameCombinationExists, expectedCode: ImportErrorNameCombinationExists},
+		{name: "storage failure", saveErr: errors.New("connection reset"), expectedCode: ImportErrorRowFailed},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := make(map[string]*userDomain.User)
+			repository := newMapUserRepository(users)
+			save := repository.SaveFunc
+			repository.SaveFunc = func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+				if user.ID == "1" {
+					return nil, test.saveErr
+				}
+				return save(ctx, user)
+			}
+			reader := &sliceRowReader{rows: []ImportRow{
+				{Line: 2, User: &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}},
+				{Line: 3, User: &userDomain.User{ID: "2", FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 30}},
+			}}
+			service := NewService(userDomain.NewValidationService(), repository, newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+			report, err := service.Import(context.Background(), reader, false)
+			if err != nil {
+				t.Fatalf("Import() unexpected error: %v", err)
+			}
+
+			if report.Created != 1 || report.Rejected != 1 {
+				t.Fatalf("Import() created/rejected = %d/%d, want 1/1", report.Created, report.Rejected)
+			}
+			failed := report.Rows[0]
+			if failed.Status != ImportStatusRejected || len(failed.Errors) != 1 || failed.Errors[0].Code != test.expectedCode {
+				t.Errorf("Import() row = %+v, want rejected with %s", failed, test.expectedCode)
+			}
+			if strings.Contains(failed.Errors[0].Message, "connection reset") {
+				t.Errorf("Import() row error message %q leaks the storage error", failed.Errors[0].Message)
+			}
+			if _, ok := users["2"]; !ok {
+				t.Errorf("Import() expected the row after the failed one to be saved")
+			}
+		})
+	}
+}
+
+func TestService_Import_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repository := newMapUserRepository(make(map[string]*userDomain.User))
+	repository.SaveFunc = func(context.Context, *userDomain.User) (*userDomain.User, error) {
+		cancel()
+		return nil, context.Canceled
+	}
+	reader := &sliceRowReader{rows: []ImportRow{
+		{Line: 2, User: &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}},
+	}}
+	service := NewService(userDomain.NewValidationService(), repository, newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+	if _, err := service.Import(ctx, reader, false); !errors.Is(err, context.Canceled) {
+		t.Errorf("Import() error = %v, want %v", err, context.Canceled)
+	}
+}
@@ -3,58 +3,479 @@ package user
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
 	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
 )
 
 type userValidationService interface {
+	// Normalize normalizes the user's fields before validation
+	Normalize(user *user.User)
 	ValidateUser(user user.User) error
 }
 
 type service struct {
 	userValidationService userValidationService
 	userRepository        user.Repository
+	auditRepository       user.AuditRepository
+	outbox                user.Outbox
+	transactor            shared.Transactor
+	duplicateConfig       user.DuplicateConfig
+	now                   func() time.Time
 }
 
-// NewService creates a new user service
-func NewService(userValidationService userValidationService, userRepository user.Repository) *service {
-	return &service{
+// duplicateCandidateLimit bounds the users scored as duplicates of a user, beyond the users sharing its email
+const duplicateCandidateLimit = 200
+
+// ServiceOption configures a user service
+type ServiceOption func(s *service)
+
+// WithDuplicateDetection looks for possible duplicates of the users being saved, duplicate detection is off by default
+func WithDuplicateDetection(config user.DuplicateConfig) ServiceOption {
+	return func(s *service) {
+		s.duplicateConfig = config
+	}
+}
+
+// NewService creates a new user service.
+// Every change it makes is recorded in the audit repository and emitted as an event to the outbox,
+// in the same transaction as the change.
+func NewService(userValidationService userValidationService, userRepository user.Repository, auditRepository user.AuditRepository,
+	outbox user.Outbox, transactor shared.Transactor, options ...ServiceOption) *service {
+	s := &service{
 		userValidationService: userValidationService,
 		userRepository:        userRepository,
+		auditRepository:       auditRepository,
+		outbox:                outbox,
+		transactor:            transactor,
+		duplicateConfig:       user.DuplicateConfig{Mode: user.DuplicateModeOff, Threshold: user.DefaultDuplicateConfig().Threshold},
+		now:                   time.Now,
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
 
 // Find finds a user by id
 func (s *service) Find(ctx context.Context, id string) (*user.User, error) {
-	user, err := s.userRepository.FindByID(ctx, id)
+	user, err := s.findByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to find user by ID %q: %w", id, err)
 	}
 	return user, nil
 }
 
-// Save adds a user to the repository
-func (s *service) Save(ctx context.Context, user *user.User) (*user.User, error) {
-	err := s.userValidationService.ValidateUser(*user)
+// List lists the users matching the filter
+func (s *service) List(ctx context.Context, filter user.ListFilter) ([]*user.User, error) {
+	users, err := s.userRepository.List(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("service: failed to validate user: %w", err)
+		return nil, fmt.Errorf("service: failed to list users: %w", err)
 	}
+	return users, nil
+}
 
-	if s.nameCombinationExists(ctx, user) {
-		return nil, fmt.Errorf("service: name combination already exists")
+// Stream returns a cursor over the users matching the filter, the caller must close the cursor
+func (s *service) Stream(ctx context.Context, filter user.ListFilter) (user.Cursor, error) {
+	cursor, err := s.userRepository.Stream(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to stream users: %w", err)
 	}
+	return cursor, nil
+}
 
-	savedUser, err := s.userRepository.Save(ctx, user)
+// Save adds a user to the repository, replacing the user with the same id if it is still at the user's version.
+// A new user has version zero, a user without an id is created with a generated one.
+func (s *service) Save(ctx context.Context, userToSave *user.User) (*user.User, error) {
+	if err := s.check(ctx, userToSave); err != nil {
+		return nil, err
+	}
+	if userToSave.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to generate user ID: %w", err)
+		}
+		userToSave.ID = id
+		userToSave.Version = 0
+		return s.store(ctx, userToSave, nil)
+	}
+	existingUser, err := s.findExisting(ctx, userToSave.ID)
 	if err != nil {
 		return nil, fmt.Errorf("service: failed to save user: %w", err)
 	}
+	var existingVersion int64
+	if existingUser != nil {
+		existingVersion = existingUser.Version
+	}
+	if userToSave.Version != existingVersion {
+		return nil, fmt.Errorf("service: failed to save user %q at version %d, current version is %d: %w",
+			userToSave.ID, userToSave.Version, existingVersion, user.ErrVersionConflict)
+	}
+	return s.store(ctx, userToSave, existingUser)
+}
+
+// Create adds a new user to the repository, generating an id when the user has none
+func (s *service) Create(ctx context.Context, newUser *user.User) (*user.User, error) {
+	if newUser.ID == "" {
+		id, err := newID()
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to generate user ID: %w", err)
+		}
+		newUser.ID = id
+	} else if _, err := s.userRepository.FindByID(ctx, newUser.ID); err == nil {
+		return nil, fmt.Errorf("service: failed to create user %q: %w", newUser.ID, user.ErrAlreadyExists)
+	} else if !errors.Is(err, user.ErrNotFound) {
+		return nil, fmt.Errorf("service: failed to create user %q: %w", newUser.ID, err)
+	}
+	if err := s.check(ctx, newUser); err != nil {
+		return nil, err
+	}
+	newUser.Version = 0
+	return s.store(ctx, newUser, nil)
+}
+
+// Update replaces an existing user in the repository if it is still at the user's version
+func (s *service) Update(ctx context.Context, userToUpdate *user.User) (*user.User, error) {
+	existingUser, err := s.findByID(ctx, userToUpdate.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to update user %q: %w", userToUpdate.ID, err)
+	}
+	if userToUpdate.Version != existingUser.Version {
+		return nil, fmt.Errorf("service: failed to update user %q at version %d, current version is %d: %w",
+			userToUpdate.ID, userToUpdate.Version, existingUser.Version, user.ErrVersionConflict)
+	}
+	if err := s.check(ctx, userToUpdate); err != nil {
+		return nil, err
+	}
+	return s.store(ctx, userToUpdate, existingUser)
+}
+
+// Delete removes a user from the repository
+func (s *service) Delete(ctx context.Context, id string) error {
+	existingUser, err := s.findByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: failed to delete user %q: %w", id, err)
+	}
+	return s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepository.Delete(ctx, id); err != nil {
+			return fmt.Errorf("service: failed to delete user %q: %w", id, err)
+		}
+		return s.recordChange(ctx, user.AuditActionDelete, existingUser, nil)
+	})
+}
+
+// AuditTrail returns the changes made to a user ordered by time, including the changes of deleted users
+func (s *service) AuditTrail(ctx context.Context, id string) ([]*user.AuditRecord, error) {
+	records, err := s.auditRepository.ListByUserID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to list audit records of user %q: %w", id, err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("service: no audit records for user %q: %w", id, user.ErrNotFound)
+	}
+	return records, nil
+}
+
+// Merge merges the duplicates of a request into its survivor. The survivor keeps its id, status and creation time and
+// takes the fields picked by the rules, then it is checked like any saved user, ignoring its duplicates.
+// In one transaction the survivor is saved and the duplicates are replaced by tombstones at the versions they were read,
+// so a user changed in the meantime fails the merge with user.ErrVersionConflict. Looking up a merged duplicate returns
+// a user.MergedError, and the merge is recorded for the survivor and every duplicate.
+func (s *service) Merge(ctx context.Context, request user.MergeRequest) (*user.User, error) {
+	if err := request.Validate(); err != nil {
+		return nil, fmt.Errorf("service: failed to merge users: %w", err)
+	}
+	survivor, err := s.findByID(ctx, request.SurvivorID)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to merge into user %q: %w", request.SurvivorID, err)
+	}
+	duplicates := make([]*user.User, 0, len(request.DuplicateIDs))
+	for _, id := range request.DuplicateIDs {
+		duplicate, err := s.findByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("service: failed to merge user %q: %w", id, err)
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+
+	mergedUser := request.Apply(*survivor, duplicates)
+	if err := s.checkExcept(ctx, mergedUser, duplicates); err != nil {
+		return nil, err
+	}
+	mergedUser.UpdatedAt = s.timestamp()
+
+	var savedUser *user.User
+	err = s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		// the duplicates are removed first as the survivor may take the names they hold
+		for _, duplicate := range duplicates {
+			tombstone := &user.Tombstone{ID: duplicate.ID, MergedInto: mergedUser.ID, MergedAt: mergedUser.UpdatedAt}
+			if err := s.userRepository.Tombstone(ctx, tombstone, duplicate.Version); err != nil {
+				return fmt.Errorf("service: failed to tombstone merged user %q: %w", duplicate.ID, err)
+			}
+		}
+		var err error
+		if savedUser, err = s.userRepository.Save(ctx, mergedUser); err != nil {
+			return fmt.Errorf("service: failed to save merged user: %w", err)
+		}
+		record := &user.AuditRecord{UserID: savedUser.ID, Action: user.AuditActionMerge, Changes: user.Diff(survivor, savedUser),
+			MergedIDs: request.DuplicateIDs}
+		if err := s.record(ctx, record, user.EventUserUpdated, savedUser); err != nil {
+			return err
+		}
+		for _, duplicate := range duplicates {
+			record := &user.AuditRecord{UserID: duplicate.ID, Action: user.AuditActionMerge, Changes: user.Diff(duplicate, nil),
+				MergedInto: savedUser.ID}
+			if err := s.record(ctx, record, user.EventUserDeleted, duplicate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return savedUser, nil
+}
+
+// Duplicates returns the users scoring as possible duplicates of a user, best first
+func (s *service) Duplicates(ctx context.Context, id string) ([]user.DuplicateCandidate, error) {
+	existingUser, err := s.findByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to find the duplicates of user %q: %w", id, err)
+	}
+	candidates, err := s.duplicates(ctx, existingUser)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to find the duplicates of user %q: %w", id, err)
+	}
+	return candidates, nil
+}
+
+// check normalizes and validates the user, checks its name uniqueness and looks for its possible duplicates.
+// Possible duplicates are rejected with a user.DuplicateError in blocking mode and reported as warnings to the
+// context in advisory mode.
+func (s *service) check(ctx context.Context, userToSave *user.User) error {
+	return s.checkExcept(ctx, userToSave, nil)
+}
+
+// checkExcept checks the user like check, ignoring the name conflicts and possible duplicates that are one of the
+// excluded users, which a merge is about to remove
+func (s *service) checkExcept(ctx context.Context, userToSave *user.User, excluded []*user.User) error {
+	s.userValidationService.Normalize(userToSave)
+	err := s.userValidationService.ValidateUser(*userToSave)
+	if err != nil {
+		return fmt.Errorf("service: failed to validate user: %w", err)
+	}
+
+	nameKey := user.NameKey(userToSave.FirstName, userToSave.LastName)
+	takenByExcluded := slices.ContainsFunc(excluded, func(excludedUser *user.User) bool {
+		return user.NameKey(excludedUser.FirstName, excludedUser.LastName) == nameKey
+	})
+	if !takenByExcluded && s.nameCombinationExists(ctx, userToSave) {
+		return fmt.Errorf("service: %w", user.ErrNameCombinationExists)
+	}
+	if s.duplicateConfig.Mode == user.DuplicateModeOff {
+		return nil
+	}
+	candidates, err := s.duplicates(ctx, userToSave)
+	if err != nil {
+		return fmt.Errorf("service: failed to look for duplicates: %w", err)
+	}
+	candidates = slices.DeleteFunc(candidates, func(candidate user.DuplicateCandidate) bool {
+		return slices.ContainsFunc(excluded, func(excludedUser *user.User) bool { return excludedUser.ID == candidate.User.ID })
+	})
+	if len(candidates) > 0 && s.duplicateConfig.Mode == user.DuplicateModeBlocking {
+		return fmt.Errorf("service: %w", &user.DuplicateError{Candidates: candidates})
+	}
+	for _, candidate := range candidates {
+		shared.AddWarnings(ctx, user.NewPossibleDuplicateWarning(candidate))
+	}
+	return nil
+}
+
+// duplicates returns the stored users scoring at least the duplicate threshold as duplicates of the user, best first
+func (s *service) duplicates(ctx context.Context, userToMatch *user.User) ([]user.DuplicateCandidate, error) {
+	candidates, err := s.userRepository.ListDuplicateCandidates(ctx, userToMatch, duplicateCandidateLimit)
+	if err != nil {
+		return nil, err
+	}
+	return user.RankDuplicates(*userToMatch, candidates, s.duplicateConfig.Threshold), nil
+}
+
+// Transition changes the status of a user, recording the transition with the actor of the context and the reason
+func (s *service) Transition(ctx context.Context, id string, target user.Status, reason string) (*user.User, error) {
+	existingUser, err := s.findByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to change the status of user %q: %w", id, err)
+	}
+	changedUser := *existingUser
+	changedUser.StatusHistory = slices.Clone(existingUser.StatusHistory)
+	changedUser.UpdatedAt = s.timestamp()
+	if err := changedUser.Transition(target, changedUser.UpdatedAt, shared.ActorFromContext(ctx), reason); err != nil {
+		return nil, fmt.Errorf("service: failed to change the status of user %q: %w", id, err)
+	}
+	return s.persist(ctx, &changedUser, existingUser)
+}
+
+// VerifyEmail records that the user received mail sent to email, it returns user.ErrEmailChanged when email is no
+// longer the email of the user
+func (s *service) VerifyEmail(ctx context.Context, id string, email string) (*user.User, error) {
+	existingUser, err := s.findByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: failed to verify the email of user %q: %w", id, err)
+	}
+	if existingUser.EmailVerified() && existingUser.Email == email {
+		return existingUser, nil
+	}
+	verifiedUser := *existingUser
+	verifiedUser.UpdatedAt = s.timestamp()
+	if err := verifiedUser.VerifyEmail(email, verifiedUser.UpdatedAt); err != nil {
+		return nil, fmt.Errorf("service: failed to verify the email of user %q: %w", id, err)
+	}
+	return s.persist(ctx, &verifiedUser, existingUser)
+}
+
+// store saves a checked user and records the change, existingUser is the stored user it replaces or nil when it is new.
+// The timestamps, status and email verification are managed here whatever the client sent: the status and its history
+// are the stored ones and new users are pending, and an email stays verified until it changes.
+func (s *service) store(ctx context.Context, userToSave *user.User, existingUser *user.User) (*user.User, error) {
+	userToSave.UpdatedAt = s.timestamp()
+	userToSave.EmailVerifiedAt = time.Time{}
+	if existingUser != nil {
+		if userToSave.Email == existingUser.Email {
+			userToSave.EmailVerifiedAt = existingUser.EmailVerifiedAt
+		}
+		// users saved before creation times were recorded keep a zero one
+		userToSave.CreatedAt = existingUser.CreatedAt
+		userToSave.Status, userToSave.StatusHistory = existingUser.Status, existingUser.StatusHistory
+		return s.persist(ctx, userToSave, existingUser)
+	}
+	userToSave.CreatedAt = userToSave.UpdatedAt
+	userToSave.Status, userToSave.StatusHistory = "", nil
+	if err := userToSave.Transition(user.StatusPending, userToSave.UpdatedAt, shared.ActorFromContext(ctx), ""); err != nil {
+		return nil, fmt.Errorf("service: failed to create user: %w", err)
+	}
+	return s.persist(ctx, userToSave, nil)
+}
+
+// timestamp returns the current time at the millisecond precision MongoDB stores
+func (s *service) timestamp() time.Time {
+	return s.now().UTC().Truncate(time.Millisecond)
+}
+
+// persist saves a user and records the change in the same transaction, existingUser is nil for a new user
+func (s *service) persist(ctx context.Context, userToSave *user.User, existingUser *user.User) (*user.User, error) {
+	var savedUser *user.User
+	err := s.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if savedUser, err = s.userRepository.Save(ctx, userToSave); err != nil {
+			return fmt.Errorf("service: failed to save user: %w", err)
+		}
+		action := user.AuditActionCreate
+		if existingUser != nil {
+			action = user.AuditActionUpdate
+		}
+		return s.recordChange(ctx, action, existingUser, savedUser)
+	})
+	if err != nil {
+		return nil, err
+	}
 	return savedUser, nil
 }
 
+// findByID finds a user by id, returning a user.MergedError when the user was merged into another user
+func (s *service) findByID(ctx context.Context, id string) (*user.User, error) {
+	foundUser, err := s.userRepository.FindByID(ctx, id)
+	if !errors.Is(err, user.ErrNotFound) {
+		return foundUser, err
+	}
+	tombstone, tombstoneErr := s.userRepository.FindTombstone(ctx, id)
+	if tombstoneErr != nil {
+		if !errors.Is(tombstoneErr, user.ErrNotFound) {
+			return nil, tombstoneErr
+		}
+		return nil, err
+	}
+	return nil, &user.MergedError{ID: id, MergedInto: tombstone.MergedInto}
+}
+
+// findExisting returns the stored user with the id, nil when there is none
+func (s *service) findExisting(ctx context.Context, id string) (*user.User, error) {
+	if id == "" {
+		return nil, nil
+	}
+	existingUser, err := s.userRepository.FindByID(ctx, id)
+	if errors.Is(err, user.ErrNotFound) {
+		return nil, nil
+	}
+	return existingUser, err
+}
+
+// recordChange records the change in the audit trail and emits its event to the outbox.
+// Updates that change no field are neither recorded nor emitted.
+func (s *service) recordChange(ctx context.Context, action user.AuditAction, before *user.User, after *user.User) error {
+	changes := user.Diff(before, after)
+	if action == user.AuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+	changedUser := after
+	if changedUser == nil {
+		changedUser = before
+	}
+	return s.record(ctx, &user.AuditRecord{UserID: changedUser.ID, Action: action, Changes: changes}, user.EventTypeOf(action), changedUser)
+}
+
+// record completes a record of a change to the user with its id, actor, request id and time, appends it to the audit
+// trail and emits the event of the change to the outbox
+func (s *service) record(ctx context.Context, record *user.AuditRecord, eventType user.EventType, changedUser *user.User) error {
+	recordID, err := newID()
+	if err != nil {
+		return fmt.Errorf("service: failed to generate audit record ID: %w", err)
+	}
+	eventID, err := newID()
+	if err != nil {
+		return fmt.Errorf("service: failed to generate event ID: %w", err)
+	}
+	actor, requestID, now := shared.ActorFromContext(ctx), shared.RequestIDFromContext(ctx), s.now().UTC()
+
+	record.ID, record.Actor, record.RequestID, record.Timestamp = recordID, actor, requestID, now
+	if err := s.auditRepository.Append(ctx, record); err != nil {
+		return fmt.Errorf("service: failed to record %s of user %q: %w", record.Action, changedUser.ID, err)
+	}
+
+	event := &user.Event{
+		ID:         eventID,
+		Type:       eventType,
+		UserID:     changedUser.ID,
+		User:       *changedUser,
+		Changes:    record.Changes,
+		Actor:      actor,
+		RequestID:  requestID,
+		OccurredAt: now,
+	}
+	if err := s.outbox.Add(ctx, event); err != nil {
+		return fmt.Errorf("service: failed to add %s event of user %q to the outbox: %w", event.Type, changedUser.ID, err)
+	}
+	return nil
+}
+
 func (s *service) nameCombinationExists(ctx context.Context, user *user.User) bool {
 	if user.ID == "" {
 		return s.userRepository.ExistsByFirstNameAndLastName(ctx, user.FirstName, user.LastName)
 	}
 	return s.userRepository.ExistsByFirstNameAndLastNameAndIDNot(ctx, user.FirstName, user.LastName, user.ID)
 }
+
+// newID generates a random 128 bit hex encoded id
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -2,10 +2,14 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
 	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
 )
 
@@ -16,8 +20,14 @@ type mockUserValidationService struct {
 type mockUserRepository struct {
 	FindByIDFunc                             func(ctx context.Context, id string) (*userDomain.User, error)
 	SaveFunc                                 func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	DeleteFunc                               func(ctx context.Context, id string) error
+	ListFunc                                 func(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error)
+	StreamFunc                               func(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error)
 	ExistsByFirstNameAndLastNameFunc         func(ctx context.Context, firstName string, lastName string) bool
 	ExistsByFirstNameAndLastNameAndIDNotFunc func(ctx context.Context, firstName string, lastName string, id string) bool
+	ListDuplicateCandidatesFunc              func(ctx context.Context, user *userDomain.User, limit int) ([]*userDomain.User, error)
+	TombstoneFunc                            func(ctx context.Context, tombstone *userDomain.Tombstone, version int64) error
+	FindTombstoneFunc                        func(ctx context.Context, id string) (*userDomain.Tombstone, error)
 }
 
 func (m *mockUserRepository) FindByID(ctx context.Context, id string) (*userDomain.User, error) {
@@ -26,16 +36,103 @@ func (m *mockUserRepository) FindByID(ctx context.Context, id string) (*userDoma
 func (m *mockUserRepository) Save(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
 	return m.SaveFunc(ctx, user)
 }
+func (m *mockUserRepository) Delete(ctx context.Context, id string) error {
+	return m.DeleteFunc(ctx, id)
+}
+func (m *mockUserRepository) List(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error) {
+	return m.ListFunc(ctx, filter)
+}
+func (m *mockUserRepository) Stream(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error) {
+	return m.StreamFunc(ctx, filter)
+}
 func (m *mockUserRepository) ExistsByFirstNameAndLastName(ctx context.Context, firstName string, lastName string) bool {
 	return m.ExistsByFirstNameAndLastNameFunc(ctx, firstName, lastName)
 }
 func (m *mockUserRepository) ExistsByFirstNameAndLastNameAndIDNot(ctx context.Context, firstName string, lastName string, id string) bool {
 	return m.ExistsByFirstNameAndLastNameAndIDNotFunc(ctx, firstName, lastName, id)
 }
+func (m *mockUserRepository) ListDuplicateCandidates(ctx context.Context, user *userDomain.User, limit int) ([]*userDomain.User, error) {
+	return m.ListDuplicateCandidatesFunc(ctx, user, limit)
+}
+func (m *mockUserRepository) Tombstone(ctx context.Context, tombstone *userDomain.Tombstone, version int64) error {
+	return m.TombstoneFunc(ctx, tombstone, version)
+}
+func (m *mockUserRepository) FindTombstone(ctx context.Context, id string) (*userDomain.Tombstone, error) {
+	return m.FindTombstoneFunc(ctx, id)
+}
+func (m *mockUserValidationService) Normalize(user *userDomain.User) {}
+
 func (m *mockUserValidationService) ValidateUser(user userDomain.User) error {
 	return m.ValidateUserFunc(user)
 }
 
+type mockAuditRepository struct {
+	AppendFunc       func(ctx context.Context, record *userDomain.AuditRecord) error
+	ListByUserIDFunc func(ctx context.Context, userID string) ([]*userDomain.AuditRecord, error)
+}
+
+func (m *mockAuditRepository) Append(ctx context.Context, record *userDomain.AuditRecord) error {
+	return m.AppendFunc(ctx, record)
+}
+func (m *mockAuditRepository) ListByUserID(ctx context.Context, userID string) ([]*userDomain.AuditRecord, error) {
+	return m.ListByUserIDFunc(ctx, userID)
+}
+
+// newSliceAuditRepository creates a mock audit repository that appends to records
+func newSliceAuditRepository(records *[]*userDomain.AuditRecord) *mockAuditRepository {
+	return &mockAuditRepository{
+		AppendFunc: func(ctx context.Context, record *userDomain.AuditRecord) error {
+			*records = append(*records, record)
+			return nil
+		},
+		ListByUserIDFunc: func(ctx context.Context, userID string) ([]*userDomain.AuditRecord, error) {
+			var userRecords []*userDomain.AuditRecord
+			for _, record := range *records {
+				if record.UserID == userID {
+					userRecords = append(userRecords, record)
+				}
+			}
+			return userRecords, nil
+		},
+	}
+}
+
+type mockOutbox struct {
+	AddFunc           func(ctx context.Context, event *userDomain.Event) error
+	PendingFunc       func(ctx context.Context, limit int) ([]*userDomain.Event, error)
+	MarkDeliveredFunc func(ctx context.Context, id string) error
+}
+
+func (m *mockOutbox) Add(ctx context.Context, event *userDomain.Event) error {
+	return m.AddFunc(ctx, event)
+}
+func (m *mockOutbox) Pending(ctx context.Context, limit int) ([]*userDomain.Event, error) {
+	return m.PendingFunc(ctx, limit)
+}
+func (m *mockOutbox) MarkDelivered(ctx context.Context, id string) error {
+	return m.MarkDeliveredFunc(ctx, id)
+}
+
+// newSliceOutbox creates a mock outbox that appends to events
+func newSliceOutbox(events *[]*userDomain.Event) *mockOutbox {
+	return &mockOutbox{
+		AddFunc: func(ctx context.Context, event *userDomain.Event) error {
+			*events = append(*events, event)
+			return nil
+		},
+	}
+}
+
+// mockTransactor runs the function without a transaction, counting the transactions
+type mockTransactor struct {
+	transactions int
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.transactions++
+	return fn(ctx)
+}
+
 func TestService_Find(t *testing.T) {
 	tests := []struct {
 		name                      string
@@ -82,7 +179,7 @@ func TestService_Find(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			service := NewService(test.mockUserValidationService, test.mockUserRepository)
+			service := NewService(test.mockUserValidationService, test.mockUserRepository, newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
 			user, err := service.Find(context.Background(), test.userID)
 
 			// Check error
@@ -155,6 +252,9 @@ func TestService_Save(t *testing.T) {
 				},
 			},
 			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return nil, userDomain.ErrNotFound
+				},
 				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
 					return user, nil
 				},
@@ -163,6 +263,25 @@ func TestService_Save(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:          "replace a user at another version",
+			user:          userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 1},
+			expectedError: true,
+			errorContains: userDomain.ErrVersionConflict.Error(),
+			mockUserValidationService: &mockUserValidationService{
+				ValidateUserFunc: func(user userDomain.User) error {
+					return nil
+				},
+			},
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: id, Version: 2}, nil
+				},
+				ExistsByFirstNameAndLastNameAndIDNotFunc: func(ctx context.Context, firstName string, lastName string, id string) bool {
+					return false
+				},
+			},
+		},
 		{
 			name:          "save a user with existing name combination",
 			user:          userDomain.User{ID: "2", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
@@ -215,7 +334,7 @@ func TestService_Save(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			service := NewService(test.mockUserValidationService, test.mockUserRepository)
+			service := NewService(test.mockUserValidationService, test.mockUserRepository, newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
 			savedUser, err := service.Save(context.Background(), &test.user)
 
 			if test.expectedError {
@@ -253,3 +372,619 @@ func TestService_Save(t *testing.T) {
 		})
 	}
 }
+
+func TestService_Create(t *testing.T) {
+	tests := []struct {
+		name               string
+		user               userDomain.User
+		mockUserRepository *mockUserRepository
+		expectedError      error
+	}{
+		{
+			name: "create a user with a new ID",
+			user: userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return nil, userDomain.ErrNotFound
+				},
+				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					return user, nil
+				},
+				ExistsByFirstNameAndLastNameAndIDNotFunc: func(ctx context.Context, firstName string, lastName string, id string) bool {
+					return false
+				},
+			},
+		},
+		{
+			name: "create a user without an ID",
+			user: userDomain.User{FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+			mockUserRepository: &mockUserRepository{
+				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					return user, nil
+				},
+				ExistsByFirstNameAndLastNameAndIDNotFunc: func(ctx context.Context, firstName string, lastName string, id string) bool {
+					return false
+				},
+			},
+		},
+		{
+			name: "create a user with an existing ID",
+			user: userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: "1"}, nil
+				},
+			},
+			expectedError: userDomain.ErrAlreadyExists,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validationService := &mockUserValidationService{
+				ValidateUserFunc: func(user userDomain.User) error {
+					return nil
+				},
+			}
+			service := NewService(validationService, test.mockUserRepository, newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+			createdUser, err := service.Create(context.Background(), &test.user)
+
+			if test.expectedError != nil {
+				if !errors.Is(err, test.expectedError) {
+					t.Errorf("Create() error = %v, want %v", err, test.expectedError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Create() unexpected error: %v", err)
+			}
+			if createdUser.ID == "" {
+				t.Errorf("Create() expected user ID to be set")
+			}
+		})
+	}
+}
+
+func TestService_Update(t *testing.T) {
+	tests := []struct {
+		name               string
+		version            int64
+		mockUserRepository *mockUserRepository
+		expectedError      error
+	}{
+		{
+			name: "update an existing user",
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: id}, nil
+				},
+				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					return user, nil
+				},
+				ExistsByFirstNameAndLastNameAndIDNotFunc: func(ctx context.Context, firstName string, lastName string, id string) bool {
+					return false
+				},
+			},
+		},
+		{
+			name:    "update at the current version",
+			version: 3,
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: id, Version: 3}, nil
+				},
+				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					if user.Version != 3 {
+						return nil, userDomain.ErrVersionConflict
+					}
+					return user, nil
+				},
+				ExistsByFirstNameAndLastNameAndIDNotFunc: func(ctx context.Context, firstName string, lastName string, id string) bool {
+					return false
+				},
+			},
+		},
+		{
+			name: "update without the current version",
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: id, Version: 3}, nil
+				},
+			},
+			expectedError: userDomain.ErrVersionConflict,
+		},
+		{
+			name:    "update a stale version",
+			version: 2,
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: id, Version: 3}, nil
+				},
+			},
+			expectedError: userDomain.ErrVersionConflict,
+		},
+		{
+			name: "update a missing user",
+			mockUserRepository: &mockUserRepository{
+				FindByIDFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return nil, userDomain.ErrNotFound
+				},
+				FindTombstoneFunc: func(ctx context.Context, id string) (*userDomain.Tombstone, error) {
+					return nil, userDomain.ErrNotFound
+				},
+			},
+			expectedError: userDomain.ErrNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validationService := &mockUserValidationService{
+				ValidateUserFunc: func(user userDomain.User) error {
+					return nil
+				},
+			}
+			service := NewService(validationService, test.mockUserRepository, newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+			user := &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: test.version}
+			_, err := service.Update(context.Background(), user)
+
+			if !errors.Is(err, test.expectedError) {
+				t.Errorf("Update() error = %v, want %v", err, test.expectedError)
+			}
+		})
+	}
+}
+
+func TestService_AuditTrail(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	var records []*userDomain.AuditRecord
+	var events []*userDomain.Event
+	userRepository := newMapUserRepository(users)
+	service := NewService(userDomain.NewValidationService(), userRepository, newSliceAuditRepository(&records), newSliceOutbox(&events), &mockTransactor{})
+	ctx := shared.WithRequestID(shared.WithActor(context.Background(), "alice"), "request-1")
+
+	if _, err := service.Create(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if _, err := service.Save(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "johnny@example.com", Age: 25}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	if _, err := service.Save(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "johnny@example.com", Age: 25}); err != nil {
+		t.Fatalf("Save() unchanged user unexpected error: %v", err)
+	}
+	if err := service.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+
+	trail, err := service.AuditTrail(ctx, "1")
+	if err != nil {
+		t.Fatalf("AuditTrail() unexpected error: %v", err)
+	}
+	var actions []userDomain.AuditAction
+	for _, record := range trail {
+		actions = append(actions, record.Action)
+		if record.Actor != "alice" || record.RequestID != "request-1" {
+			t.Errorf("AuditTrail() record actor = %q request ID = %q, want alice and request-1", record.Actor, record.RequestID)
+		}
+	}
+	if fmt.Sprint(actions) != "[create update delete]" {
+		t.Fatalf("AuditTrail() actions = %v, want [create update delete]", actions)
+	}
+	expectedChange := userDomain.FieldChange{Field: userDomain.FieldEmail, Before: "john@example.com", After: "johnny@example.com"}
+	if changes := trail[1].Changes; len(changes) != 1 || changes[0] != expectedChange {
+		t.Errorf("AuditTrail() update changes = %v, want [%v]", changes, expectedChange)
+	}
+
+	if _, err := service.AuditTrail(ctx, "2"); !errors.Is(err, userDomain.ErrNotFound) {
+		t.Errorf("AuditTrail() unknown user error = %v, want %v", err, userDomain.ErrNotFound)
+	}
+}
+
+func TestService_Events(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	var events []*userDomain.Event
+	transactor := &mockTransactor{}
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(&events), transactor)
+	ctx := shared.WithActor(context.Background(), "alice")
+
+	if _, err := service.Create(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if _, err := service.Update(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 26}); err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if err := service.Delete(ctx, "1"); err != nil {
+		t.Fatalf("Delete() unexpected error: %v", err)
+	}
+
+	var types []userDomain.EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+		if event.UserID != "1" || event.User.ID != "1" || event.Actor != "alice" || event.ID == "" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	}
+	expected := []userDomain.EventType{userDomain.EventUserCreated, userDomain.EventUserUpdated, userDomain.EventUserDeleted}
+	if fmt.Sprint(types) != fmt.Sprint(expected) {
+		t.Errorf("event types = %v, want %v", types, expected)
+	}
+	if events[2].User.Age != 26 {
+		t.Errorf("deleted event user = %+v, want the user before deletion", events[2].User)
+	}
+	if transactor.transactions != 3 {
+		t.Errorf("transactions = %d, want one per change", transactor.transactions)
+	}
+}
+
+func TestService_Timestamps(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+	created := time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+	updated := created.Add(time.Hour)
+	ctx := context.Background()
+
+	service.now = func() time.Time { return created }
+	// client supplied timestamps are ignored
+	createdUser, err := service.Create(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25,
+		CreatedAt: time.Unix(0, 0), UpdatedAt: time.Unix(0, 0)})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if expected := created.Truncate(time.Millisecond); !createdUser.CreatedAt.Equal(expected) || !createdUser.UpdatedAt.Equal(expected) {
+		t.Errorf("created user timestamps = %v, %v, want %v", createdUser.CreatedAt, createdUser.UpdatedAt, expected)
+	}
+
+	service.now = func() time.Time { return updated }
+	updatedUser, err := service.Update(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 26})
+	if err != nil {
+		t.Fatalf("Update() unexpected error: %v", err)
+	}
+	if !updatedUser.CreatedAt.Equal(createdUser.CreatedAt) || !updatedUser.UpdatedAt.Equal(updated.Truncate(time.Millisecond)) {
+		t.Errorf("updated user timestamps = %v, %v, want %v, %v", updatedUser.CreatedAt, updatedUser.UpdatedAt, createdUser.CreatedAt, updated)
+	}
+}
+
+func TestService_Save_OutboxFailure(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	outbox := &mockOutbox{AddFunc: func(ctx context.Context, event *userDomain.Event) error {
+		return errors.New("outbox unavailable")
+	}}
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(new([]*userDomain.AuditRecord)), outbox, &mockTransactor{})
+
+	_, err := service.Save(context.Background(), &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25})
+	if err == nil || !strings.Contains(err.Error(), "outbox unavailable") {
+		t.Errorf("Save() error = %v, want the outbox error", err)
+	}
+}
+
+func TestService_Save_WithoutID(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+	first, err := service.Save(context.Background(), &userDomain.User{FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25})
+	if err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	second, err := service.Save(context.Background(), &userDomain.User{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 25})
+	if err != nil {
+		t.Fatalf("Save() of a second user without id unexpected error: %v", err)
+	}
+	if first.ID == "" || second.ID == "" || first.ID == second.ID {
+		t.Errorf("Save() ids = %q and %q, want two generated ids", first.ID, second.ID)
+	}
+	if len(users) != 2 {
+		t.Errorf("Save() stored %d users, want 2", len(users))
+	}
+}
+
+func TestService_Transition(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	var events []*userDomain.Event
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(&events), &mockTransactor{})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := shared.WithActor(context.Background(), "admin")
+
+	createdUser, err := service.Create(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25,
+		Status: userDomain.StatusActive})
+	if err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if createdUser.Status != userDomain.StatusPending || len(createdUser.StatusHistory) != 1 || createdUser.StatusHistory[0].Actor != "admin" {
+		t.Fatalf("created user status = %q with %v, want pending with one transition by admin", createdUser.Status, createdUser.StatusHistory)
+	}
+
+	verifiedUser, err := service.Transition(ctx, "1", userDomain.StatusVerified, "documents checked")
+	if err != nil {
+		t.Fatalf("Transition() unexpected error: %v", err)
+	}
+	expected := userDomain.StatusTransition{From: userDomain.StatusPending, To: userDomain.StatusVerified, At: now, Actor: "admin", Reason: "documents checked"}
+	if verifiedUser.Status != userDomain.StatusVerified || len(verifiedUser.StatusHistory) != 2 || verifiedUser.StatusHistory[1] != expected {
+		t.Errorf("verified user status = %q with %v, want verified after %v", verifiedUser.Status, verifiedUser.StatusHistory, expected)
+	}
+	if len(createdUser.StatusHistory) != 1 {
+		t.Errorf("expected the transition not to change the history of the stored user, got %v", createdUser.StatusHistory)
+	}
+	if last := events[len(events)-1]; last.Type != userDomain.EventUserUpdated || len(last.Changes) != 1 || last.Changes[0].Field != userDomain.FieldStatus {
+		t.Errorf("last event = %s %v, want an update of %s", last.Type, last.Changes, userDomain.FieldStatus)
+	}
+
+	if _, err := service.Transition(ctx, "1", userDomain.StatusSuspended, ""); !errors.Is(err, userDomain.ErrInvalidTransition) {
+		t.Errorf("Transition() error = %v, want %v", err, userDomain.ErrInvalidTransition)
+	}
+	if _, err := service.Transition(ctx, "2", userDomain.StatusVerified, ""); !errors.Is(err, userDomain.ErrNotFound) {
+		t.Errorf("Transition() error = %v, want %v", err, userDomain.ErrNotFound)
+	}
+
+	// saving a user keeps the stored status whatever the client sent
+	savedUser, err := service.Save(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 26,
+		Status: userDomain.StatusOffboarded})
+	if err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	if savedUser.Status != userDomain.StatusVerified || len(savedUser.StatusHistory) != 2 {
+		t.Errorf("saved user status = %q with %v, want the stored verified status", savedUser.Status, savedUser.StatusHistory)
+	}
+}
+
+func TestService_VerifyEmail(t *testing.T) {
+	users := map[string]*userDomain.User{}
+	var records []*userDomain.AuditRecord
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(&records), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if _, err := service.Create(ctx, &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25,
+		EmailVerifiedAt: now}); err != nil {
+		t.Fatalf("Create() unexpected error: %v", err)
+	}
+	if users["1"].EmailVerified() {
+		t.Fatal("expected a client supplied verification time to be ignored")
+	}
+	if _, err := service.VerifyEmail(ctx, "1", "old@example.com"); !errors.Is(err, userDomain.ErrEmailChanged) {
+		t.Errorf("VerifyEmail() error = %v, want %v", err, userDomain.ErrEmailChanged)
+	}
+
+	verifiedUser, err := service.VerifyEmail(ctx, "1", "john@example.com")
+	if err != nil {
+		t.Fatalf("VerifyEmail() unexpected error: %v", err)
+	}
+	if !verifiedUser.EmailVerifiedAt.Equal(now) {
+		t.Errorf("email verified at %v, want %v", verifiedUser.EmailVerifiedAt, now)
+	}
+	if last := records[len(records)-1]; len(last.Changes) != 1 || last.Changes[0].Field != userDomain.FieldEmailVerifiedAt {
+		t.Errorf("last audit record changes = %v, want %s", last.Changes, userDomain.FieldEmailVerifiedAt)
+	}
+
+	savedUser, err := service.Save(ctx, &userDomain.User{ID: "1", FirstName: "Johnny", LastName: "Doe", Email: "john@example.com", Age: 25})
+	if err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	if !savedUser.EmailVerified() {
+		t.Error("expected the email to stay verified while it is unchanged")
+	}
+	savedUser, err = service.Save(ctx, &userDomain.User{ID: "1", FirstName: "Johnny", LastName: "Doe", Email: "johnny@example.com", Age: 25})
+	if err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	if savedUser.EmailVerified() {
+		t.Error("expected a changed email not to be verified")
+	}
+}
+
+func TestService_Save_DuplicateDetection(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          []ServiceOption
+		expectedErr      error
+		expectedWarnings []string
+	}{
+		{
+			name: "off by default",
+		},
+		{
+			name:             "advisory",
+			options:          []ServiceOption{WithDuplicateDetection(userDomain.DefaultDuplicateConfig())},
+			expectedWarnings: []string{"1"},
+		},
+		{
+			name:        "blocking",
+			options:     []ServiceOption{WithDuplicateDetection(userDomain.DuplicateConfig{Mode: userDomain.DuplicateModeBlocking, Threshold: 0.9})},
+			expectedErr: userDomain.ErrPossibleDuplicate,
+		},
+		{
+			name:    "below the threshold",
+			options: []ServiceOption{WithDuplicateDetection(userDomain.DuplicateConfig{Mode: userDomain.DuplicateModeBlocking, Threshold: 0.99})},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := map[string]*userDomain.User{
+				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", CanonicalEmail: "john@example.com", Age: 25, Version: 1},
+				"2": {ID: "2", FirstName: "Jane", LastName: "Smith", Email: "jane@example.com", CanonicalEmail: "jane@example.com", Age: 25, Version: 1},
+			}
+			service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+				newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{}, test.options...)
+			ctx := shared.WithWarnings(context.Background())
+
+			_, err := service.Save(ctx, &userDomain.User{ID: "3", FirstName: "Jon", LastName: "Doe", Email: "jon.doe@example.com", Age: 25})
+
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Save() error = %v, want %v", err, test.expectedErr)
+			}
+			var duplicateErr *userDomain.DuplicateError
+			if test.expectedErr != nil && (!errors.As(err, &duplicateErr) || len(duplicateErr.Candidates) != 1 || duplicateErr.Candidates[0].User.ID != "1") {
+				t.Errorf("Save() error = %v, want a duplicate of user 1", err)
+			}
+			var warnings []string
+			for _, warning := range shared.WarningsFromContext(ctx) {
+				if warning.Code != userDomain.ErrorPossibleDuplicate || warning.Severity != shared.SeverityWarning {
+					t.Errorf("unexpected warning %+v", warning)
+				}
+				warnings = append(warnings, warning.Params["user_id"])
+			}
+			if !reflect.DeepEqual(warnings, test.expectedWarnings) {
+				t.Errorf("warnings of users %v, want %v", warnings, test.expectedWarnings)
+			}
+		})
+	}
+}
+
+func TestService_Duplicates(t *testing.T) {
+	users := map[string]*userDomain.User{
+		"1": {ID: "1", FirstName: "John", LastName: "Doe", CanonicalEmail: "john@example.com"},
+		"2": {ID: "2", FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com"},
+		"3": {ID: "3", FirstName: "Jane", LastName: "Smith", CanonicalEmail: "john@example.com"},
+		"4": {ID: "4", FirstName: "Max", LastName: "Mustermann", CanonicalEmail: "max@example.com"},
+	}
+	service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+		newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+	candidates, err := service.Duplicates(context.Background(), "1")
+	if err != nil {
+		t.Fatalf("Duplicates() unexpected error: %v", err)
+	}
+	var ids []string
+	for _, candidate := range candidates {
+		ids = append(ids, candidate.User.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"3", "2"}) {
+		t.Errorf("Duplicates() = %v, want the email match 3 then the name match 2", ids)
+	}
+
+	if _, err := service.Duplicates(context.Background(), "5"); !errors.Is(err, userDomain.ErrNotFound) {
+		t.Errorf("Duplicates() error = %v, want %v", err, userDomain.ErrNotFound)
+	}
+}
+
+func TestService_Merge(t *testing.T) {
+	verifiedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	newUsers := func() map[string]*userDomain.User {
+		return map[string]*userDomain.User{
+			"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Status: userDomain.StatusActive,
+				UpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Version: 4},
+			"2": {ID: "2", FirstName: "Jon", LastName: "Doe", Email: "jon@example.com", EmailVerifiedAt: verifiedAt, Age: 25, Phone: "+14155550123",
+				Locale: "en-US", UpdatedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC), Version: 1},
+			// user 3 was saved before phones were validated
+			"3": {ID: "3", FirstName: "Johnny", LastName: "Doe", Email: "johnny@example.com", Age: 25, Phone: "0123", Locale: "de-DE",
+				UpdatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC), Version: 1},
+		}
+	}
+	tests := []struct {
+		name          string
+		request       userDomain.MergeRequest
+		expectedError error
+		expectedUser  *userDomain.User
+	}{
+		{
+			name: "merge with rules",
+			request: userDomain.MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2", "3"}, Rules: map[string]string{
+				userDomain.FieldFirstName: "2",
+				userDomain.FieldEmail:     "2",
+				userDomain.FieldPhone:     userDomain.MergeRuleNonEmpty,
+				userDomain.FieldLocale:    userDomain.MergeRuleNewest,
+			}},
+			expectedUser: &userDomain.User{ID: "1", FirstName: "Jon", LastName: "Doe", Email: "jon@example.com", EmailVerifiedAt: verifiedAt,
+				Age: 25, Phone: "+14155550123", Locale: "en-US", Status: userDomain.StatusActive, Version: 4},
+		},
+		{
+			name:          "invalid request",
+			request:       userDomain.MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"1"}},
+			expectedError: userDomain.ErrInvalidMerge,
+		},
+		{
+			name:          "missing duplicate",
+			request:       userDomain.MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"4"}},
+			expectedError: userDomain.ErrNotFound,
+		},
+		{
+			name:          "invalid merged user",
+			request:       userDomain.MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"3"}, Rules: map[string]string{userDomain.FieldPhone: "3"}},
+			expectedError: userDomain.NewPhoneFormatError("0123"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := newUsers()
+			repository := newMapUserRepository(users)
+			records, events, transactor := new([]*userDomain.AuditRecord), new([]*userDomain.Event), &mockTransactor{}
+			service := NewService(userDomain.NewValidationService(), repository, newSliceAuditRepository(records), newSliceOutbox(events), transactor)
+			service.now = func() time.Time { return time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC) }
+
+			mergedUser, err := service.Merge(context.Background(), test.request)
+
+			if !errors.Is(err, test.expectedError) {
+				t.Fatalf("Merge() error = %v, want %v", err, test.expectedError)
+			}
+			if test.expectedError != nil {
+				if len(users) != 3 || len(*records) != 0 || transactor.transactions != 0 {
+					t.Errorf("expected a failed merge to change nothing, got users %v and records %v", users, *records)
+				}
+				return
+			}
+			mergedUser.CanonicalEmail, mergedUser.UpdatedAt = "", time.Time{}
+			if !reflect.DeepEqual(mergedUser, test.expectedUser) {
+				t.Errorf("Merge() = %+v, want %+v", mergedUser, test.expectedUser)
+			}
+			if transactor.transactions != 1 {
+				t.Errorf("expected the merge in 1 transaction, got %d", transactor.transactions)
+			}
+			for _, id := range test.request.DuplicateIDs {
+				_, err := service.Find(context.Background(), id)
+				var mergedErr *userDomain.MergedError
+				if !errors.As(err, &mergedErr) || mergedErr.MergedInto != "1" || !errors.Is(err, userDomain.ErrNotFound) {
+					t.Errorf("Find(%q) error = %v, want a user merged into 1", id, err)
+				}
+			}
+			if len(*records) != len(test.request.DuplicateIDs)+1 || len(*events) != len(*records) {
+				t.Fatalf("expected a record and an event for the survivor and every duplicate, got %d records and %d events", len(*records), len(*events))
+			}
+			if record, event := (*records)[0], (*events)[0]; record.Action != userDomain.AuditActionMerge || record.UserID != "1" ||
+				!reflect.DeepEqual(record.MergedIDs, test.request.DuplicateIDs) || event.Type != userDomain.EventUserUpdated {
+				t.Errorf("unexpected survivor record %+v and event type %s", record, event.Type)
+			}
+			for i, record := range (*records)[1:] {
+				if record.Action != userDomain.AuditActionMerge || record.UserID != test.request.DuplicateIDs[i] || record.MergedInto != "1" ||
+					(*events)[i+1].Type != userDomain.EventUserDeleted {
+					t.Errorf("unexpected duplicate record %+v", record)
+				}
+			}
+		})
+	}
+}
+
+func TestService_Merge_ChangedDuplicate(t *testing.T) {
+	users := map[string]*userDomain.User{
+		"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 1},
+		"2": {ID: "2", FirstName: "Jon", LastName: "Doe", Email: "jon@example.com", Age: 25, Version: 1},
+	}
+	repository := newMapUserRepository(users)
+	findByID := repository.FindByIDFunc
+	// user 2 is changed by another request after the merge read it
+	repository.FindByIDFunc = func(ctx context.Context, id string) (*userDomain.User, error) {
+		found, err := findByID(ctx, id)
+		if id == "2" {
+			changed := *users["2"]
+			changed.Version++
+			users["2"] = &changed
+		}
+		return found, err
+	}
+	service := NewService(userDomain.NewValidationService(), repository, newSliceAuditRepository(new([]*userDomain.AuditRecord)),
+		newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+	_, err := service.Merge(context.Background(), userDomain.MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2"}})
+
+	if !errors.Is(err, userDomain.ErrVersionConflict) {
+		t.Errorf("Merge() error = %v, want %v", err, userDomain.ErrVersionConflict)
+	}
+}
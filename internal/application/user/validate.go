@@ -0,0 +1,48 @@
+package user
+
+import (
+	"context"
+	"strings"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// ageFields are the fields age failures are reported on
+var ageFields = map[string]bool{user.FieldAge: true, user.FieldDateOfBirth: true}
+
+// Validate normalizes and validates the user and checks its name uniqueness like a save, without saving it.
+// A user with an id is checked as an update of that user. When field is not empty only the failures of that field
+// are returned, name uniqueness being a failure of both names and age failures being failures of both the age and the
+// date of birth it is computed from. The address field covers the failures of each part of the address.
+// Unless duplicate detection is off, the possible duplicates of the user are failures of the names and the email,
+// reported as errors in blocking mode and as warnings in advisory mode.
+func (s *service) Validate(ctx context.Context, userToValidate *user.User, field string) []shared.ValidationError {
+	s.userValidationService.Normalize(userToValidate)
+	var failures []shared.ValidationError
+	for _, validationError := range shared.ValidationErrors(s.userValidationService.ValidateUser(*userToValidate)) {
+		if field == "" || validationError.Field == field || ageFields[field] && ageFields[validationError.Field] ||
+			field == user.FieldAddress && strings.HasPrefix(validationError.Field, user.FieldAddress+".") {
+			failures = append(failures, validationError)
+		}
+	}
+
+	checksNames := field == "" || field == user.FieldFirstName || field == user.FieldLastName
+	if checksNames && userToValidate.FirstName != "" && userToValidate.LastName != "" && s.nameCombinationExists(ctx, userToValidate) {
+		failures = append(failures, user.NewNameCombinationExistsError())
+	}
+
+	checksDuplicates := s.duplicateConfig.Mode != user.DuplicateModeOff && (checksNames || field == user.FieldEmail)
+	if checksDuplicates {
+		// a failed lookup is not a failure of the user
+		candidates, _ := s.duplicates(ctx, userToValidate)
+		for _, candidate := range candidates {
+			warning := user.NewPossibleDuplicateWarning(candidate)
+			if s.duplicateConfig.Mode == user.DuplicateModeBlocking {
+				warning.Severity = shared.SeverityError
+			}
+			failures = append(failures, warning)
+		}
+	}
+	return failures
+}
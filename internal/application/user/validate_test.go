@@ -0,0 +1,104 @@
+package user
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestService_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		user          userDomain.User
+		field         string
+		expectedCodes []string
+	}{
+		{
+			name: "valid user",
+			user: userDomain.User{FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 30},
+		},
+		{
+			name:          "every failure",
+			user:          userDomain.User{FirstName: "john", LastName: " DOE ", Email: "john", Age: 12},
+			expectedCodes: []string{userDomain.ErrorAgeMinimum, userDomain.ErrorEmailFormat, userDomain.ErrorNameCombinationExists},
+		},
+		{
+			name:          "single field",
+			user:          userDomain.User{FirstName: "John", LastName: "Doe", Email: "john", Age: 12},
+			field:         userDomain.FieldEmail,
+			expectedCodes: []string{userDomain.ErrorEmailFormat},
+		},
+		{
+			name:          "name field includes uniqueness",
+			user:          userDomain.User{FirstName: "John", LastName: "Doe"},
+			field:         userDomain.FieldLastName,
+			expectedCodes: []string{userDomain.ErrorNameCombinationExists},
+		},
+		{
+			name: "update of the same user",
+			user: userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 26},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var records []*userDomain.AuditRecord
+			users := map[string]*userDomain.User{
+				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 1},
+			}
+			service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+				newSliceAuditRepository(&records), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{})
+
+			var codes []string
+			for _, failure := range service.Validate(context.Background(), &test.user, test.field) {
+				codes = append(codes, failure.Code)
+			}
+
+			if !reflect.DeepEqual(codes, test.expectedCodes) {
+				t.Errorf("Validate() codes = %v, want %v", codes, test.expectedCodes)
+			}
+			if len(users) != 1 || users["1"].Version != 1 || len(records) != 0 {
+				t.Errorf("Validate() changed the repository: users %v, audit records %v", users, records)
+			}
+		})
+	}
+}
+
+func TestService_Validate_Duplicates(t *testing.T) {
+	tests := []struct {
+		name             string
+		mode             userDomain.DuplicateMode
+		field            string
+		expectedSeverity []shared.Severity
+	}{
+		{name: "advisory", mode: userDomain.DuplicateModeAdvisory, expectedSeverity: []shared.Severity{shared.SeverityWarning}},
+		{name: "blocking", mode: userDomain.DuplicateModeBlocking, expectedSeverity: []shared.Severity{shared.SeverityError}},
+		{name: "off", mode: userDomain.DuplicateModeOff},
+		{name: "other field", mode: userDomain.DuplicateModeBlocking, field: userDomain.FieldPhone},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			users := map[string]*userDomain.User{
+				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", CanonicalEmail: "john@example.com", Age: 25},
+			}
+			service := NewService(userDomain.NewValidationService(), newMapUserRepository(users),
+				newSliceAuditRepository(new([]*userDomain.AuditRecord)), newSliceOutbox(new([]*userDomain.Event)), &mockTransactor{},
+				WithDuplicateDetection(userDomain.DuplicateConfig{Mode: test.mode, Threshold: 0.9}))
+
+			var severities []shared.Severity
+			for _, failure := range service.Validate(context.Background(), &userDomain.User{FirstName: "Jon", LastName: "Doe", Email: "jon@example.com", Age: 30}, test.field) {
+				if failure.Code != userDomain.ErrorPossibleDuplicate || failure.Params["user_id"] != "1" {
+					t.Errorf("unexpected failure %+v", failure)
+				}
+				severities = append(severities, failure.Severity)
+			}
+			if !reflect.DeepEqual(severities, test.expectedSeverity) {
+				t.Errorf("Validate() severities = %v, want %v", severities, test.expectedSeverity)
+			}
+		})
+	}
+}
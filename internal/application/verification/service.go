@@ -0,0 +1,109 @@
+// Package verification contains the logic verifying that users own their email.
+package verification
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type userService interface {
+	// Find finds a user by id
+	Find(ctx context.Context, id string) (*user.User, error)
+	// VerifyEmail records that the user received mail sent to email
+	VerifyEmail(ctx context.Context, id string, email string) (*user.User, error)
+}
+
+// Config configures the verification emails and their tokens
+type Config struct {
+	// Secret signs the tokens, tokens signed with another secret are invalid
+	Secret []byte
+	// TTL is how long a token may be used
+	TTL time.Duration
+	// URL is the verification endpoint linked in the emails, the token is added as its token query parameter
+	URL string
+	// From is the sender of the emails
+	From string
+}
+
+type service struct {
+	userService userService
+	mailer      shared.Mailer
+	config      Config
+	now         func() time.Time
+}
+
+// NewService creates a new verification service sending the emails with the mailer
+func NewService(userService userService, mailer shared.Mailer, config Config) *service {
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	return &service{userService: userService, mailer: mailer, config: config, now: time.Now}
+}
+
+// Send emails a link verifying the current email of a user and returns when the link expires.
+// It returns user.ErrEmailMissing for users without an email and user.ErrEmailAlreadyVerified when it is verified.
+func (s *service) Send(ctx context.Context, id string) (time.Time, error) {
+	recipient, err := s.userService.Find(ctx, id)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("verification: failed to send verification: %w", err)
+	}
+	if recipient.Email == "" {
+		return time.Time{}, fmt.Errorf("verification: failed to send verification to user %q: %w", id, user.ErrEmailMissing)
+	}
+	if recipient.EmailVerified() {
+		return time.Time{}, fmt.Errorf("verification: failed to send verification to user %q: %w", id, user.ErrEmailAlreadyVerified)
+	}
+
+	expiresAt := s.now().UTC().Add(s.config.TTL).Truncate(time.Second)
+	link, err := s.link(Token{UserID: recipient.ID, Email: recipient.Email, ExpiresAt: expiresAt})
+	if err != nil {
+		return time.Time{}, err
+	}
+	message := shared.Message{
+		From:    s.config.From,
+		To:      recipient.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hello %s,\n\nOpen the link below to verify your email address, it expires on %s:\n\n%s\n\n"+
+			"If you did not sign up, you can ignore this email.\n", recipient.FirstName, expiresAt.Format(time.RFC1123), link),
+	}
+	if err := s.mailer.Send(ctx, message); err != nil {
+		return time.Time{}, fmt.Errorf("verification: failed to email user %q: %w", id, err)
+	}
+	return expiresAt, nil
+}
+
+// Verify verifies the email a token was sent to.
+// It returns ErrInvalidToken or ErrTokenExpired for unusable tokens and user.ErrEmailChanged when the user changed
+// its email since.
+func (s *service) Verify(ctx context.Context, value string) (*user.User, error) {
+	token, err := Parse(s.config.Secret, value, s.now())
+	if err != nil {
+		return nil, fmt.Errorf("verification: %w", err)
+	}
+	verifiedUser, err := s.userService.VerifyEmail(ctx, token.UserID, token.Email)
+	if err != nil {
+		return nil, fmt.Errorf("verification: %w", err)
+	}
+	return verifiedUser, nil
+}
+
+// link returns the verification url of a token
+func (s *service) link(token Token) (string, error) {
+	value, err := Sign(s.config.Secret, token)
+	if err != nil {
+		return "", err
+	}
+	link, err := url.Parse(s.config.URL)
+	if err != nil {
+		return "", fmt.Errorf("verification: invalid verification url %q: %w", s.config.URL, err)
+	}
+	query := link.Query()
+	query.Set("token", value)
+	link.RawQuery = query.Encode()
+	return link.String(), nil
+}
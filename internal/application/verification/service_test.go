@@ -0,0 +1,136 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type mockUserService struct {
+	FindFunc        func(ctx context.Context, id string) (*user.User, error)
+	VerifyEmailFunc func(ctx context.Context, id string, email string) (*user.User, error)
+}
+
+func (m *mockUserService) Find(ctx context.Context, id string) (*user.User, error) {
+	return m.FindFunc(ctx, id)
+}
+func (m *mockUserService) VerifyEmail(ctx context.Context, id string, email string) (*user.User, error) {
+	return m.VerifyEmailFunc(ctx, id, email)
+}
+
+type mockMailer struct {
+	SendFunc func(ctx context.Context, message shared.Message) error
+}
+
+func (m *mockMailer) Send(ctx context.Context, message shared.Message) error {
+	return m.SendFunc(ctx, message)
+}
+
+var testConfig = Config{
+	Secret: []byte("0123456789abcdef0123456789abcdef"),
+	TTL:    time.Hour,
+	URL:    "https://onboarding.example.com/verify?source=email",
+	From:   "no-reply@example.com",
+}
+
+func TestService_Send(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        *user.User
+		expectedErr error
+	}{
+		{name: "unverified email", user: &user.User{ID: "1", FirstName: "Jane", Email: "jane@example.com"}},
+		{name: "no email", user: &user.User{ID: "1", FirstName: "Jane"}, expectedErr: user.ErrEmailMissing},
+		{name: "verified email", user: &user.User{ID: "1", Email: "jane@example.com", EmailVerifiedAt: time.Now()}, expectedErr: user.ErrEmailAlreadyVerified},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+			var messages []shared.Message
+			service := NewService(&mockUserService{
+				FindFunc: func(ctx context.Context, id string) (*user.User, error) { return test.user, nil },
+			}, &mockMailer{
+				SendFunc: func(ctx context.Context, message shared.Message) error {
+					messages = append(messages, message)
+					return nil
+				},
+			}, testConfig)
+			service.now = func() time.Time { return now }
+
+			expiresAt, err := service.Send(context.Background(), "1")
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Send() error = %v, want %v", err, test.expectedErr)
+			}
+			if test.expectedErr != nil {
+				if len(messages) != 0 {
+					t.Errorf("expected no email, got %v", messages)
+				}
+				return
+			}
+			if !expiresAt.Equal(now.Add(time.Hour)) {
+				t.Errorf("Send() expiry = %v, want %v", expiresAt, now.Add(time.Hour))
+			}
+			if len(messages) != 1 || messages[0].To != "jane@example.com" || messages[0].From != testConfig.From {
+				t.Fatalf("expected one email to jane@example.com, got %v", messages)
+			}
+			link, err := url.Parse(regexp.MustCompile(`https://\S+`).FindString(messages[0].Body))
+			if err != nil || link.Query().Get("source") != "email" {
+				t.Fatalf("expected the email to link to the verification url, got %q", messages[0].Body)
+			}
+			token, err := Parse(testConfig.Secret, link.Query().Get("token"), now)
+			if err != nil || token != (Token{UserID: "1", Email: "jane@example.com", ExpiresAt: expiresAt}) {
+				t.Errorf("linked token = %+v (%v), want the user's email until %v", token, err, expiresAt)
+			}
+		})
+	}
+}
+
+func TestService_Verify(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	value, _ := Sign(testConfig.Secret, Token{UserID: "1", Email: "jane@example.com", ExpiresAt: now.Add(time.Hour)})
+
+	tests := []struct {
+		name           string
+		value          string
+		now            time.Time
+		verifyEmailErr error
+		expectedErr    error
+	}{
+		{name: "valid token", value: value, now: now},
+		{name: "invalid token", value: value + "x", now: now, expectedErr: ErrInvalidToken},
+		{name: "expired token", value: value, now: now.Add(2 * time.Hour), expectedErr: ErrTokenExpired},
+		{name: "email changed", value: value, now: now, verifyEmailErr: user.ErrEmailChanged, expectedErr: user.ErrEmailChanged},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			service := NewService(&mockUserService{
+				VerifyEmailFunc: func(ctx context.Context, id string, email string) (*user.User, error) {
+					if id != "1" || email != "jane@example.com" {
+						t.Errorf("VerifyEmail() called with %q, %q", id, email)
+					}
+					if test.verifyEmailErr != nil {
+						return nil, test.verifyEmailErr
+					}
+					return &user.User{ID: id, Email: email, EmailVerifiedAt: test.now}, nil
+				},
+			}, nil, testConfig)
+			service.now = func() time.Time { return test.now }
+
+			verifiedUser, err := service.Verify(context.Background(), test.value)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, test.expectedErr)
+			}
+			if test.expectedErr == nil && !verifiedUser.EmailVerified() {
+				t.Errorf("expected a verified user, got %+v", verifiedUser)
+			}
+		})
+	}
+}
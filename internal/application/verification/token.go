@@ -0,0 +1,76 @@
+package verification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidToken is returned for tokens that were not signed with the secret
+	ErrInvalidToken = errors.New("verification token is invalid")
+	// ErrTokenExpired is returned for signed tokens used after they expired
+	ErrTokenExpired = errors.New("verification token expired")
+)
+
+// Token identifies the email of a user a verification was sent to, until it expires
+type Token struct {
+	UserID    string
+	Email     string
+	ExpiresAt time.Time
+}
+
+// claims are the signed content of a token
+type claims struct {
+	Subject string `json:"sub"`
+	Email   string `json:"email"`
+	Expiry  int64  `json:"exp"`
+}
+
+// Sign returns the value of a token: its base64url JSON claims, a dot and the base64url HMAC-SHA256 of the claims keyed
+// by the secret
+func Sign(secret []byte, token Token) (string, error) {
+	payload, err := json.Marshal(claims{Subject: token.UserID, Email: token.Email, Expiry: token.ExpiresAt.Unix()})
+	if err != nil {
+		return "", fmt.Errorf("verification: failed to encode token: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(mac(secret, encoded)), nil
+}
+
+// Parse checks the signature of a token value and returns its token.
+// It returns ErrInvalidToken when the value was not signed with the secret and ErrTokenExpired once now is past its expiry.
+func Parse(secret []byte, value string, now time.Time) (Token, error) {
+	encoded, signature, ok := strings.Cut(value, ".")
+	if !ok {
+		return Token{}, ErrInvalidToken
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, mac(secret, encoded)) {
+		return Token{}, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Token{}, ErrInvalidToken
+	}
+	var tokenClaims claims
+	if err := json.Unmarshal(payload, &tokenClaims); err != nil || tokenClaims.Subject == "" {
+		return Token{}, ErrInvalidToken
+	}
+	token := Token{UserID: tokenClaims.Subject, Email: tokenClaims.Email, ExpiresAt: time.Unix(tokenClaims.Expiry, 0).UTC()}
+	if !now.Before(token.ExpiresAt) {
+		return Token{}, ErrTokenExpired
+	}
+	return token, nil
+}
+
+func mac(secret []byte, encoded string) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(encoded))
+	return h.Sum(nil)
+}
@@ -0,0 +1,46 @@
+package verification
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignAndParse(t *testing.T) {
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	token := Token{UserID: "1", Email: "jane@example.com", ExpiresAt: now.Add(time.Hour)}
+	value, err := Sign(secret, token)
+	if err != nil {
+		t.Fatalf("Sign() unexpected error: %v", err)
+	}
+	encoded, signature, _ := strings.Cut(value, ".")
+
+	tests := []struct {
+		name        string
+		secret      []byte
+		value       string
+		now         time.Time
+		expectedErr error
+	}{
+		{name: "valid token", secret: secret, value: value, now: now},
+		{name: "other secret", secret: []byte("another secret of 32 characters!"), value: value, now: now, expectedErr: ErrInvalidToken},
+		{name: "changed claims", secret: secret, value: encoded + "x." + signature, now: now, expectedErr: ErrInvalidToken},
+		{name: "no signature", secret: secret, value: encoded, now: now, expectedErr: ErrInvalidToken},
+		{name: "empty", secret: secret, now: now, expectedErr: ErrInvalidToken},
+		{name: "expired", secret: secret, value: value, now: now.Add(time.Hour), expectedErr: ErrTokenExpired},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsed, err := Parse(test.secret, test.value, test.now)
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, test.expectedErr)
+			}
+			if test.expectedErr == nil && parsed != token {
+				t.Errorf("Parse() = %+v, want %+v", parsed, token)
+			}
+		})
+	}
+}
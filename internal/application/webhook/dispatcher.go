@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+// maxResponseBytes is the most of a response body read before the connection is reused
+const maxResponseBytes = 4 << 10
+
+// errDestinationNotAllowed is returned when connecting to an address refused by webhook.AllowedAddress
+var errDestinationNotAllowed = errors.New("webhook: destination address not allowed")
+
+// DispatcherConfig configures how deliveries are attempted and retried
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// Timeout bounds a single attempt
+	Timeout time.Duration
+	// MaxAttempts is the number of attempts after which a delivery is dead
+	MaxAttempts int
+	// InitialBackoff is the delay after the first failed attempt, it doubles after every further failure up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+type dispatcher struct {
+	subscriptionRepository webhook.SubscriptionRepository
+	deliveryRepository     webhook.DeliveryRepository
+	client                 *http.Client
+	config                 DispatcherConfig
+	now                    func() time.Time
+}
+
+// NewDispatcher creates a dispatcher sending the due deliveries.
+// A client without redirects or proxies, with the configured timeout and refusing to connect to the addresses
+// refused by webhook.AllowedAddress is used when client is nil.
+func NewDispatcher(subscriptionRepository webhook.SubscriptionRepository, deliveryRepository webhook.DeliveryRepository,
+	client *http.Client, config DispatcherConfig) *dispatcher {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 30 * time.Second
+	}
+	if config.MaxBackoff < config.InitialBackoff {
+		config.MaxBackoff = max(time.Hour, config.InitialBackoff)
+	}
+	if client == nil {
+		client = newClient(config.Timeout)
+	}
+	return &dispatcher{
+		subscriptionRepository: subscriptionRepository,
+		deliveryRepository:     deliveryRepository,
+		client:                 client,
+		config:                 config,
+		now:                    time.Now,
+	}
+}
+
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		// the resolved address is checked, so a host name cannot rebind to a private address after validation
+		Control: func(network string, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !webhook.AllowedAddress(addrPort.Addr()) {
+				return errDestinationNotAllowed
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would connect to the endpoint without the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Run dispatches the due deliveries every poll interval until ctx is done
+func (d *dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := d.DispatchDue(ctx); err != nil && ctx.Err() == nil {
+			log.Printf(`{"level":"error","msg":"webhook dispatch failed","error":%q}`, err.Error())
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue attempts every due delivery once and returns the number of attempts made
+func (d *dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		deliveries, err := d.deliveryRepository.Due(ctx, d.now().UTC(), d.config.BatchSize)
+		if err != nil {
+			return attempted, fmt.Errorf("webhook: failed to list due deliveries: %w", err)
+		}
+		for _, delivery := range deliveries {
+			if err := d.dispatch(ctx, delivery); err != nil {
+				return attempted, err
+			}
+			attempted++
+		}
+		if len(deliveries) < d.config.BatchSize {
+			return attempted, nil
+		}
+	}
+}
+
+// dispatch attempts a delivery and saves its outcome
+func (d *dispatcher) dispatch(ctx context.Context, delivery *webhook.Delivery) error {
+	subscription, err := d.subscriptionRepository.FindByID(ctx, delivery.SubscriptionID)
+	if err != nil && !errors.Is(err, webhook.ErrNotFound) {
+		return fmt.Errorf("webhook: failed to find subscription %q: %w", delivery.SubscriptionID, err)
+	}
+	if subscription == nil {
+		delivery.Attempts = append(delivery.Attempts, webhook.Attempt{At: d.now().UTC(), Error: "subscription deleted"})
+		delivery.Status = webhook.DeliveryDead
+	} else {
+		attempt := d.send(ctx, subscription, delivery)
+		delivery.Attempts = append(delivery.Attempts, attempt)
+		switch {
+		case attempt.Error == "":
+			delivery.Status = webhook.DeliverySucceeded
+		case len(delivery.Attempts) >= d.config.MaxAttempts:
+			delivery.Status = webhook.DeliveryDead
+		default:
+			delivery.NextAttemptAt = attempt.At.Add(d.backoff(len(delivery.Attempts)))
+		}
+	}
+
+	if err := d.deliveryRepository.Save(ctx, delivery); err != nil {
+		return fmt.Errorf("webhook: failed to save delivery %q: %w", delivery.ID, err)
+	}
+	return nil
+}
+
+// send posts the signed payload, the attempt has an error unless the endpoint answered with a 2xx status
+func (d *dispatcher) send(ctx context.Context, subscription *webhook.Subscription, delivery *webhook.Delivery) webhook.Attempt {
+	attempt := webhook.Attempt{At: d.now().UTC()}
+
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = "invalid url"
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, attempt.At, delivery.Payload))
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(EventHeader, string(delivery.EventType))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		attempt.Error = attemptError(err)
+		return attempt
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBytes))
+
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// attemptError is the reason a request failed as recorded in the delivery log.
+// It is kept coarse, the error itself can describe the network behind the endpoint.
+func attemptError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, errDestinationNotAllowed):
+		return "destination address not allowed"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return "connection failed"
+	}
+}
+
+// backoff returns the delay before the next attempt after the given number of failed attempts
+func (d *dispatcher) backoff(failedAttempts int) time.Duration {
+	delay := d.config.InitialBackoff
+	for i := 1; i < failedAttempts && delay < d.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.config.MaxBackoff)
+}
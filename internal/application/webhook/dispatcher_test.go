@@ -0,0 +1,346 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+type mockSubscriptionRepository struct {
+	FindByIDFunc func(ctx context.Context, id string) (*webhook.Subscription, error)
+	SaveFunc     func(ctx context.Context, subscription *webhook.Subscription) error
+	DeleteFunc   func(ctx context.Context, id string) error
+	ListFunc     func(ctx context.Context) ([]*webhook.Subscription, error)
+}
+
+func (m *mockSubscriptionRepository) FindByID(ctx context.Context, id string) (*webhook.Subscription, error) {
+	return m.FindByIDFunc(ctx, id)
+}
+func (m *mockSubscriptionRepository) Save(ctx context.Context, subscription *webhook.Subscription) error {
+	return m.SaveFunc(ctx, subscription)
+}
+func (m *mockSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	return m.DeleteFunc(ctx, id)
+}
+func (m *mockSubscriptionRepository) List(ctx context.Context) ([]*webhook.Subscription, error) {
+	return m.ListFunc(ctx)
+}
+
+// newSliceSubscriptionRepository creates a mock subscription repository over subscriptions
+func newSliceSubscriptionRepository(subscriptions ...*webhook.Subscription) *mockSubscriptionRepository {
+	return &mockSubscriptionRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*webhook.Subscription, error) {
+			for _, subscription := range subscriptions {
+				if subscription.ID == id {
+					return subscription, nil
+				}
+			}
+			return nil, webhook.ErrNotFound
+		},
+		ListFunc: func(ctx context.Context) ([]*webhook.Subscription, error) {
+			return subscriptions, nil
+		},
+	}
+}
+
+type mockDeliveryRepository struct {
+	AddFunc                  func(ctx context.Context, delivery *webhook.Delivery) error
+	SaveFunc                 func(ctx context.Context, delivery *webhook.Delivery) error
+	DueFunc                  func(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error)
+	ListBySubscriptionIDFunc func(ctx context.Context, subscriptionID string, limit int) ([]*webhook.Delivery, error)
+}
+
+func (m *mockDeliveryRepository) Add(ctx context.Context, delivery *webhook.Delivery) error {
+	return m.AddFunc(ctx, delivery)
+}
+func (m *mockDeliveryRepository) Save(ctx context.Context, delivery *webhook.Delivery) error {
+	return m.SaveFunc(ctx, delivery)
+}
+func (m *mockDeliveryRepository) Due(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	return m.DueFunc(ctx, now, limit)
+}
+func (m *mockDeliveryRepository) ListBySubscriptionID(ctx context.Context, subscriptionID string, limit int) ([]*webhook.Delivery, error) {
+	return m.ListBySubscriptionIDFunc(ctx, subscriptionID, limit)
+}
+
+// newMapDeliveryRepository creates a mock delivery repository storing copies of the deliveries in a map
+func newMapDeliveryRepository(deliveries map[string]webhook.Delivery) *mockDeliveryRepository {
+	return &mockDeliveryRepository{
+		AddFunc: func(ctx context.Context, delivery *webhook.Delivery) error {
+			if _, ok := deliveries[delivery.ID]; !ok {
+				deliveries[delivery.ID] = *delivery
+			}
+			return nil
+		},
+		SaveFunc: func(ctx context.Context, delivery *webhook.Delivery) error {
+			deliveries[delivery.ID] = *delivery
+			return nil
+		},
+		DueFunc: func(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+			var due []*webhook.Delivery
+			for _, delivery := range deliveries {
+				if delivery.Status == webhook.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+					delivery.Attempts = append([]webhook.Attempt(nil), delivery.Attempts...)
+					due = append(due, &delivery)
+				}
+			}
+			sort.Slice(due, func(i, j int) bool { return due[i].ID < due[j].ID })
+			return due[:min(len(due), limit)], nil
+		},
+	}
+}
+
+func TestDispatcher_DispatchDue(t *testing.T) {
+	const secret = "0123456789abcdef"
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	config := DispatcherConfig{BatchSize: 10, MaxAttempts: 3, InitialBackoff: time.Minute, MaxBackoff: 90 * time.Second}
+
+	tests := []struct {
+		name             string
+		statusCodes      []int
+		deleted          bool
+		expectedStatus   webhook.DeliveryStatus
+		expectedAttempts int
+		expectedNext     time.Time
+	}{
+		{
+			name:             "delivered on the first attempt",
+			statusCodes:      []int{http.StatusNoContent},
+			expectedStatus:   webhook.DeliverySucceeded,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "retried with backoff until delivered",
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusOK},
+			expectedStatus:   webhook.DeliverySucceeded,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "dead after the last attempt",
+			statusCodes:      []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusBadRequest},
+			expectedStatus:   webhook.DeliveryDead,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "pending after a failure",
+			statusCodes:      []int{http.StatusInternalServerError},
+			expectedStatus:   webhook.DeliveryPending,
+			expectedAttempts: 1,
+			expectedNext:     start.Add(time.Minute),
+		},
+		{
+			name:             "dead when the subscription is deleted",
+			deleted:          true,
+			expectedStatus:   webhook.DeliveryDead,
+			expectedAttempts: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			now := start
+			received := 0
+			receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if err := Verify(secret, r.Header.Get(SignatureHeader), body, now, time.Minute); err != nil {
+					t.Errorf("receiver failed to verify the signature: %v", err)
+				}
+				if r.Header.Get(DeliveryHeader) != "s1-e1" || r.Header.Get(EventHeader) != string(user.EventUserCreated) {
+					t.Errorf("unexpected delivery headers %v", r.Header)
+				}
+				w.WriteHeader(test.statusCodes[received])
+				received++
+			}))
+			defer receiver.Close()
+
+			subscriptions := newSliceSubscriptionRepository()
+			if !test.deleted {
+				subscriptions = newSliceSubscriptionRepository(&webhook.Subscription{ID: "s1", URL: receiver.URL, Secret: secret})
+			}
+			deliveries := map[string]webhook.Delivery{"s1-e1": {
+				ID: "s1-e1", SubscriptionID: "s1", EventID: "e1", EventType: user.EventUserCreated,
+				Payload: []byte(`{"id":"e1"}`), Status: webhook.DeliveryPending, NextAttemptAt: start,
+			}}
+			// the receiver listens on a loopback address the default client refuses
+			dispatcher := NewDispatcher(subscriptions, newMapDeliveryRepository(deliveries), receiver.Client(), config)
+			dispatcher.now = func() time.Time { return now }
+
+			// advance the clock to the next attempt until the delivery is no longer pending or the receiver is out of answers
+			for i := 0; i < 5; i++ {
+				if _, err := dispatcher.DispatchDue(context.Background()); err != nil {
+					t.Fatalf("DispatchDue() error = %v", err)
+				}
+				delivery := deliveries["s1-e1"]
+				if delivery.Status != webhook.DeliveryPending || received == len(test.statusCodes) {
+					break
+				}
+				now = delivery.NextAttemptAt
+			}
+
+			delivery := deliveries["s1-e1"]
+			if delivery.Status != test.expectedStatus {
+				t.Errorf("expected status %s, got %s", test.expectedStatus, delivery.Status)
+			}
+			if len(delivery.Attempts) != test.expectedAttempts {
+				t.Errorf("expected %d attempts, got %+v", test.expectedAttempts, delivery.Attempts)
+			}
+			if !test.expectedNext.IsZero() && !delivery.NextAttemptAt.Equal(test.expectedNext) {
+				t.Errorf("expected next attempt at %v, got %v", test.expectedNext, delivery.NextAttemptAt)
+			}
+		})
+	}
+}
+
+func TestDispatcher_DestinationNotAllowed(t *testing.T) {
+	received := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+
+	// the subscription was validated with a public host name that now resolves to a loopback address
+	subscriptions := newSliceSubscriptionRepository(&webhook.Subscription{ID: "s1", URL: receiver.URL, Secret: "0123456789abcdef"})
+	deliveries := map[string]webhook.Delivery{"s1-e1": {
+		ID: "s1-e1", SubscriptionID: "s1", EventID: "e1", EventType: user.EventUserCreated,
+		Payload: []byte(`{"id":"e1"}`), Status: webhook.DeliveryPending,
+	}}
+	dispatcher := NewDispatcher(subscriptions, newMapDeliveryRepository(deliveries), nil, DispatcherConfig{})
+
+	if _, err := dispatcher.DispatchDue(context.Background()); err != nil {
+		t.Fatalf("DispatchDue() error = %v", err)
+	}
+
+	attempts := deliveries["s1-e1"].Attempts
+	if received || len(attempts) != 1 || attempts[0].Error != "destination address not allowed" {
+		t.Errorf("expected the delivery to be refused before connecting, got %+v", attempts)
+	}
+}
+
+func TestSubscription_Validate_URL(t *testing.T) {
+	tests := []struct {
+		url          string
+		expectedCode string
+	}{
+		{url: "https://partner.example.com/hooks"},
+		{url: "https://93.184.215.14/hooks"},
+		{url: "ftp://partner.example.com/hooks", expectedCode: webhook.ErrorURLInvalid},
+		{url: "http://localhost:8080/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://127.0.0.1/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://10.1.2.3/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://192.168.0.1/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://169.254.169.254/latest/meta-data", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://[::1]/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://[::ffff:10.0.0.1]/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://0.0.0.0/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://0.1.2.3/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://100.64.0.1/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://100.127.255.254/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://198.18.0.1/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://198.19.255.254/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "http://[::ffff:100.64.0.1]/hooks", expectedCode: webhook.ErrorURLPrivate},
+		{url: "https://100.128.0.1/hooks"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			subscription := &webhook.Subscription{URL: test.url, Secret: "0123456789abcdef"}
+
+			err := subscription.Validate()
+
+			var code string
+			if validationErrors := shared.ValidationErrors(err); len(validationErrors) > 0 {
+				code = validationErrors[0].Code
+			}
+			if code != test.expectedCode {
+				t.Errorf("Validate() = %v, want %q", err, test.expectedCode)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Backoff(t *testing.T) {
+	dispatcher := NewDispatcher(nil, nil, nil, DispatcherConfig{InitialBackoff: time.Minute, MaxBackoff: 5 * time.Minute})
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, delay := range expected {
+		if got := dispatcher.backoff(i + 1); got != delay {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, delay)
+		}
+	}
+}
+
+func TestService_HandleEvent(t *testing.T) {
+	subscriptions := newSliceSubscriptionRepository(
+		&webhook.Subscription{ID: "all"},
+		&webhook.Subscription{ID: "created", Events: []user.EventType{user.EventUserCreated}},
+		&webhook.Subscription{ID: "deleted", Events: []user.EventType{user.EventUserDeleted}},
+	)
+	deliveries := map[string]webhook.Delivery{}
+	service := NewService(subscriptions, newMapDeliveryRepository(deliveries))
+	event := &user.Event{ID: "e1", Type: user.EventUserCreated, UserID: "1", User: user.User{ID: "1", FirstName: "John"}}
+
+	// the relay delivers at least once, handling the event twice must not queue it twice
+	for i := 0; i < 2; i++ {
+		if err := service.HandleEvent(context.Background(), event); err != nil {
+			t.Fatalf("HandleEvent() error = %v", err)
+		}
+	}
+
+	if len(deliveries) != 2 {
+		t.Fatalf("expected deliveries to the matching subscriptions, got %v", deliveries)
+	}
+	for _, id := range []string{"all-e1", "created-e1"} {
+		if delivery, ok := deliveries[id]; !ok || delivery.Status != webhook.DeliveryPending || len(delivery.Payload) == 0 {
+			t.Errorf("expected pending delivery %s, got %+v", id, delivery)
+		}
+	}
+}
+
+func TestService_Deliveries_UnknownSubscription(t *testing.T) {
+	service := NewService(newSliceSubscriptionRepository(), newMapDeliveryRepository(map[string]webhook.Delivery{}))
+
+	_, err := service.Deliveries(context.Background(), "missing")
+
+	if !errors.Is(err, webhook.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	const secret = "0123456789abcdef"
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"e1"}`)
+	header := Sign(secret, now, body)
+
+	tests := []struct {
+		name      string
+		secret    string
+		header    string
+		body      []byte
+		now       time.Time
+		expectErr bool
+	}{
+		{name: "valid signature", secret: secret, header: header, body: body, now: now},
+		{name: "wrong secret", secret: "fedcba9876543210", header: header, body: body, now: now, expectErr: true},
+		{name: "tampered body", secret: secret, header: header, body: []byte(`{"id":"e2"}`), now: now, expectErr: true},
+		{name: "replayed too late", secret: secret, header: header, body: body, now: now.Add(10 * time.Minute), expectErr: true},
+		{name: "malformed header", secret: secret, header: "v1=abc", body: body, now: now, expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Verify(test.secret, test.header, test.body, test.now, 5*time.Minute)
+			if (err != nil) != test.expectErr {
+				t.Errorf("Verify() error = %v, expectErr %v", err, test.expectErr)
+			}
+		})
+	}
+}
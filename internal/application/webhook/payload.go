@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// eventPayload is the JSON body of a delivery
+type eventPayload struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       eventData `json:"data"`
+}
+
+type eventData struct {
+	User    userPayload     `json:"user"`
+	Changes []changePayload `json:"changes,omitempty"`
+}
+
+type userPayload struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	// DateOfBirth is empty for legacy users saved with an age only
+	DateOfBirth string `json:"date_of_birth,omitempty"`
+	// Age is the age on the day the event occurred
+	Age      int             `json:"age"`
+	Phone    string          `json:"phone,omitempty"`
+	Address  *addressPayload `json:"address,omitempty"`
+	Locale   string          `json:"locale,omitempty"`
+	TimeZone string          `json:"time_zone,omitempty"`
+	Status   string          `json:"status,omitempty"`
+	// EmailVerifiedAt is empty while the email is not verified
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	CreatedAt       *time.Time `json:"created_at,omitempty"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+type addressPayload struct {
+	Line1      string `json:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city,omitempty"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty"`
+}
+
+type changePayload struct {
+	Field  string `json:"field"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+func newEventPayload(event *user.Event) eventPayload {
+	payload := eventPayload{
+		ID:         event.ID,
+		Type:       string(event.Type),
+		OccurredAt: event.OccurredAt,
+		Data: eventData{User: userPayload{
+			ID:          event.User.ID,
+			FirstName:   event.User.FirstName,
+			LastName:    event.User.LastName,
+			Email:       event.User.Email,
+			DateOfBirth: user.FormatDateOfBirth(event.User.DateOfBirth),
+			Age:         event.User.AgeAt(event.OccurredAt),
+			Phone:       event.User.Phone,
+			Locale:      event.User.Locale,
+			TimeZone:    event.User.TimeZone,
+			Status:      string(event.User.Status),
+		}},
+	}
+	if address := event.User.Address; !address.IsZero() {
+		payload.Data.User.Address = &addressPayload{
+			Line1:      address.Line1,
+			Line2:      address.Line2,
+			City:       address.City,
+			Region:     address.Region,
+			PostalCode: address.PostalCode,
+			Country:    address.Country,
+		}
+	}
+	if verifiedAt := event.User.EmailVerifiedAt; !verifiedAt.IsZero() {
+		payload.Data.User.EmailVerifiedAt = &verifiedAt
+	}
+	if createdAt := event.User.CreatedAt; !createdAt.IsZero() {
+		payload.Data.User.CreatedAt = &createdAt
+	}
+	if updatedAt := event.User.UpdatedAt; !updatedAt.IsZero() {
+		payload.Data.User.UpdatedAt = &updatedAt
+	}
+	for _, change := range event.Changes {
+		payload.Data.Changes = append(payload.Data.Changes, changePayload{Field: change.Field, Before: change.Before, After: change.After})
+	}
+	return payload
+}
@@ -0,0 +1,138 @@
+// Package webhook contains the logic for the Webhook application service.
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+// deliveryLogLimit is the number of most recent deliveries returned per subscription
+const deliveryLogLimit = 100
+
+type service struct {
+	subscriptionRepository webhook.SubscriptionRepository
+	deliveryRepository     webhook.DeliveryRepository
+	now                    func() time.Time
+}
+
+// NewService creates a new webhook service
+func NewService(subscriptionRepository webhook.SubscriptionRepository, deliveryRepository webhook.DeliveryRepository) *service {
+	return &service{
+		subscriptionRepository: subscriptionRepository,
+		deliveryRepository:     deliveryRepository,
+		now:                    time.Now,
+	}
+}
+
+// Subscribe creates a subscription, generating its secret when none is given
+func (s *service) Subscribe(ctx context.Context, subscription *webhook.Subscription) (*webhook.Subscription, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to generate subscription ID: %w", err)
+	}
+	subscription.ID = id
+	subscription.CreatedAt = s.now().UTC()
+	if subscription.Secret == "" {
+		if subscription.Secret, err = newID(); err != nil {
+			return nil, fmt.Errorf("webhook: failed to generate secret: %w", err)
+		}
+	}
+	if err := subscription.Validate(); err != nil {
+		return nil, fmt.Errorf("webhook: failed to validate subscription: %w", err)
+	}
+
+	if err := s.subscriptionRepository.Save(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("webhook: failed to save subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// Find finds a subscription by id
+func (s *service) Find(ctx context.Context, id string) (*webhook.Subscription, error) {
+	subscription, err := s.subscriptionRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to find subscription %q: %w", id, err)
+	}
+	return subscription, nil
+}
+
+// List lists the subscriptions
+func (s *service) List(ctx context.Context) ([]*webhook.Subscription, error) {
+	subscriptions, err := s.subscriptionRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to list subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// Unsubscribe deletes a subscription, its pending deliveries are dropped when they are next attempted
+func (s *service) Unsubscribe(ctx context.Context, id string) error {
+	if err := s.subscriptionRepository.Delete(ctx, id); err != nil {
+		return fmt.Errorf("webhook: failed to delete subscription %q: %w", id, err)
+	}
+	return nil
+}
+
+// Deliveries returns the most recent deliveries of a subscription, newest first
+func (s *service) Deliveries(ctx context.Context, subscriptionID string) ([]*webhook.Delivery, error) {
+	if _, err := s.Find(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.deliveryRepository.ListBySubscriptionID(ctx, subscriptionID, deliveryLogLimit)
+	if err != nil {
+		return nil, fmt.Errorf("webhook: failed to list deliveries of subscription %q: %w", subscriptionID, err)
+	}
+	return deliveries, nil
+}
+
+// HandleEvent queues a delivery of the event for every matching subscription.
+// It is subscribed to the user event bus, an event handled twice is only delivered once.
+func (s *service) HandleEvent(ctx context.Context, event *user.Event) error {
+	subscriptions, err := s.subscriptionRepository.List(ctx)
+	if err != nil {
+		return fmt.Errorf("webhook: failed to list subscriptions: %w", err)
+	}
+
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Matches(event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(newEventPayload(event)); err != nil {
+				return fmt.Errorf("webhook: failed to encode event %q: %w", event.ID, err)
+			}
+		}
+		now := s.now().UTC()
+		delivery := &webhook.Delivery{
+			ID:             webhook.DeliveryID(subscription.ID, event.ID),
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         webhook.DeliveryPending,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		if err := s.deliveryRepository.Add(ctx, delivery); err != nil {
+			return fmt.Errorf("webhook: failed to queue delivery %q: %w", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+// newID generates a random 128 bit hex encoded id
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
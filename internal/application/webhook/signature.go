@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader carries the timestamp and HMAC-SHA256 signature of a delivery as t=<unix>,v1=<hex>
+	SignatureHeader = "X-Webhook-Signature"
+	// DeliveryHeader carries the delivery id, it is the same for every attempt of a delivery
+	DeliveryHeader = "X-Webhook-Delivery"
+	// EventHeader carries the event type
+	EventHeader = "X-Webhook-Event"
+)
+
+// Sign returns the signature header value of a body sent at timestamp.
+// The signature is the hex HMAC-SHA256, keyed by the secret, of the unix timestamp, a dot and the body.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + hex.EncodeToString(mac(secret, unix, body))
+}
+
+// Verify checks a signature header value against the body, rejecting signatures older than tolerance
+func Verify(secret string, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix, signature string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signature = value
+		}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return fmt.Errorf("webhook: invalid signature timestamp %q", unix)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return errors.New("webhook: signature timestamp outside tolerance")
+	}
+	expected, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, mac(secret, unix, body)) {
+		return errors.New("webhook: signature mismatch")
+	}
+	return nil
+}
+
+func mac(secret string, unix string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(unix))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,370 @@
+// Package config loads the application configuration from the environment.
+package config
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// Config is the application configuration
+type Config struct {
+	Port     string
+	GRPCPort string
+	// AdminAddr is the address of the internal listener serving /debug/vars, empty disables it
+	AdminAddr       string
+	MongoURI        string
+	MongoCollection string
+	// MongoAllowStandalone accepts a standalone MongoDB, which has no transactions, so a failed change may be partly written
+	MongoAllowStandalone bool
+	// UserCacheControl is the Cache-Control header of user reads
+	UserCacheControl string
+	CORS             CORS
+	Cache            Cache
+	Validation       user.ValidationConfig
+	// Duplicates configures the detection of possible duplicates of saved users
+	Duplicates user.DuplicateConfig
+	Outbox     Outbox
+	Webhooks   Webhooks
+	Mail       Mail
+	// EmailVerification configures the tokens of the email verification links
+	EmailVerification EmailVerification
+
+	// RejectedValues includes the rejected values in validation problems
+	RejectedValues bool
+}
+
+// CORS is the configuration for cross-origin requests
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// Cache is the configuration of the user repository cache
+type Cache struct {
+	Enabled bool
+	// Size is the maximum number of cached users
+	Size int
+	TTL  time.Duration
+	// NegativeTTL is how long unknown user ids are cached, zero disables it
+	NegativeTTL time.Duration
+}
+
+// Outbox is the configuration of the relay delivering user events
+type Outbox struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// LogEvents publishes the events to the log
+	LogEvents bool
+}
+
+// Webhooks is the configuration of the webhook dispatcher
+type Webhooks struct {
+	PollInterval time.Duration
+	// Timeout bounds a single delivery attempt
+	Timeout     time.Duration
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, it doubles up to MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Mailers are the names of the mailers sending the emails
+const (
+	MailerSMTP   = "smtp"
+	MailerFile   = "file"
+	MailerMemory = "memory"
+)
+
+// Mail is the configuration of the mailer sending the emails of the application
+type Mail struct {
+	// Mailer is MailerSMTP, MailerFile or MailerMemory
+	Mailer string
+	From   string
+	// Dir is the directory MailerFile writes the messages to
+	Dir          string
+	SMTPAddr     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// EmailVerification is the configuration of the email verification links
+type EmailVerification struct {
+	// Secret signs the tokens, tokens do not survive a restart when it is empty as a random one is used
+	Secret string
+	TTL    time.Duration
+	// URL is the verify endpoint the emails link to
+	URL string
+}
+
+// minVerificationSecretLength is the shortest accepted verification secret, as long as the HMAC-SHA256 key it makes
+const minVerificationSecretLength = 32
+
+// Load loads the configuration from environment variables, applying defaults for unset values
+func Load() (Config, error) {
+	var err error
+	cfg := Config{
+		Port:            getEnv("PORT", "8080"),
+		GRPCPort:        getEnv("GRPC_PORT", "9090"),
+		AdminAddr:       getEnv("ADMIN_ADDR", "localhost:8081"),
+		MongoURI:        os.Getenv("MONGO_URI"),
+		MongoCollection: getEnv("MONGO_COLLECTION", "user"),
+		// users hold personal data, shared caches must not store them unless configured to
+		UserCacheControl: getEnv("USER_CACHE_CONTROL", "private, no-cache"),
+		CORS: CORS{
+			AllowedOrigins: getEnvList("CORS_ALLOWED_ORIGINS", nil),
+			AllowedMethods: getEnvList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE"}),
+			AllowedHeaders: getEnvList("CORS_ALLOWED_HEADERS", []string{"Accept", "Content-Type", "Authorization", "If-Match", "If-None-Match", "If-Modified-Since"}),
+			ExposedHeaders: getEnvList("CORS_EXPOSED_HEADERS", []string{"ETag"}),
+		},
+	}
+
+	if cfg.MongoAllowStandalone, err = getEnvBool("MONGO_ALLOW_STANDALONE", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.CORS.AllowCredentials, err = getEnvBool("CORS_ALLOW_CREDENTIALS", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.CORS.MaxAge, err = getEnvDuration("CORS_MAX_AGE", 10*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.RejectedValues, err = getEnvBool("API_REJECTED_VALUES", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.Validation, err = loadValidation(); err != nil {
+		return Config{}, err
+	}
+	if cfg.Duplicates, err = loadDuplicates(); err != nil {
+		return Config{}, err
+	}
+	if cfg.Cache.Enabled, err = getEnvBool("CACHE_ENABLED", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.Cache.Size, err = getEnvInt("CACHE_SIZE", 10000); err != nil {
+		return Config{}, err
+	}
+	if cfg.Cache.TTL, err = getEnvDuration("CACHE_TTL", time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.Cache.NegativeTTL, err = getEnvDuration("CACHE_NEGATIVE_TTL", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.Outbox.PollInterval, err = getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.Outbox.BatchSize, err = getEnvInt("OUTBOX_BATCH_SIZE", 100); err != nil {
+		return Config{}, err
+	}
+	if cfg.Outbox.LogEvents, err = getEnvBool("OUTBOX_LOG_EVENTS", true); err != nil {
+		return Config{}, err
+	}
+	if cfg.Webhooks.PollInterval, err = getEnvDuration("WEBHOOK_POLL_INTERVAL", time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.Webhooks.Timeout, err = getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.Webhooks.MaxAttempts, err = getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8); err != nil {
+		return Config{}, err
+	}
+	if cfg.Webhooks.InitialBackoff, err = getEnvDuration("WEBHOOK_INITIAL_BACKOFF", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.Webhooks.MaxBackoff, err = getEnvDuration("WEBHOOK_MAX_BACKOFF", time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.Mail, err = loadMail(); err != nil {
+		return Config{}, err
+	}
+	if cfg.EmailVerification, err = loadEmailVerification(cfg.Port); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// loadMail loads the mailer configuration, rejecting unknown mailers and SMTP without a server
+func loadMail() (Mail, error) {
+	mail := Mail{
+		Mailer:       getEnv("MAILER", MailerFile),
+		From:         getEnv("MAIL_FROM", "no-reply@localhost"),
+		Dir:          getEnv("MAIL_DIR", "mail"),
+		SMTPAddr:     os.Getenv("SMTP_ADDR"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+	}
+	switch mail.Mailer {
+	case MailerSMTP:
+		if mail.SMTPAddr == "" {
+			return Mail{}, fmt.Errorf("config: SMTP_ADDR is required by the %s mailer", MailerSMTP)
+		}
+	case MailerFile, MailerMemory:
+	default:
+		return Mail{}, fmt.Errorf("config: unknown MAILER %q, use %s, %s or %s", mail.Mailer, MailerSMTP, MailerFile, MailerMemory)
+	}
+	return mail, nil
+}
+
+// loadEmailVerification loads the email verification configuration, the links default to the verify endpoint on port
+func loadEmailVerification(port string) (EmailVerification, error) {
+	var err error
+	verification := EmailVerification{
+		Secret: os.Getenv("EMAIL_VERIFICATION_SECRET"),
+		URL:    getEnv("EMAIL_VERIFICATION_URL", "http://localhost:"+port+"/verify"),
+	}
+	if verification.Secret != "" && len(verification.Secret) < minVerificationSecretLength {
+		return EmailVerification{}, fmt.Errorf("config: EMAIL_VERIFICATION_SECRET must be at least %d characters", minVerificationSecretLength)
+	}
+	if verification.TTL, err = getEnvDuration("EMAIL_VERIFICATION_TTL", 24*time.Hour); err != nil {
+		return EmailVerification{}, err
+	}
+	return verification, nil
+}
+
+// loadValidation loads the validation rules and their parameters, defaulting to user.DefaultValidationConfig
+func loadValidation() (user.ValidationConfig, error) {
+	var err error
+	validation := user.DefaultValidationConfig()
+	validation.Rules = getEnvList("VALIDATION_RULES", validation.Rules)
+	validation.RequiredFields = getEnvList("VALIDATION_REQUIRED_FIELDS", validation.RequiredFields)
+	validation.EmailPattern = getEnv("VALIDATION_EMAIL_PATTERN", validation.EmailPattern)
+	if validation.GmailCanonicalization, err = getEnvBool("VALIDATION_GMAIL_CANONICALIZATION", validation.GmailCanonicalization); err != nil {
+		return user.ValidationConfig{}, err
+	}
+	if path := getEnv("VALIDATION_DISPOSABLE_DOMAINS_FILE", ""); path != "" {
+		if validation.DisposableDomains, err = readDomainList(path); err != nil {
+			return user.ValidationConfig{}, err
+		}
+		// the domains would have no effect without the rule
+		if !slices.Contains(validation.Rules, user.RuleDisposableEmail) {
+			validation.Rules = append(validation.Rules, user.RuleDisposableEmail)
+		}
+	}
+	if validation.MinAge, err = getEnvInt("VALIDATION_MIN_AGE", validation.MinAge); err != nil {
+		return user.ValidationConfig{}, err
+	}
+	if validation.MaxAge, err = getEnvInt("VALIDATION_MAX_AGE", validation.MaxAge); err != nil {
+		return user.ValidationConfig{}, err
+	}
+	if validation.NameMinLength, err = getEnvInt("VALIDATION_NAME_MIN_LENGTH", validation.NameMinLength); err != nil {
+		return user.ValidationConfig{}, err
+	}
+	if validation.NameMaxLength, err = getEnvInt("VALIDATION_NAME_MAX_LENGTH", validation.NameMaxLength); err != nil {
+		return user.ValidationConfig{}, err
+	}
+	// reject invalid rules at startup rather than on the first validation
+	if _, err := user.NewRules(validation); err != nil {
+		return user.ValidationConfig{}, fmt.Errorf("config: %w", err)
+	}
+	return validation, nil
+}
+
+// loadDuplicates loads the duplicate detection, defaulting to user.DefaultDuplicateConfig
+func loadDuplicates() (user.DuplicateConfig, error) {
+	var err error
+	duplicates := user.DefaultDuplicateConfig()
+	if duplicates.Mode, err = user.ParseDuplicateMode(getEnv("DUPLICATE_DETECTION", string(duplicates.Mode))); err != nil {
+		return user.DuplicateConfig{}, fmt.Errorf("config: invalid DUPLICATE_DETECTION: %w", err)
+	}
+	if duplicates.Threshold, err = getEnvFloat("DUPLICATE_THRESHOLD", duplicates.Threshold); err != nil {
+		return user.DuplicateConfig{}, err
+	}
+	if duplicates.Threshold < 0 || duplicates.Threshold > 1 {
+		return user.DuplicateConfig{}, fmt.Errorf("config: DUPLICATE_THRESHOLD must be between 0 and 1")
+	}
+	return duplicates, nil
+}
+
+// readDomainList reads one domain per line, ignoring blank lines and # comments
+func readDomainList(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config: failed to read domain list: %w", err)
+	}
+	var domains []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if line = strings.TrimSpace(line); line != "" {
+			domains = append(domains, line)
+		}
+	}
+	return domains, nil
+}
+
+func getEnv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvList reads a comma separated list, ignoring empty entries
+func getEnvList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("config: invalid boolean for %s: %w", key, err)
+	}
+	return b, nil
+}
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("config: invalid integer for %s: %w", key, err)
+	}
+	return i, nil
+}
+
+func getEnvFloat(key string, fallback float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("config: invalid number for %s: %w", key, err)
+	}
+	return f, nil
+}
+
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("config: invalid duration for %s: %w", key, err)
+	}
+	return d, nil
+}
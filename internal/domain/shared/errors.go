@@ -1,13 +1,67 @@
 // Package shared contains shared code for the domain layer
 package shared
 
+import "errors"
+
+// Severity is how serious a validation failure is
+type Severity string
+
+const (
+	// SeverityError rejects the entity
+	SeverityError Severity = "error"
+	// SeverityWarning reports a concern without rejecting the entity
+	SeverityWarning Severity = "warning"
+)
+
 // ValidationError is a validation error
 type ValidationError struct {
 	Code    string
 	Message string
+	// Field is the path of the rejected field, such as first_name, empty when the error concerns the whole entity
+	Field string
+	// Value is the rejected value, see Redacted
+	Value string
+	// Params are the parameters of the failed rule, such as min=18
+	Params   map[string]string
+	Severity Severity
 }
 
 // Error returns the error message
 func (e ValidationError) Error() string {
 	return e.Message
 }
+
+// Is reports whether target is a ValidationError with the same code and,
+// when target has a field, the same field, so errors.Is matches errors built by the same constructor
+func (e ValidationError) Is(target error) bool {
+	t, ok := target.(ValidationError)
+	return ok && t.Code == e.Code && (t.Field == "" || t.Field == e.Field)
+}
+
+// Redacted returns the error without its rejected value, for reporting where the value must not be disclosed
+func (e ValidationError) Redacted() ValidationError {
+	e.Value = ""
+	return e
+}
+
+// ValidationErrors returns every ValidationError in the error tree of err,
+// including errors aggregated with errors.Join
+func ValidationErrors(err error) []ValidationError {
+	var validationErrors []ValidationError
+	var walk func(err error)
+	walk = func(err error) {
+		switch e := err.(type) {
+		case nil:
+		case ValidationError:
+			validationErrors = append(validationErrors, e)
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				walk(err)
+			}
+		default:
+			walk(errors.Unwrap(err))
+		}
+	}
+	walk(err)
+	return validationErrors
+}
@@ -0,0 +1,9 @@
+package shared
+
+import "context"
+
+// Transactor runs a function in a storage transaction.
+// The function must use the context it is given so its reads and writes take part in the transaction.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
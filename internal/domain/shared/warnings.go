@@ -0,0 +1,37 @@
+package shared
+
+import (
+	"context"
+	"sync"
+)
+
+// warnings collects the warnings reported while handling a request
+type warnings struct {
+	mu       sync.Mutex
+	warnings []ValidationError
+}
+
+// WithWarnings returns a context collecting the warnings reported with AddWarnings, see WarningsFromContext
+func WithWarnings(ctx context.Context) context.Context {
+	return context.WithValue(ctx, warningsKey, &warnings{})
+}
+
+// AddWarnings reports warnings about the request, they are dropped when the context does not collect warnings
+func AddWarnings(ctx context.Context, reported ...ValidationError) {
+	if collected, ok := ctx.Value(warningsKey).(*warnings); ok {
+		collected.mu.Lock()
+		defer collected.mu.Unlock()
+		collected.warnings = append(collected.warnings, reported...)
+	}
+}
+
+// WarningsFromContext returns the warnings reported to the context in order, nil when it does not collect warnings
+func WarningsFromContext(ctx context.Context) []ValidationError {
+	collected, ok := ctx.Value(warningsKey).(*warnings)
+	if !ok {
+		return nil
+	}
+	collected.mu.Lock()
+	defer collected.mu.Unlock()
+	return append([]ValidationError(nil), collected.warnings...)
+}
@@ -0,0 +1,67 @@
+package user
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout is the layout of dates of birth in the API, bulk files and audit trail
+const DateLayout = time.DateOnly
+
+// ParseDateOfBirth parses a date of birth in DateLayout, an empty value is the zero time of an unknown date
+func ParseDateOfBirth(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("user: date of birth %q is not a %s date", value, DateLayout)
+	}
+	return date, nil
+}
+
+// FormatDateOfBirth formats a date of birth in DateLayout, the zero time of an unknown date is empty
+func FormatDateOfBirth(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format(DateLayout)
+}
+
+// AgeOn returns the age in whole years on the UTC day of now of someone born on dateOfBirth.
+// Someone born on February 29 turns a year older on March 1 in common years.
+func AgeOn(dateOfBirth time.Time, now time.Time) int {
+	now = now.UTC()
+	age := now.Year() - dateOfBirth.Year()
+	if now.YearDay() < birthdayOf(dateOfBirth, now.Year()) {
+		age--
+	}
+	return age
+}
+
+// LastBirthday returns the UTC midnight of the most recent birthday on or before the UTC day of now,
+// when the age AgeOn returns last changed
+func LastBirthday(dateOfBirth time.Time, now time.Time) time.Time {
+	now = now.UTC()
+	year := now.Year()
+	if now.YearDay() < birthdayOf(dateOfBirth, year) {
+		year--
+	}
+	// time.Date normalizes February 29 to March 1 in common years
+	return time.Date(year, dateOfBirth.Month(), dateOfBirth.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// birthdayOf returns the day of year of the birthday in year
+func birthdayOf(dateOfBirth time.Time, year int) int {
+	// time.Date normalizes February 29 to March 1 in common years
+	return time.Date(year, dateOfBirth.Month(), dateOfBirth.Day(), 0, 0, 0, 0, time.UTC).YearDay()
+}
+
+// AgeAt returns the user's age at now, computed from the date of birth
+// or, for legacy users without one, the age they were saved with
+func (u User) AgeAt(now time.Time) int {
+	if u.DateOfBirth.IsZero() {
+		return u.Age
+	}
+	return AgeOn(u.DateOfBirth, now)
+}
@@ -0,0 +1,104 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAgeOn(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name        string
+		dateOfBirth time.Time
+		now         time.Time
+		expectedAge int
+	}{
+		{name: "day before the birthday", dateOfBirth: date(2006, 6, 15), now: date(2024, 6, 14), expectedAge: 17},
+		{name: "on the birthday", dateOfBirth: date(2006, 6, 15), now: date(2024, 6, 15), expectedAge: 18},
+		{name: "after the birthday", dateOfBirth: date(2006, 6, 15), now: date(2024, 12, 31), expectedAge: 18},
+		{name: "late on the day before the birthday", dateOfBirth: date(2006, 6, 15), now: time.Date(2024, 6, 14, 23, 59, 59, 0, time.UTC), expectedAge: 17},
+		{name: "now is compared as a UTC day", dateOfBirth: date(2006, 6, 15), now: time.Date(2024, 6, 14, 23, 0, 0, 0, time.FixedZone("UTC-2", -2*60*60)), expectedAge: 18},
+		{name: "leap day birthday in a common year", dateOfBirth: date(2004, 2, 29), now: date(2022, 2, 28), expectedAge: 17},
+		{name: "leap day birthday on March 1 of a common year", dateOfBirth: date(2004, 2, 29), now: date(2022, 3, 1), expectedAge: 18},
+		{name: "leap day birthday in a leap year", dateOfBirth: date(2004, 2, 29), now: date(2024, 2, 29), expectedAge: 20},
+		{name: "March birthday in a leap year", dateOfBirth: date(2005, 3, 1), now: date(2024, 2, 29), expectedAge: 18},
+		{name: "born in the future", dateOfBirth: date(2025, 1, 1), now: date(2024, 6, 15), expectedAge: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if age := AgeOn(test.dateOfBirth, test.now); age != test.expectedAge {
+				t.Errorf("expected age %d, got %d", test.expectedAge, age)
+			}
+		})
+	}
+}
+
+func TestLastBirthday(t *testing.T) {
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name         string
+		dateOfBirth  time.Time
+		now          time.Time
+		expectedDate time.Time
+	}{
+		{name: "day before the birthday", dateOfBirth: date(2006, 6, 15), now: date(2024, 6, 14), expectedDate: date(2023, 6, 15)},
+		{name: "on the birthday", dateOfBirth: date(2006, 6, 15), now: time.Date(2024, 6, 15, 18, 0, 0, 0, time.UTC), expectedDate: date(2024, 6, 15)},
+		{name: "leap day birthday in a common year", dateOfBirth: date(2004, 2, 29), now: date(2022, 3, 2), expectedDate: date(2022, 3, 1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if date := LastBirthday(test.dateOfBirth, test.now); !date.Equal(test.expectedDate) {
+				t.Errorf("expected %v, got %v", test.expectedDate, date)
+			}
+		})
+	}
+}
+
+func TestUser_AgeAt(t *testing.T) {
+	now := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	withDateOfBirth := User{DateOfBirth: time.Date(2000, 6, 16, 0, 0, 0, 0, time.UTC), Age: 99}
+	if age := withDateOfBirth.AgeAt(now); age != 23 {
+		t.Errorf("expected the age computed from the date of birth 23, got %d", age)
+	}
+	legacy := User{Age: 30}
+	if age := legacy.AgeAt(now); age != 30 {
+		t.Errorf("expected the legacy age 30, got %d", age)
+	}
+}
+
+func TestParseDateOfBirth(t *testing.T) {
+	tests := []struct {
+		value       string
+		expected    time.Time
+		expectedErr bool
+	}{
+		{value: "", expected: time.Time{}},
+		{value: "2000-02-29", expected: time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{value: "2001-02-29", expectedErr: true},
+		{value: "2000-02-29T00:00:00Z", expectedErr: true},
+		{value: "29/02/2000", expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			date, err := ParseDateOfBirth(test.value)
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("expected error %v, got %v", test.expectedErr, err)
+			}
+			if !date.Equal(test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, date)
+			}
+			if err == nil && FormatDateOfBirth(date) != test.value {
+				t.Errorf("expected %q to format back, got %q", test.value, FormatDateOfBirth(date))
+			}
+		})
+	}
+}
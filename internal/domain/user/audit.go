@@ -0,0 +1,109 @@
+package user
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// AuditAction is the kind of change recorded in an audit record
+type AuditAction string
+
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+	// AuditActionMerge is recorded for the survivor of a merge and for each user merged into it
+	AuditActionMerge AuditAction = "merge"
+)
+
+// Field names used in audit diffs
+const (
+	FieldFirstName   = "first_name"
+	FieldLastName    = "last_name"
+	FieldEmail       = "email"
+	FieldAge         = "age"
+	FieldDateOfBirth = "date_of_birth"
+	FieldPhone       = "phone"
+	FieldAddress     = "address"
+	FieldLocale      = "locale"
+	FieldTimeZone    = "time_zone"
+	FieldStatus      = "status"
+	// FieldEmailVerifiedAt changes when the email is verified and when a changed email is no longer verified
+	FieldEmailVerifiedAt = "email_verified_at"
+)
+
+// AuditRecord records a single change made to a user
+type AuditRecord struct {
+	ID        string
+	UserID    string
+	Action    AuditAction
+	Actor     string
+	RequestID string
+	Timestamp time.Time
+	Changes   []FieldChange
+	// MergedIDs are the users merged into the user by a merge of the survivor
+	MergedIDs []string
+	// MergedInto is the survivor a merged user was merged into
+	MergedInto string
+}
+
+// FieldChange is the before and after value of a changed field.
+// Before is empty for created users and After is empty for deleted users.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// AuditRepository stores the audit trail of users
+type AuditRepository interface {
+	// Append adds a record to the audit trail
+	Append(ctx context.Context, record *AuditRecord) error
+	// ListByUserID lists the records of a user ordered by timestamp
+	ListByUserID(ctx context.Context, userID string) ([]*AuditRecord, error)
+}
+
+// auditField reads a field recorded in audit diffs
+type auditField struct {
+	name  string
+	value func(user *User) string
+}
+
+// auditFields are the fields recorded in audit diffs, in the order of the changes
+var auditFields = []auditField{
+	{name: FieldFirstName, value: func(user *User) string { return user.FirstName }},
+	{name: FieldLastName, value: func(user *User) string { return user.LastName }},
+	{name: FieldEmail, value: func(user *User) string { return user.Email }},
+	{name: FieldAge, value: func(user *User) string { return strconv.Itoa(user.Age) }},
+	{name: FieldDateOfBirth, value: func(user *User) string { return FormatDateOfBirth(user.DateOfBirth) }},
+	{name: FieldPhone, value: func(user *User) string { return user.Phone }},
+	{name: FieldAddress, value: func(user *User) string { return user.Address.String() }},
+	{name: FieldLocale, value: func(user *User) string { return user.Locale }},
+	{name: FieldTimeZone, value: func(user *User) string { return user.TimeZone }},
+	{name: FieldStatus, value: func(user *User) string { return string(user.Status) }},
+	{name: FieldEmailVerifiedAt, value: func(user *User) string {
+		if !user.EmailVerified() {
+			return ""
+		}
+		return user.EmailVerifiedAt.UTC().Format(time.RFC3339)
+	}},
+}
+
+// Diff returns the fields that differ between before and after, either may be nil
+func Diff(before *User, after *User) []FieldChange {
+	var changes []FieldChange
+	for _, field := range auditFields {
+		var beforeValue, afterValue string
+		if before != nil {
+			beforeValue = field.value(before)
+		}
+		if after != nil {
+			afterValue = field.value(after)
+		}
+		if beforeValue != afterValue {
+			changes = append(changes, FieldChange{Field: field.name, Before: beforeValue, After: afterValue})
+		}
+	}
+	return changes
+}
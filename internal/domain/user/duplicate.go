@@ -0,0 +1,225 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// DuplicateMode is what saving a user with possible duplicates does
+type DuplicateMode string
+
+const (
+	// DuplicateModeOff does not look for duplicates when saving
+	DuplicateModeOff DuplicateMode = "off"
+	// DuplicateModeAdvisory saves the user and reports its possible duplicates as warnings
+	DuplicateModeAdvisory DuplicateMode = "advisory"
+	// DuplicateModeBlocking rejects the user with a DuplicateError
+	DuplicateModeBlocking DuplicateMode = "blocking"
+)
+
+// ErrorPossibleDuplicate is the code of the warnings and import errors reporting a possible duplicate
+const ErrorPossibleDuplicate = "POSSIBLE_DUPLICATE"
+
+// ErrPossibleDuplicate is returned when saving a user that scores as a duplicate of another user in blocking mode
+var ErrPossibleDuplicate = errors.New("user is a possible duplicate of another user")
+
+// Weights of the date of birth in a duplicate score
+const (
+	dateOfBirthMatchBonus      = 0.05
+	dateOfBirthMismatchPenalty = 0.15
+)
+
+// DuplicateConfig configures duplicate detection
+type DuplicateConfig struct {
+	Mode DuplicateMode
+	// Threshold is the lowest score, between 0 and 1, of a reported duplicate
+	Threshold float64
+}
+
+// DefaultDuplicateConfig returns the duplicate detection used unless configured otherwise
+func DefaultDuplicateConfig() DuplicateConfig {
+	return DuplicateConfig{Mode: DuplicateModeAdvisory, Threshold: 0.9}
+}
+
+// ParseDuplicateMode parses the name of a duplicate mode
+func ParseDuplicateMode(name string) (DuplicateMode, error) {
+	switch mode := DuplicateMode(name); mode {
+	case DuplicateModeOff, DuplicateModeAdvisory, DuplicateModeBlocking:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown duplicate mode %q, must be one of %s, %s or %s", name, DuplicateModeOff, DuplicateModeAdvisory, DuplicateModeBlocking)
+}
+
+// DuplicateCandidate is an existing user scored as a duplicate of another user
+type DuplicateCandidate struct {
+	User *User
+	// Score is how likely, between 0 and 1, the users are the same person
+	Score float64
+	// NameSimilarity is the Jaro-Winkler similarity of the full names, ignoring case and the order of the names
+	NameSimilarity float64
+	// EmailMatch is set when both users have the same canonical email, an email match scores 1
+	EmailMatch bool
+	// DateOfBirthMatch is set when both users have the same date of birth
+	DateOfBirthMatch bool
+}
+
+// DuplicateError is returned when saving a user with possible duplicates in blocking mode, it wraps ErrPossibleDuplicate
+type DuplicateError struct {
+	Candidates []DuplicateCandidate
+}
+
+// Error returns the error message
+func (e *DuplicateError) Error() string {
+	ids := make([]string, 0, len(e.Candidates))
+	for _, candidate := range e.Candidates {
+		ids = append(ids, candidate.User.ID)
+	}
+	return fmt.Sprintf("%s: %s", ErrPossibleDuplicate, strings.Join(ids, ", "))
+}
+
+// Unwrap returns ErrPossibleDuplicate
+func (e *DuplicateError) Unwrap() error {
+	return ErrPossibleDuplicate
+}
+
+// NewPossibleDuplicateWarning creates a new warning reporting a possible duplicate, it concerns the whole user so it has no field
+func NewPossibleDuplicateWarning(candidate DuplicateCandidate) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorPossibleDuplicate,
+		Message:  fmt.Sprintf("User is a possible duplicate of user %s", candidate.User.ID),
+		Params:   map[string]string{"user_id": candidate.User.ID, "score": FormatScore(candidate.Score)},
+		Severity: shared.SeverityWarning,
+	}
+}
+
+// FormatScore formats a duplicate score with two decimals
+func FormatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 2, 64)
+}
+
+// ScoreDuplicate scores other as a duplicate of user.
+// The score is 1 for users with the same canonical email, otherwise it is their name similarity raised a little
+// by the same date of birth and lowered by different ones.
+func ScoreDuplicate(user User, other *User) DuplicateCandidate {
+	candidate := DuplicateCandidate{
+		User:           other,
+		NameSimilarity: nameSimilarity(user, *other),
+		EmailMatch:     user.CanonicalEmail != "" && user.CanonicalEmail == other.CanonicalEmail,
+	}
+	score := candidate.NameSimilarity
+	if !user.DateOfBirth.IsZero() && !other.DateOfBirth.IsZero() {
+		candidate.DateOfBirthMatch = user.DateOfBirth.Equal(other.DateOfBirth)
+		if candidate.DateOfBirthMatch {
+			score += dateOfBirthMatchBonus
+		} else {
+			score -= dateOfBirthMismatchPenalty
+		}
+	}
+	if candidate.EmailMatch {
+		score = 1
+	}
+	candidate.Score = min(max(score, 0), 1)
+	return candidate
+}
+
+// RankDuplicates scores the candidates as duplicates of the user and returns those scoring at least threshold,
+// best first. The user itself is never its own duplicate.
+func RankDuplicates(user User, candidates []*User, threshold float64) []DuplicateCandidate {
+	var duplicates []DuplicateCandidate
+	for _, other := range candidates {
+		if user.ID != "" && other.ID == user.ID {
+			continue
+		}
+		if candidate := ScoreDuplicate(user, other); candidate.Score >= threshold {
+			duplicates = append(duplicates, candidate)
+		}
+	}
+	slices.SortFunc(duplicates, func(a, b DuplicateCandidate) int {
+		if a.Score != b.Score {
+			if a.Score > b.Score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.User.ID, b.User.ID)
+	})
+	return duplicates
+}
+
+// FoldName is the key of a single name: names differing only in case, whitespace or Unicode composition
+// have the same key
+func FoldName(name string) string {
+	return norm.NFC.String(cases.Fold().String(NormalizeName(name)))
+}
+
+// nameSimilarity is the Jaro-Winkler similarity of the folded full names, trying both orders of the other names
+// so swapped first and last names still match. Users without any name are not similar.
+func nameSimilarity(user User, other User) float64 {
+	firstName, lastName := FoldName(user.FirstName), FoldName(user.LastName)
+	otherFirstName, otherLastName := FoldName(other.FirstName), FoldName(other.LastName)
+	if firstName+lastName == "" || otherFirstName+otherLastName == "" {
+		return 0
+	}
+	fullName := strings.TrimSpace(firstName + " " + lastName)
+	return max(JaroWinkler(fullName, strings.TrimSpace(otherFirstName+" "+otherLastName)),
+		JaroWinkler(fullName, strings.TrimSpace(otherLastName+" "+otherFirstName)))
+}
+
+// JaroWinkler returns the Jaro-Winkler similarity of two strings, from 0 for no similarity to 1 for equal strings.
+// It compares characters, not bytes, and boosts strings sharing a prefix of up to 4 characters.
+func JaroWinkler(a string, b string) float64 {
+	s1, s2 := []rune(a), []rune(b)
+	if len(s1) == 0 && len(s2) == 0 {
+		return 1
+	}
+	if len(s1) == 0 || len(s2) == 0 {
+		return 0
+	}
+
+	// characters match when they are equal and no farther apart than the window
+	window := max(max(len(s1), len(s2))/2-1, 0)
+	matched1, matched2 := make([]bool, len(s1)), make([]bool, len(s2))
+	matches := 0
+	for i, r := range s1 {
+		for j := max(0, i-window); j < min(len(s2), i+window+1); j++ {
+			if !matched2[j] && s2[j] == r {
+				matched1[i], matched2[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// transpositions are the matched characters out of order, counted twice
+	transpositions, j := 0, 0
+	for i := range s1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if s1[i] != s2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(s1)) + m/float64(len(s2)) + (m-float64(transpositions)/2)/m) / 3
+	prefix := 0
+	for prefix < min(4, len(s1), len(s2)) && s1[prefix] == s2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,130 @@
+package user
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestJaroWinkler(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected float64
+	}{
+		{a: "martha", b: "marhta", expected: 0.961},
+		{a: "dwayne", b: "duane", expected: 0.840},
+		{a: "dixon", b: "dicksonx", expected: 0.813},
+		{a: "jon doe", b: "john doe", expected: 0.967},
+		{a: "josé", b: "josé", expected: 1},
+		{a: "", b: "", expected: 1},
+		{a: "abc", b: "", expected: 0},
+		{a: "abc", b: "xyz", expected: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+"/"+test.b, func(t *testing.T) {
+			if got := JaroWinkler(test.a, test.b); math.Abs(got-test.expected) > 0.001 {
+				t.Errorf("JaroWinkler(%q, %q) = %.3f, want %.3f", test.a, test.b, got, test.expected)
+			}
+			if got, reversed := JaroWinkler(test.a, test.b), JaroWinkler(test.b, test.a); math.Abs(got-reversed) > 1e-9 {
+				t.Errorf("JaroWinkler is not symmetric: %f, %f", got, reversed)
+			}
+		})
+	}
+}
+
+func TestScoreDuplicate(t *testing.T) {
+	dateOfBirth := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	user := User{FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com", DateOfBirth: dateOfBirth}
+	tests := []struct {
+		name          string
+		other         User
+		expectedScore float64
+		expectedEmail bool
+		expectedDOB   bool
+	}{
+		{
+			name:          "similar name",
+			other:         User{FirstName: "John", LastName: "DOE", CanonicalEmail: "john@example.com"},
+			expectedScore: 0.967,
+		},
+		{
+			name:          "similar name and same date of birth",
+			other:         User{FirstName: "John", LastName: "Doe", DateOfBirth: dateOfBirth},
+			expectedScore: 1,
+			expectedDOB:   true,
+		},
+		{
+			name:          "similar name and different date of birth",
+			other:         User{FirstName: "John", LastName: "Doe", DateOfBirth: dateOfBirth.AddDate(1, 0, 0)},
+			expectedScore: 0.817,
+		},
+		{
+			name:          "swapped names",
+			other:         User{FirstName: "Doe", LastName: "Jon"},
+			expectedScore: 1,
+		},
+		{
+			name:          "same canonical email",
+			other:         User{FirstName: "Max", LastName: "Mustermann", CanonicalEmail: "jon@example.com"},
+			expectedScore: 1,
+			expectedEmail: true,
+		},
+		{
+			name:          "no names",
+			other:         User{},
+			expectedScore: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			candidate := ScoreDuplicate(user, &test.other)
+			if math.Abs(candidate.Score-test.expectedScore) > 0.001 {
+				t.Errorf("ScoreDuplicate() score = %.3f, want %.3f", candidate.Score, test.expectedScore)
+			}
+			if candidate.EmailMatch != test.expectedEmail || candidate.DateOfBirthMatch != test.expectedDOB {
+				t.Errorf("ScoreDuplicate() email match %v, date of birth match %v, want %v, %v",
+					candidate.EmailMatch, candidate.DateOfBirthMatch, test.expectedEmail, test.expectedDOB)
+			}
+		})
+	}
+}
+
+func TestRankDuplicates(t *testing.T) {
+	user := User{ID: "1", FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com"}
+	candidates := []*User{
+		{ID: "1", FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com"},
+		{ID: "2", FirstName: "Jane", LastName: "Doe"},
+		{ID: "3", FirstName: "John", LastName: "Doe"},
+		{ID: "4", FirstName: "Max", LastName: "Mustermann", CanonicalEmail: "jon@example.com"},
+	}
+
+	duplicates := RankDuplicates(user, candidates, 0.9)
+
+	if len(duplicates) != 2 || duplicates[0].User.ID != "4" || duplicates[1].User.ID != "3" {
+		t.Errorf("RankDuplicates() = %+v, want users 4 and 3", duplicates)
+	}
+}
+
+func TestParseDuplicateMode(t *testing.T) {
+	for _, mode := range []DuplicateMode{DuplicateModeOff, DuplicateModeAdvisory, DuplicateModeBlocking} {
+		if got, err := ParseDuplicateMode(string(mode)); err != nil || got != mode {
+			t.Errorf("ParseDuplicateMode(%q) = %q, %v", mode, got, err)
+		}
+	}
+	if _, err := ParseDuplicateMode("strict"); err == nil {
+		t.Error("ParseDuplicateMode(strict) expected an error")
+	}
+}
+
+func TestDuplicateError(t *testing.T) {
+	err := &DuplicateError{Candidates: []DuplicateCandidate{{User: &User{ID: "1"}}, {User: &User{ID: "2"}}}}
+	if !errors.Is(err, ErrPossibleDuplicate) {
+		t.Errorf("expected %v to wrap %v", err, ErrPossibleDuplicate)
+	}
+	if expected := "user is a possible duplicate of another user: 1, 2"; err.Error() != expected {
+		t.Errorf("Error() = %q, want %q", err.Error(), expected)
+	}
+}
@@ -0,0 +1,59 @@
+package user
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// errEmailInvalid is returned when an email is not a single RFC 5322 or RFC 6531 address
+var errEmailInvalid = errors.New("email is not a valid address")
+
+// gmailDomains are the domains whose addresses ignore dots and +tags in the local part
+var gmailDomains = map[string]bool{"gmail.com": true, "googlemail.com": true}
+
+// Email is a parsed email address
+type Email struct {
+	// Display is the address as entered, trimmed and with its domain lowercased
+	Display string
+	// Canonical identifies the mailbox: it is lowercased, its domain is in ASCII form and,
+	// with Gmail canonicalization, Gmail addresses lose their dots and +tags
+	Canonical string
+	// Domain is the ASCII form of the domain
+	Domain string
+}
+
+// ParseEmail parses a bare address, display names and comments are rejected.
+// Internationalized domains are validated as IDNA and canonicalized to their ASCII form.
+func ParseEmail(raw string, gmailCanonicalization bool) (Email, error) {
+	trimmed := strings.TrimSpace(raw)
+	address, err := mail.ParseAddress(trimmed)
+	if err != nil || address.Name != "" || address.Address != trimmed {
+		return Email{}, errEmailInvalid
+	}
+	at := strings.LastIndex(trimmed, "@")
+	local, domain := trimmed[:at], trimmed[at+1:]
+	if strings.HasPrefix(domain, "[") {
+		// domain literals are valid in RFC 5322 but do not identify a mailbox reliably
+		return Email{}, errEmailInvalid
+	}
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil || !strings.Contains(asciiDomain, ".") {
+		return Email{}, errEmailInvalid
+	}
+	asciiDomain = strings.ToLower(asciiDomain)
+
+	canonicalLocal := strings.ToLower(local)
+	if gmailCanonicalization && gmailDomains[asciiDomain] {
+		canonicalLocal, _, _ = strings.Cut(canonicalLocal, "+")
+		canonicalLocal = strings.ReplaceAll(canonicalLocal, ".", "")
+		asciiDomain = "gmail.com"
+	}
+	return Email{
+		Display:   local + "@" + strings.ToLower(domain),
+		Canonical: canonicalLocal + "@" + asciiDomain,
+		Domain:    asciiDomain,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package user
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseEmail(t *testing.T) {
+	tests := []struct {
+		name                  string
+		raw                   string
+		gmailCanonicalization bool
+		expected              Email
+		expectedErr           bool
+	}{
+		{
+			name:     "trims and lowercases the domain",
+			raw:      "  John.Doe@Example.COM ",
+			expected: Email{Display: "John.Doe@example.com", Canonical: "john.doe@example.com", Domain: "example.com"},
+		},
+		{
+			name:     "internationalized domain",
+			raw:      "jürgen@Bücher.example",
+			expected: Email{Display: "jürgen@bücher.example", Canonical: "jürgen@xn--bcher-kva.example", Domain: "xn--bcher-kva.example"},
+		},
+		{
+			name:     "gmail kept as entered without canonicalization",
+			raw:      "John.Doe+news@gmail.com",
+			expected: Email{Display: "John.Doe+news@gmail.com", Canonical: "john.doe+news@gmail.com", Domain: "gmail.com"},
+		},
+		{
+			name:                  "gmail canonicalization",
+			raw:                   "John.Doe+news@GoogleMail.com",
+			gmailCanonicalization: true,
+			expected:              Email{Display: "John.Doe+news@googlemail.com", Canonical: "johndoe@gmail.com", Domain: "gmail.com"},
+		},
+		{
+			name:                  "gmail canonicalization leaves other domains",
+			raw:                   "john.doe+news@example.com",
+			gmailCanonicalization: true,
+			expected:              Email{Display: "john.doe+news@example.com", Canonical: "john.doe+news@example.com", Domain: "example.com"},
+		},
+		{name: "only an at sign", raw: "@", expectedErr: true},
+		{name: "two at signs", raw: "a@b@example.com", expectedErr: true},
+		{name: "unquoted space", raw: "john doe@example.com", expectedErr: true},
+		{name: "display name", raw: "John <john@example.com>", expectedErr: true},
+		{name: "domain literal", raw: "john@[192.0.2.1]", expectedErr: true},
+		{name: "domain without a dot", raw: "john@localhost", expectedErr: true},
+		{name: "invalid internationalized domain", raw: "john@exa_mple.com", expectedErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			email, err := ParseEmail(test.raw, test.gmailCanonicalization)
+			if (err != nil) != test.expectedErr {
+				t.Fatalf("ParseEmail(%q) error = %v, expectedErr %v", test.raw, err, test.expectedErr)
+			}
+			if email != test.expected {
+				t.Errorf("ParseEmail(%q) = %+v, want %+v", test.raw, email, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.GmailCanonicalization = true
+	validationService, err := NewConfiguredValidationService(config)
+	if err != nil {
+		t.Fatalf("NewConfiguredValidationService() error = %v", err)
+	}
+
+	valid := User{Email: " J.Doe+work@Gmail.com", CanonicalEmail: "stale@example.com"}
+	validationService.Normalize(&valid)
+	if valid.Email != "J.Doe+work@gmail.com" || valid.CanonicalEmail != "jdoe@gmail.com" {
+		t.Errorf("Normalize() email = %q, canonical %q", valid.Email, valid.CanonicalEmail)
+	}
+
+	invalid := User{Email: " john.doe ", CanonicalEmail: "stale@example.com"}
+	validationService.Normalize(&invalid)
+	if invalid.Email != "john.doe" || invalid.CanonicalEmail != "" {
+		t.Errorf("Normalize() email = %q, canonical %q", invalid.Email, invalid.CanonicalEmail)
+	}
+
+	migrated := User{DateOfBirth: time.Date(2000, 1, 2, 23, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)), Age: 30}
+	validationService.Normalize(&migrated)
+	if !migrated.DateOfBirth.Equal(time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)) || migrated.Age != 0 {
+		t.Errorf("Normalize() date of birth = %v, age %d", migrated.DateOfBirth, migrated.Age)
+	}
+}
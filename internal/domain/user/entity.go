@@ -1,11 +1,38 @@
 // Package user contains the logic for the User domain.
 package user
 
+import "time"
+
 // User is a user entity
 type User struct {
 	ID        string
 	FirstName string
 	LastName  string
 	Email     string
-	Age       int
+	// CanonicalEmail identifies the mailbox of Email, it is empty when Email is not a valid address
+	CanonicalEmail string
+	// EmailVerifiedAt is the time Email was verified, it is zero when it is not verified
+	EmailVerifiedAt time.Time
+	// DateOfBirth is the UTC midnight of the user's date of birth, it is zero for legacy users saved with an Age only
+	DateOfBirth time.Time
+	// Age is the age legacy users were saved with, it is zero for users with a DateOfBirth; see AgeAt
+	Age int
+	// Phone is an E.164 phone number such as +14155550123, it is optional
+	Phone string
+	// Address is the postal address, it is optional
+	Address Address
+	// Locale is the preferred BCP 47 locale such as en-US, it is optional
+	Locale string
+	// TimeZone is the preferred IANA time zone such as Europe/Berlin, it is optional
+	TimeZone string
+	// Version is incremented by the repository on every save, it is zero for users that were never saved
+	Version int64
+	// Status is the onboarding lifecycle status, it only changes by a Transition
+	Status Status
+	// StatusHistory are the transitions of the status in order
+	StatusHistory []StatusTransition
+	// CreatedAt is the time of the first save, it is zero for users saved before it was recorded
+	CreatedAt time.Time
+	// UpdatedAt is the time of the last save, it is zero for users saved before it was recorded
+	UpdatedAt time.Time
 }
@@ -1,43 +1,194 @@
 package user
 
-import "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
 
 const (
-	ErrorAgeMinimum    = "AGE_MINIMUM"
-	ErrorEmailFormat   = "EMAIL_FORMAT"
-	ErrorEmailRequired = "EMAIL_REQUIRED"
-	ErrorNameRequired  = "NAME_REQUIRED"
+	ErrorAgeMinimum      = "AGE_MINIMUM"
+	ErrorAgeMaximum      = "AGE_MAXIMUM"
+	ErrorEmailFormat     = "EMAIL_FORMAT"
+	ErrorEmailRequired   = "EMAIL_REQUIRED"
+	ErrorEmailDisposable = "EMAIL_DISPOSABLE"
+	ErrorNameRequired    = "NAME_REQUIRED"
+	ErrorNameLength      = "NAME_LENGTH"
+	ErrorPhoneFormat     = "PHONE_FORMAT"
+	ErrorAddressRequired = "ADDRESS_REQUIRED"
+	ErrorAddressCountry  = "ADDRESS_COUNTRY"
+	ErrorPostalCode      = "ADDRESS_POSTAL_CODE"
+	ErrorLocaleFormat    = "LOCALE_FORMAT"
+	ErrorTimeZoneUnknown = "TIME_ZONE_UNKNOWN"
+	// ErrorNameCombinationExists reports ErrNameCombinationExists where failures are listed as validation errors
+	ErrorNameCombinationExists = "NAME_COMBINATION_EXISTS"
+)
+
+var (
+	// ErrNotFound is returned when a user does not exist
+	ErrNotFound = errors.New("user not found")
+	// ErrAlreadyExists is returned when creating a user with an id that is already taken
+	ErrAlreadyExists = errors.New("user already exists")
+	// ErrNameCombinationExists is returned when another user has the same first and last name
+	ErrNameCombinationExists = errors.New("name combination already exists")
+	// ErrVersionConflict is returned when saving a user whose version is not the stored version
+	ErrVersionConflict = errors.New("user version conflict")
 )
 
 // Error Constructors
 // NewAgeMinimumError creates a new age minimum error
-func NewAgeMinimumError() shared.ValidationError {
+func NewAgeMinimumError(age int, minAge int) shared.ValidationError {
 	return shared.ValidationError{
-		Code:    ErrorAgeMinimum,
-		Message: "User does not meet minimum age requirement",
+		Code:     ErrorAgeMinimum,
+		Message:  fmt.Sprintf("User does not meet minimum age requirement of %d", minAge),
+		Field:    FieldAge,
+		Value:    strconv.Itoa(age),
+		Params:   map[string]string{"min": strconv.Itoa(minAge)},
+		Severity: shared.SeverityError,
 	}
 }
 
 // NewEmailFormatError creates a new email format error
-func NewEmailFormatError() shared.ValidationError {
+func NewEmailFormatError(email string) shared.ValidationError {
 	return shared.ValidationError{
-		Code:    ErrorEmailFormat,
-		Message: "User email must be properly formatted",
+		Code:     ErrorEmailFormat,
+		Message:  "User email must be properly formatted",
+		Field:    FieldEmail,
+		Value:    email,
+		Severity: shared.SeverityError,
 	}
 }
 
 // NewEmailRequiredError creates a new email required error
 func NewEmailRequiredError() shared.ValidationError {
 	return shared.ValidationError{
-		Code:    ErrorEmailRequired,
-		Message: "User email is required",
+		Code:     ErrorEmailRequired,
+		Message:  "User email is required",
+		Field:    FieldEmail,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewNameRequiredError creates a new name required error for the FieldFirstName or FieldLastName field
+func NewNameRequiredError(field string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorNameRequired,
+		Message:  "User first/last name is required",
+		Field:    field,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewAgeMaximumError creates a new age maximum error
+func NewAgeMaximumError(age int, maxAge int) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorAgeMaximum,
+		Message:  fmt.Sprintf("User exceeds the maximum age of %d", maxAge),
+		Field:    FieldAge,
+		Value:    strconv.Itoa(age),
+		Params:   map[string]string{"max": strconv.Itoa(maxAge)},
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewNameLengthError creates a new name length error for the FieldFirstName or FieldLastName field
+func NewNameLengthError(field string, name string, minLength int, maxLength int) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorNameLength,
+		Message:  fmt.Sprintf("User first/last name must be between %d and %d characters", minLength, maxLength),
+		Field:    field,
+		Value:    name,
+		Params:   map[string]string{"min": strconv.Itoa(minLength), "max": strconv.Itoa(maxLength)},
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewEmailDisposableError creates a new disposable email error, domain is the blocked domain the email belongs to
+func NewEmailDisposableError(email string, domain string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorEmailDisposable,
+		Message:  "User email must not use the disposable email domain " + domain,
+		Field:    FieldEmail,
+		Value:    email,
+		Params:   map[string]string{"domain": domain},
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewNameCombinationExistsError creates a new name combination exists error, it concerns both names so it has no field
+func NewNameCombinationExistsError() shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorNameCombinationExists,
+		Message:  ErrNameCombinationExists.Error(),
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewPhoneFormatError creates a new phone format error
+func NewPhoneFormatError(phone string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorPhoneFormat,
+		Message:  "User phone must be an E.164 number such as +14155550123",
+		Field:    FieldPhone,
+		Value:    phone,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewAddressRequiredError creates a new address required error for a missing part of a non-empty address,
+// the field is FieldAddressLine1, FieldAddressCity or FieldAddressCountry
+func NewAddressRequiredError(field string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorAddressRequired,
+		Message:  "User address must have a street, city and country",
+		Field:    field,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewAddressCountryError creates a new address country error
+func NewAddressCountryError(country string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorAddressCountry,
+		Message:  "User address country must be an ISO 3166-1 alpha-2 code",
+		Field:    FieldAddressCountry,
+		Value:    country,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewPostalCodeError creates a new address postal code error
+func NewPostalCodeError(postalCode string, maxLength int) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorPostalCode,
+		Message:  fmt.Sprintf("User address postal code must be at most %d letters, digits, spaces or hyphens", maxLength),
+		Field:    FieldAddressPostalCode,
+		Value:    postalCode,
+		Params:   map[string]string{"max": strconv.Itoa(maxLength)},
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewLocaleFormatError creates a new locale format error
+func NewLocaleFormatError(locale string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorLocaleFormat,
+		Message:  "User locale must be a BCP 47 language tag such as en-US",
+		Field:    FieldLocale,
+		Value:    locale,
+		Severity: shared.SeverityError,
 	}
 }
 
-// NewNameRequiredError creates a new name required error
-func NewNameRequiredError() shared.ValidationError {
+// NewTimeZoneUnknownError creates a new unknown time zone error
+func NewTimeZoneUnknownError(timeZone string) shared.ValidationError {
 	return shared.ValidationError{
-		Code:    ErrorNameRequired,
-		Message: "User first/last name is required",
+		Code:     ErrorTimeZoneUnknown,
+		Message:  "User time zone must be an IANA time zone such as Europe/Berlin",
+		Field:    FieldTimeZone,
+		Value:    timeZone,
+		Severity: shared.SeverityError,
 	}
 }
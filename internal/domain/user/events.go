@@ -0,0 +1,51 @@
+package user
+
+import (
+	"context"
+	"time"
+)
+
+// EventType is the type of a user domain event
+type EventType string
+
+const (
+	EventUserCreated EventType = "user.created"
+	EventUserUpdated EventType = "user.updated"
+	EventUserDeleted EventType = "user.deleted"
+)
+
+// Event is a domain event emitted when a user changes
+type Event struct {
+	ID     string
+	Type   EventType
+	UserID string
+	// User is the user after the change, or before it for deleted users
+	User       User
+	Changes    []FieldChange
+	Actor      string
+	RequestID  string
+	OccurredAt time.Time
+}
+
+// Outbox stores events in the same backend as the users until they are delivered
+type Outbox interface {
+	// Add adds an event to the outbox
+	Add(ctx context.Context, event *Event) error
+	// Pending lists up to limit undelivered events ordered by the time they occurred
+	Pending(ctx context.Context, limit int) ([]*Event, error)
+	// MarkDelivered marks an event as delivered so it is not returned by Pending again
+	MarkDelivered(ctx context.Context, id string) error
+}
+
+// eventTypes maps the audit actions to the event emitted for them
+var eventTypes = map[AuditAction]EventType{
+	AuditActionCreate: EventUserCreated,
+	AuditActionUpdate: EventUserUpdated,
+	AuditActionDelete: EventUserDeleted,
+}
+
+// EventTypeOf returns the type of the event emitted for an audited change.
+// Merges have no event type of their own: the survivor emits EventUserUpdated and the merged users EventUserDeleted.
+func EventTypeOf(action AuditAction) EventType {
+	return eventTypes[action]
+}
@@ -0,0 +1,208 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Rules picking the value of a field in a merge, any other rule is the id of the user whose value is picked
+const (
+	// MergeRuleSurvivor keeps the value of the survivor, it is the rule of fields without one
+	MergeRuleSurvivor = "survivor"
+	// MergeRuleNonEmpty keeps the value of the survivor unless it is empty, then picks the first non-empty value of the
+	// duplicates in request order
+	MergeRuleNonEmpty = "non_empty"
+	// MergeRuleNewest picks the non-empty value of the most recently updated user
+	MergeRuleNewest = "newest"
+)
+
+var (
+	// ErrInvalidMerge is returned for a merge request that cannot be applied
+	ErrInvalidMerge = errors.New("invalid merge")
+	// ErrMerged is returned when looking up a user that was merged into another user
+	ErrMerged = errors.New("user was merged into another user")
+)
+
+// MergeRequest merges duplicate users into a survivor.
+// Rules map the mergeable fields to the rule picking their value, the age follows the date of birth
+// and the email verification follows the email.
+type MergeRequest struct {
+	SurvivorID   string
+	DuplicateIDs []string
+	Rules        map[string]string
+}
+
+// MergeError is returned for an invalid merge request, it wraps ErrInvalidMerge.
+// Param is the invalid request parameter such as duplicate_ids or fields.email.
+type MergeError struct {
+	Param  string
+	Reason string
+}
+
+// Error returns the error message
+func (e *MergeError) Error() string {
+	return fmt.Sprintf("%s: %s %s", ErrInvalidMerge, e.Param, e.Reason)
+}
+
+// Unwrap returns ErrInvalidMerge
+func (e *MergeError) Unwrap() error {
+	return ErrInvalidMerge
+}
+
+// MergedError is returned when looking up a user that was merged into another user.
+// It wraps both ErrMerged and ErrNotFound so callers that do not follow merges see a missing user.
+type MergedError struct {
+	ID         string
+	MergedInto string
+}
+
+// Error returns the error message
+func (e *MergedError) Error() string {
+	return fmt.Sprintf("user %q was merged into user %q", e.ID, e.MergedInto)
+}
+
+// Unwrap returns ErrMerged and ErrNotFound
+func (e *MergedError) Unwrap() []error {
+	return []error{ErrMerged, ErrNotFound}
+}
+
+// Tombstone records that a user was merged into a survivor and removed
+type Tombstone struct {
+	ID         string
+	MergedInto string
+	MergedAt   time.Time
+}
+
+// mergeField reads and copies a mergeable field
+type mergeField struct {
+	empty func(user User) bool
+	copy  func(dst *User, src User)
+}
+
+// mergeFields are the fields a merge request has rules for
+var mergeFields = map[string]mergeField{
+	FieldFirstName: {
+		empty: func(user User) bool { return user.FirstName == "" },
+		copy:  func(dst *User, src User) { dst.FirstName = src.FirstName },
+	},
+	FieldLastName: {
+		empty: func(user User) bool { return user.LastName == "" },
+		copy:  func(dst *User, src User) { dst.LastName = src.LastName },
+	},
+	FieldEmail: {
+		empty: func(user User) bool { return user.Email == "" },
+		copy: func(dst *User, src User) {
+			dst.Email, dst.CanonicalEmail, dst.EmailVerifiedAt = src.Email, src.CanonicalEmail, src.EmailVerifiedAt
+		},
+	},
+	FieldDateOfBirth: {
+		empty: func(user User) bool { return user.DateOfBirth.IsZero() && user.Age == 0 },
+		copy:  func(dst *User, src User) { dst.DateOfBirth, dst.Age = src.DateOfBirth, src.Age },
+	},
+	FieldPhone: {
+		empty: func(user User) bool { return user.Phone == "" },
+		copy:  func(dst *User, src User) { dst.Phone = src.Phone },
+	},
+	FieldAddress: {
+		empty: func(user User) bool { return user.Address == Address{} },
+		copy:  func(dst *User, src User) { dst.Address = src.Address },
+	},
+	FieldLocale: {
+		empty: func(user User) bool { return user.Locale == "" },
+		copy:  func(dst *User, src User) { dst.Locale = src.Locale },
+	},
+	FieldTimeZone: {
+		empty: func(user User) bool { return user.TimeZone == "" },
+		copy:  func(dst *User, src User) { dst.TimeZone = src.TimeZone },
+	},
+}
+
+// MergeFields returns the fields a merge request has rules for, sorted
+func MergeFields() []string {
+	fields := make([]string, 0, len(mergeFields))
+	for field := range mergeFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Validate checks the request before the users are loaded, it returns a MergeError
+func (r MergeRequest) Validate() error {
+	if r.SurvivorID == "" {
+		return &MergeError{Param: "survivor_id", Reason: "is required"}
+	}
+	if len(r.DuplicateIDs) == 0 {
+		return &MergeError{Param: "duplicate_ids", Reason: "must not be empty"}
+	}
+	for i, id := range r.DuplicateIDs {
+		switch {
+		case id == "":
+			return &MergeError{Param: "duplicate_ids", Reason: "must not contain empty ids"}
+		case id == r.SurvivorID:
+			return &MergeError{Param: "duplicate_ids", Reason: fmt.Sprintf("must not contain the survivor %q", id)}
+		case slices.Contains(r.DuplicateIDs[:i], id):
+			return &MergeError{Param: "duplicate_ids", Reason: fmt.Sprintf("must not contain %q twice", id)}
+		}
+	}
+	for field, rule := range r.Rules {
+		if _, ok := mergeFields[field]; !ok {
+			return &MergeError{Param: "fields." + field, Reason: fmt.Sprintf("is not a mergeable field, must be one of %v", MergeFields())}
+		}
+		switch rule {
+		case MergeRuleSurvivor, MergeRuleNonEmpty, MergeRuleNewest, r.SurvivorID:
+			continue
+		}
+		if !slices.Contains(r.DuplicateIDs, rule) {
+			return &MergeError{Param: "fields." + field, Reason: fmt.Sprintf("must be %s, %s, %s or the id of a merged user",
+				MergeRuleSurvivor, MergeRuleNonEmpty, MergeRuleNewest)}
+		}
+	}
+	return nil
+}
+
+// Apply returns the survivor with the fields picked by the rules from the survivor and its duplicates,
+// which are in the order of DuplicateIDs. The request must be valid.
+func (r MergeRequest) Apply(survivor User, duplicates []*User) *User {
+	merged := survivor
+	merged.StatusHistory = slices.Clone(survivor.StatusHistory)
+	users := append([]*User{&survivor}, duplicates...)
+	for field, rule := range r.Rules {
+		if source := pickMergeSource(mergeFields[field], rule, users); source != nil {
+			mergeFields[field].copy(&merged, *source)
+		}
+	}
+	return &merged
+}
+
+// pickMergeSource returns the user whose value of the field the rule picks, survivor first, nil to keep the survivor's
+func pickMergeSource(field mergeField, rule string, users []*User) *User {
+	switch rule {
+	case MergeRuleSurvivor:
+		return nil
+	case MergeRuleNonEmpty:
+		for _, user := range users {
+			if !field.empty(*user) {
+				return user
+			}
+		}
+		return nil
+	case MergeRuleNewest:
+		var newest *User
+		for _, user := range users {
+			if !field.empty(*user) && (newest == nil || user.UpdatedAt.After(newest.UpdatedAt)) {
+				newest = user
+			}
+		}
+		return newest
+	}
+	for _, user := range users {
+		if user.ID == rule {
+			return user
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package user
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMergeRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		request       MergeRequest
+		expectedParam string
+	}{
+		{
+			name:    "valid",
+			request: MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2", "3"}, Rules: map[string]string{FieldEmail: "3", FieldPhone: MergeRuleNewest}},
+		},
+		{
+			name:          "missing survivor",
+			request:       MergeRequest{DuplicateIDs: []string{"2"}},
+			expectedParam: "survivor_id",
+		},
+		{
+			name:          "no duplicates",
+			request:       MergeRequest{SurvivorID: "1"},
+			expectedParam: "duplicate_ids",
+		},
+		{
+			name:          "survivor among the duplicates",
+			request:       MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2", "1"}},
+			expectedParam: "duplicate_ids",
+		},
+		{
+			name:          "repeated duplicate",
+			request:       MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2", "2"}},
+			expectedParam: "duplicate_ids",
+		},
+		{
+			name:          "unknown field",
+			request:       MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2"}, Rules: map[string]string{FieldStatus: "2"}},
+			expectedParam: "fields.status",
+		},
+		{
+			name:          "user outside the merge",
+			request:       MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2"}, Rules: map[string]string{FieldEmail: "4"}},
+			expectedParam: "fields.email",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.request.Validate()
+			if test.expectedParam == "" {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			var mergeErr *MergeError
+			if !errors.As(err, &mergeErr) || mergeErr.Param != test.expectedParam || !errors.Is(err, ErrInvalidMerge) {
+				t.Errorf("Validate() error = %v, want an invalid %s", err, test.expectedParam)
+			}
+		})
+	}
+}
+
+func TestMergeRequest_Apply(t *testing.T) {
+	verifiedAt := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	dateOfBirth := time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)
+	survivor := User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 30, Locale: "en-US",
+		UpdatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), Version: 2}
+	duplicates := []*User{
+		{ID: "2", FirstName: "Jon", Email: "jon@example.com", CanonicalEmail: "jon@example.com", EmailVerifiedAt: verifiedAt,
+			DateOfBirth: dateOfBirth, UpdatedAt: time.Date(2026, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: "3", Phone: "+14155550123", Locale: "de-DE", UpdatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	request := MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2", "3"}, Rules: map[string]string{
+		FieldFirstName:   MergeRuleSurvivor,
+		FieldLastName:    MergeRuleNewest,
+		FieldEmail:       "2",
+		FieldDateOfBirth: "2",
+		FieldPhone:       MergeRuleNonEmpty,
+		FieldLocale:      MergeRuleNewest,
+	}}
+
+	merged := request.Apply(survivor, duplicates)
+
+	if merged.ID != "1" || merged.Version != 2 || merged.FirstName != "John" || merged.LastName != "Doe" {
+		t.Errorf("expected the survivor's id, version and names, got %+v", merged)
+	}
+	if merged.Email != "jon@example.com" || merged.CanonicalEmail != "jon@example.com" || !merged.EmailVerifiedAt.Equal(verifiedAt) {
+		t.Errorf("expected the verified email of user 2, got %q verified at %v", merged.Email, merged.EmailVerifiedAt)
+	}
+	if !merged.DateOfBirth.Equal(dateOfBirth) || merged.Age != 0 {
+		t.Errorf("expected the date of birth of user 2 without an age, got %v and %d", merged.DateOfBirth, merged.Age)
+	}
+	if merged.Phone != "+14155550123" || merged.Locale != "de-DE" {
+		t.Errorf("expected the phone and locale of user 3, got %q and %q", merged.Phone, merged.Locale)
+	}
+}
@@ -0,0 +1,21 @@
+package user
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeName trims a name, collapses its inner whitespace to single spaces and composes it to Unicode NFC
+func NormalizeName(name string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(name), " "))
+}
+
+// NameKey is the key two users with the same name share: names differing only in case,
+// whitespace or Unicode composition have the same key
+func NameKey(firstName string, lastName string) string {
+	fold := cases.Fold()
+	// normalized names hold no newline so it cannot be mistaken for part of a name
+	return norm.NFC.String(fold.String(NormalizeName(firstName)) + "\n" + fold.String(NormalizeName(lastName)))
+}
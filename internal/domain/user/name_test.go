@@ -0,0 +1,48 @@
+package user
+
+import "testing"
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "trims and collapses whitespace", input: " Mary \t Ann ", expected: "Mary Ann"},
+		{name: "composes to NFC", input: "José", expected: "José"},
+		{name: "keeps the case", input: "McDonald", expected: "McDonald"},
+		{name: "only whitespace", input: "  \t", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NormalizeName(test.input); got != test.expected {
+				t.Errorf("NormalizeName(%q) = %q, want %q", test.input, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNameKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		first, second [2]string
+		expectedEqual bool
+	}{
+		{name: "case", first: [2]string{"John", "Doe"}, second: [2]string{"JOHN", "doe"}, expectedEqual: true},
+		{name: "whitespace", first: [2]string{"Mary Ann", "Doe"}, second: [2]string{" Mary  Ann", "Doe "}, expectedEqual: true},
+		{name: "composition", first: [2]string{"José", "Doe"}, second: [2]string{"JOSÉ", "Doe"}, expectedEqual: true},
+		{name: "full case folding", first: [2]string{"Strauß", "Doe"}, second: [2]string{"STRAUSS", "Doe"}, expectedEqual: true},
+		{name: "split between first and last name", first: [2]string{"Mary Ann", "Doe"}, second: [2]string{"Mary", "Ann Doe"}, expectedEqual: false},
+		{name: "accents are significant", first: [2]string{"José", "Doe"}, second: [2]string{"Jose", "Doe"}, expectedEqual: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			equal := NameKey(test.first[0], test.first[1]) == NameKey(test.second[0], test.second[1])
+			if equal != test.expectedEqual {
+				t.Errorf("NameKey(%q) == NameKey(%q) is %v, want %v", test.first, test.second, equal, test.expectedEqual)
+			}
+		})
+	}
+}
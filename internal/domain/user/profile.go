@@ -0,0 +1,114 @@
+package user
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	// the time zone database is embedded so time zones validate the same without a system database
+	_ "time/tzdata"
+
+	"golang.org/x/text/language"
+)
+
+// Fields of the parts of an address reported in validation errors
+const (
+	FieldAddressLine1      = FieldAddress + ".line1"
+	FieldAddressCity       = FieldAddress + ".city"
+	FieldAddressPostalCode = FieldAddress + ".postal_code"
+	FieldAddressCountry    = FieldAddress + ".country"
+)
+
+// postalCodeMaxLength bounds the length of postal codes, the longest in use have 10 characters
+const postalCodeMaxLength = 10
+
+// postalCodePattern matches the characters of postal codes
+var postalCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]*$`)
+
+// Address is a postal address, it is empty when none was given
+type Address struct {
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	// Country is the ISO 3166-1 alpha-2 country code
+	Country string
+}
+
+// IsZero reports whether the address is empty
+func (a Address) IsZero() bool {
+	return a == Address{}
+}
+
+// String formats the address on a single line, empty parts are left out
+func (a Address) String() string {
+	var parts []string
+	for _, part := range []string{a.Line1, a.Line2, a.City, a.Region, a.PostalCode, a.Country} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// e164Pattern matches E.164 phone numbers: a + and up to 15 digits, the first digit of the country code not being 0
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
+// phoneSeparators are the characters commonly used to group the digits of phone numbers
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "", "/", "")
+
+// NormalizePhone removes the grouping characters of a phone number and replaces a 00 international prefix by +.
+// A number without a country code is not E.164 and is left to the validation.
+func NormalizePhone(phone string) string {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+	if rest, ok := strings.CutPrefix(phone, "00"); ok {
+		phone = "+" + rest
+	}
+	return phone
+}
+
+// NormalizeAddress trims the parts of an address and uppercases its postal code and country code
+func NormalizeAddress(address Address) Address {
+	return Address{
+		Line1:      NormalizeName(address.Line1),
+		Line2:      NormalizeName(address.Line2),
+		City:       NormalizeName(address.City),
+		Region:     NormalizeName(address.Region),
+		PostalCode: strings.ToUpper(NormalizeName(address.PostalCode)),
+		Country:    strings.ToUpper(strings.TrimSpace(address.Country)),
+	}
+}
+
+// NormalizeLocale formats a BCP 47 locale canonically, such as en_us as en-US.
+// An invalid locale is only trimmed and left to the validation.
+func NormalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if tag, err := language.Parse(locale); err == nil {
+		return tag.String()
+	}
+	return locale
+}
+
+// validLocale reports whether a locale is a well-formed BCP 47 tag
+func validLocale(locale string) bool {
+	_, err := language.Parse(locale)
+	return err == nil
+}
+
+// validCountry reports whether a code is an ISO 3166-1 alpha-2 country code
+func validCountry(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	region, err := language.ParseRegion(code)
+	return err == nil && region.IsCountry()
+}
+
+// validTimeZone reports whether a time zone is an IANA time zone name, the local time zone of the server is not
+func validTimeZone(timeZone string) bool {
+	if timeZone == "" || timeZone == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(timeZone)
+	return err == nil
+}
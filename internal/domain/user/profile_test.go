@@ -0,0 +1,54 @@
+package user
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		phone    string
+		expected string
+	}{
+		{phone: "+1 (415) 555-0123", expected: "+14155550123"},
+		{phone: " 0049 30/123.456 ", expected: "+4930123456"},
+		{phone: "030 123456", expected: "030123456"},
+		{phone: "", expected: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.phone, func(t *testing.T) {
+			if got := NormalizePhone(test.phone); got != test.expected {
+				t.Errorf("NormalizePhone(%q) = %q, want %q", test.phone, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeLocale(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{locale: "en_us", expected: "en-US"},
+		{locale: " DE-ch ", expected: "de-CH"},
+		{locale: "zh-hant-tw", expected: "zh-Hant-TW"},
+		{locale: "not a locale", expected: "not a locale"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.locale, func(t *testing.T) {
+			if got := NormalizeLocale(test.locale); got != test.expected {
+				t.Errorf("NormalizeLocale(%q) = %q, want %q", test.locale, got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNormalizeAddress(t *testing.T) {
+	got := NormalizeAddress(Address{Line1: "  221B  Baker Street ", City: "London", PostalCode: "nw1 6xe", Country: " gb"})
+	expected := Address{Line1: "221B Baker Street", City: "London", PostalCode: "NW1 6XE", Country: "GB"}
+	if got != expected {
+		t.Errorf("NormalizeAddress() = %+v, want %+v", got, expected)
+	}
+	if got.String() != "221B Baker Street, London, NW1 6XE, GB" {
+		t.Errorf("String() = %q", got.String())
+	}
+}
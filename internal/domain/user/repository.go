@@ -5,10 +5,59 @@ import "context"
 type Repository interface {
 	// FindByID finds a user by id
 	FindByID(ctx context.Context, id string) (*User, error)
-	// Save saves a user to the repository
+	// Save saves a user to the repository if its version is the stored version, zero for a new user,
+	// and returns the saved user with the next version. It returns ErrVersionConflict otherwise,
+	// and ErrNameCombinationExists when another user has the same NameKey.
 	Save(ctx context.Context, user *User) (*User, error)
-	// ExistsByFirstNameAndLastName checks if a user exists by first name and last name
+	// Delete deletes a user by id, returning ErrNotFound when there is no such user
+	Delete(ctx context.Context, id string) error
+	// List lists the users matching the filter ordered by id
+	List(ctx context.Context, filter ListFilter) ([]*User, error)
+	// Stream returns a cursor over the users matching the filter ordered by id
+	Stream(ctx context.Context, filter ListFilter) (Cursor, error)
+	// ExistsByFirstNameAndLastName checks if a user exists by first name and last name, comparing their NameKey
 	ExistsByFirstNameAndLastName(ctx context.Context, firstName string, lastName string) bool
-	// ExistsByFirstNameAndLastNameAndIDNot checks if a user exists by first name and last name but not by id
+	// ExistsByFirstNameAndLastNameAndIDNot checks if a user exists by first name and last name but not by id, comparing their NameKey
 	ExistsByFirstNameAndLastNameAndIDNot(ctx context.Context, firstName string, lastName string, id string) bool
+	// ListDuplicateCandidates lists the users other than the user that could be its duplicates: every user with its
+	// canonical email, then up to limit users in total with its first or last name compared by FoldName, each ordered by id
+	ListDuplicateCandidates(ctx context.Context, user *User, limit int) ([]*User, error)
+	// Tombstone deletes a user merged into another user at the version it was read and records its tombstone,
+	// returning ErrNotFound when there is no such user and ErrVersionConflict when it was changed since
+	Tombstone(ctx context.Context, tombstone *Tombstone, version int64) error
+	// FindTombstone finds the tombstone of a merged user by its id, returning ErrNotFound when it was not merged
+	FindTombstone(ctx context.Context, id string) (*Tombstone, error)
+}
+
+// Cursor iterates over users one at a time without loading them all in memory
+type Cursor interface {
+	// Next advances to the next user, returning false when there are no more users or an error occurred
+	Next(ctx context.Context) bool
+	// User returns the current user
+	User() *User
+	// Err returns the error that stopped the iteration, if any
+	Err() error
+	// Close releases the cursor
+	Close(ctx context.Context) error
+}
+
+// ListFilter filters and paginates the users returned by Repository.List.
+// Empty fields are not filtered on.
+type ListFilter struct {
+	FirstName string
+	LastName  string
+	Email     string
+	Status    Status
+	// AfterID only returns users with an id greater than AfterID
+	AfterID string
+	// Limit is the maximum number of users to return, zero means no limit
+	Limit int
+}
+
+// Matches reports whether the user matches the filter fields, ignoring pagination
+func (f ListFilter) Matches(user *User) bool {
+	return (f.FirstName == "" || user.FirstName == f.FirstName) &&
+		(f.LastName == "" || user.LastName == f.LastName) &&
+		(f.Email == "" || user.Email == f.Email) &&
+		(f.Status == "" || user.Status == f.Status)
 }
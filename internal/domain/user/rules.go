@@ -0,0 +1,302 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"golang.org/x/net/idna"
+)
+
+// Rule names
+const (
+	RuleAgeMinimum      = "age_minimum"
+	RuleAgeMaximum      = "age_maximum"
+	RuleRequiredFields  = "required_fields"
+	RuleEmailFormat     = "email_format"
+	RuleNameLength      = "name_length"
+	RuleDisposableEmail = "disposable_email"
+	RulePhoneFormat     = "phone_format"
+	RuleAddressFormat   = "address_format"
+	RuleLocaleFormat    = "locale_format"
+	RuleTimeZone        = "time_zone"
+)
+
+// Rule is a named check of a user, Check returns the joined validation errors of the user or nil
+type Rule struct {
+	Name  string
+	Check func(user User) error
+}
+
+// ValidationConfig selects and parameterizes the validation rules
+type ValidationConfig struct {
+	// Rules are the names of the enabled rules in the order they run
+	Rules []string
+	// MinAge is the youngest accepted age
+	MinAge int
+	// MaxAge is the oldest accepted age
+	MaxAge int
+	// NameMinLength and NameMaxLength bound the length in characters of the first and last name
+	NameMinLength int
+	NameMaxLength int
+	// EmailPattern is a regular expression valid emails must also match, any valid email is accepted when it is empty
+	EmailPattern string
+	// GmailCanonicalization drops the dots and +tags of Gmail addresses in their canonical form
+	GmailCanonicalization bool
+	// DisposableDomains are the email domains rejected by the disposable_email rule, including their subdomains
+	DisposableDomains []string
+	// Now is the clock ages are computed with, nil is time.Now
+	Now func() time.Time
+	// RequiredFields are the fields that must not be empty, among FieldFirstName, FieldLastName and FieldEmail
+	RequiredFields []string
+}
+
+// DefaultValidationConfig returns the rules and parameters used unless configured otherwise
+func DefaultValidationConfig() ValidationConfig {
+	return ValidationConfig{
+		Rules:          []string{RuleAgeMinimum, RuleRequiredFields, RuleEmailFormat, RulePhoneFormat, RuleAddressFormat, RuleLocaleFormat, RuleTimeZone},
+		MinAge:         18,
+		MaxAge:         150,
+		NameMinLength:  1,
+		NameMaxLength:  100,
+		EmailPattern:   "",
+		RequiredFields: []string{FieldFirstName, FieldLastName, FieldEmail},
+	}
+}
+
+// ruleFactories is the registry of rules by name
+var ruleFactories = map[string]func(config ValidationConfig) (Rule, error){
+	RuleAgeMinimum:      newAgeMinimumRule,
+	RuleAgeMaximum:      newAgeMaximumRule,
+	RuleRequiredFields:  newRequiredFieldsRule,
+	RuleEmailFormat:     newEmailFormatRule,
+	RuleNameLength:      newNameLengthRule,
+	RuleDisposableEmail: newDisposableEmailRule,
+	RulePhoneFormat:     newPhoneFormatRule,
+	RuleAddressFormat:   newAddressFormatRule,
+	RuleLocaleFormat:    newLocaleFormatRule,
+	RuleTimeZone:        newTimeZoneRule,
+}
+
+// RuleNames returns the names of the registered rules
+func RuleNames() []string {
+	names := make([]string, 0, len(ruleFactories))
+	for name := range ruleFactories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// NewRules builds the enabled rules of the config in order
+func NewRules(config ValidationConfig) ([]Rule, error) {
+	rules := make([]Rule, 0, len(config.Rules))
+	for _, name := range config.Rules {
+		factory, ok := ruleFactories[name]
+		if !ok {
+			return nil, fmt.Errorf("user: unknown validation rule %q, known rules are %v", name, RuleNames())
+		}
+		if slices.ContainsFunc(rules, func(rule Rule) bool { return rule.Name == name }) {
+			return nil, fmt.Errorf("user: validation rule %q is enabled twice", name)
+		}
+		rule, err := factory(config)
+		if err != nil {
+			return nil, fmt.Errorf("user: invalid %s rule: %w", name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// clock returns the clock of the config
+func (c ValidationConfig) clock() func() time.Time {
+	if c.Now == nil {
+		return time.Now
+	}
+	return c.Now
+}
+
+// newAgeMinimumRule checks the age on the current day, so a user born 18 years ago today is 18
+func newAgeMinimumRule(config ValidationConfig) (Rule, error) {
+	now := config.clock()
+	return Rule{Name: RuleAgeMinimum, Check: func(user User) error {
+		if age := user.AgeAt(now()); age < config.MinAge {
+			return onDateOfBirth(NewAgeMinimumError(age, config.MinAge), user)
+		}
+		return nil
+	}}, nil
+}
+
+func newAgeMaximumRule(config ValidationConfig) (Rule, error) {
+	if config.MaxAge < config.MinAge {
+		return Rule{}, fmt.Errorf("maximum age %d is below the minimum age %d", config.MaxAge, config.MinAge)
+	}
+	now := config.clock()
+	return Rule{Name: RuleAgeMaximum, Check: func(user User) error {
+		if age := user.AgeAt(now()); age > config.MaxAge {
+			return onDateOfBirth(NewAgeMaximumError(age, config.MaxAge), user)
+		}
+		return nil
+	}}, nil
+}
+
+// onDateOfBirth reports an age error on the date of birth the age was computed from, legacy users keep FieldAge
+func onDateOfBirth(err shared.ValidationError, user User) shared.ValidationError {
+	if !user.DateOfBirth.IsZero() {
+		err.Field, err.Value = FieldDateOfBirth, FormatDateOfBirth(user.DateOfBirth)
+	}
+	return err
+}
+
+// newRequiredFieldsRule reports a NAME_REQUIRED error for each missing name
+func newRequiredFieldsRule(config ValidationConfig) (Rule, error) {
+	for _, field := range config.RequiredFields {
+		if field != FieldFirstName && field != FieldLastName && field != FieldEmail {
+			return Rule{}, fmt.Errorf("field %q cannot be required", field)
+		}
+	}
+	firstName := slices.Contains(config.RequiredFields, FieldFirstName)
+	lastName := slices.Contains(config.RequiredFields, FieldLastName)
+	email := slices.Contains(config.RequiredFields, FieldEmail)
+	return Rule{Name: RuleRequiredFields, Check: func(user User) error {
+		var errs []error
+		if email && user.Email == "" {
+			errs = append(errs, NewEmailRequiredError())
+		}
+		// names of only whitespace are empty once normalized
+		if firstName && NormalizeName(user.FirstName) == "" {
+			errs = append(errs, NewNameRequiredError(FieldFirstName))
+		}
+		if lastName && NormalizeName(user.LastName) == "" {
+			errs = append(errs, NewNameRequiredError(FieldLastName))
+		}
+		return errors.Join(errs...)
+	}}, nil
+}
+
+// newEmailFormatRule leaves empty emails to the required fields rule
+func newEmailFormatRule(config ValidationConfig) (Rule, error) {
+	var pattern *regexp.Regexp
+	if config.EmailPattern != "" {
+		var err error
+		if pattern, err = regexp.Compile(config.EmailPattern); err != nil {
+			return Rule{}, fmt.Errorf("invalid email pattern: %w", err)
+		}
+	}
+	return Rule{Name: RuleEmailFormat, Check: func(user User) error {
+		if user.Email == "" {
+			return nil
+		}
+		if _, err := ParseEmail(user.Email, false); err != nil || (pattern != nil && !pattern.MatchString(user.Email)) {
+			return NewEmailFormatError(user.Email)
+		}
+		return nil
+	}}, nil
+}
+
+// newDisposableEmailRule leaves invalid emails to the email format rule
+func newDisposableEmailRule(config ValidationConfig) (Rule, error) {
+	blocked := make(map[string]bool, len(config.DisposableDomains))
+	for _, domain := range config.DisposableDomains {
+		asciiDomain, err := idna.Lookup.ToASCII(strings.TrimSpace(domain))
+		if err != nil {
+			return Rule{}, fmt.Errorf("invalid disposable domain %q: %w", domain, err)
+		}
+		blocked[strings.ToLower(asciiDomain)] = true
+	}
+	return Rule{Name: RuleDisposableEmail, Check: func(user User) error {
+		email, err := ParseEmail(user.Email, false)
+		if err != nil {
+			return nil
+		}
+		// the domain and each of its parent domains
+		for domain := email.Domain; domain != ""; {
+			if blocked[domain] {
+				return NewEmailDisposableError(user.Email, domain)
+			}
+			_, domain, _ = strings.Cut(domain, ".")
+		}
+		return nil
+	}}, nil
+}
+
+// newNameLengthRule leaves empty names to the required fields rule
+func newNameLengthRule(config ValidationConfig) (Rule, error) {
+	if config.NameMinLength < 0 || config.NameMaxLength < config.NameMinLength {
+		return Rule{}, fmt.Errorf("name length bounds [%d, %d] are invalid", config.NameMinLength, config.NameMaxLength)
+	}
+	check := func(field string, name string) error {
+		length := utf8.RuneCountInString(name)
+		if name != "" && (length < config.NameMinLength || length > config.NameMaxLength) {
+			return NewNameLengthError(field, name, config.NameMinLength, config.NameMaxLength)
+		}
+		return nil
+	}
+	return Rule{Name: RuleNameLength, Check: func(user User) error {
+		return errors.Join(check(FieldFirstName, user.FirstName), check(FieldLastName, user.LastName))
+	}}, nil
+}
+
+// newPhoneFormatRule checks that a phone is E.164, an empty phone is valid
+func newPhoneFormatRule(config ValidationConfig) (Rule, error) {
+	return Rule{Name: RulePhoneFormat, Check: func(user User) error {
+		if user.Phone != "" && !e164Pattern.MatchString(user.Phone) {
+			return NewPhoneFormatError(user.Phone)
+		}
+		return nil
+	}}, nil
+}
+
+// newAddressFormatRule requires the street, city and country of a non-empty address and checks its country and
+// postal code, an empty address is valid
+func newAddressFormatRule(config ValidationConfig) (Rule, error) {
+	return Rule{Name: RuleAddressFormat, Check: func(user User) error {
+		address := user.Address
+		if address.IsZero() {
+			return nil
+		}
+		var errs []error
+		for _, part := range []struct{ field, value string }{
+			{FieldAddressLine1, address.Line1},
+			{FieldAddressCity, address.City},
+			{FieldAddressCountry, address.Country},
+		} {
+			if strings.TrimSpace(part.value) == "" {
+				errs = append(errs, NewAddressRequiredError(part.field))
+			}
+		}
+		if address.Country != "" && !validCountry(address.Country) {
+			errs = append(errs, NewAddressCountryError(address.Country))
+		}
+		if address.PostalCode != "" &&
+			(utf8.RuneCountInString(address.PostalCode) > postalCodeMaxLength || !postalCodePattern.MatchString(address.PostalCode)) {
+			errs = append(errs, NewPostalCodeError(address.PostalCode, postalCodeMaxLength))
+		}
+		return errors.Join(errs...)
+	}}, nil
+}
+
+// newLocaleFormatRule checks that a locale is a BCP 47 tag, an empty locale is valid
+func newLocaleFormatRule(config ValidationConfig) (Rule, error) {
+	return Rule{Name: RuleLocaleFormat, Check: func(user User) error {
+		if user.Locale != "" && !validLocale(user.Locale) {
+			return NewLocaleFormatError(user.Locale)
+		}
+		return nil
+	}}, nil
+}
+
+// newTimeZoneRule checks that a time zone is an IANA time zone, an empty time zone is valid
+func newTimeZoneRule(config ValidationConfig) (Rule, error) {
+	return Rule{Name: RuleTimeZone, Check: func(user User) error {
+		if user.TimeZone != "" && !validTimeZone(user.TimeZone) {
+			return NewTimeZoneUnknownError(user.TimeZone)
+		}
+		return nil
+	}}, nil
+}
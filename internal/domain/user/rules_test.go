@@ -0,0 +1,309 @@
+package user
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+func TestNewRules_ValidateUser(t *testing.T) {
+	valid := User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20}
+	withConfig := func(change func(config *ValidationConfig)) ValidationConfig {
+		config := DefaultValidationConfig()
+		change(&config)
+		return config
+	}
+
+	tests := []struct {
+		name          string
+		config        ValidationConfig
+		user          User
+		expectedCodes []string
+	}{
+		{
+			name:          "defaults aggregate every failure in rule order",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", Email: "john.doe", Age: 12},
+			expectedCodes: []string{ErrorAgeMinimum, ErrorNameRequired, ErrorNameRequired, ErrorEmailFormat},
+		},
+		{
+			name:          "configured minimum age",
+			config:        withConfig(func(config *ValidationConfig) { config.MinAge = 21 }),
+			user:          valid,
+			expectedCodes: []string{ErrorAgeMinimum},
+		},
+		{
+			name: "maximum age",
+			config: withConfig(func(config *ValidationConfig) {
+				config.Rules = append(config.Rules, RuleAgeMaximum)
+				config.MaxAge = 19
+			}),
+			user:          valid,
+			expectedCodes: []string{ErrorAgeMaximum},
+		},
+		{
+			name: "name length counts characters",
+			config: withConfig(func(config *ValidationConfig) {
+				config.Rules = append(config.Rules, RuleNameLength)
+				config.NameMinLength, config.NameMaxLength = 2, 4
+			}),
+			user:          User{ID: "1", FirstName: "Zoë", LastName: "Doe", Email: "zoe@example.com", Age: 20},
+			expectedCodes: nil,
+		},
+		{
+			name: "name too long",
+			config: withConfig(func(config *ValidationConfig) {
+				config.Rules = append(config.Rules, RuleNameLength)
+				config.NameMaxLength = 3
+			}),
+			user:          valid,
+			expectedCodes: []string{ErrorNameLength},
+		},
+		{
+			name:          "email pattern",
+			config:        withConfig(func(config *ValidationConfig) { config.EmailPattern = `@example\.org$` }),
+			user:          valid,
+			expectedCodes: []string{ErrorEmailFormat},
+		},
+		{
+			name:          "email with a display name",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "John Doe <john.doe@example.com>", Age: 20},
+			expectedCodes: []string{ErrorEmailFormat},
+		},
+		{
+			name: "disposable email domain",
+			config: withConfig(func(config *ValidationConfig) {
+				config.Rules = append(config.Rules, RuleDisposableEmail)
+				config.DisposableDomains = []string{"Mailinator.com"}
+			}),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@eu.mailinator.com", Age: 20},
+			expectedCodes: []string{ErrorEmailDisposable},
+		},
+		{
+			name: "disposable rule leaves invalid emails to the format rule",
+			config: withConfig(func(config *ValidationConfig) {
+				config.Rules = append(config.Rules, RuleDisposableEmail)
+				config.DisposableDomains = []string{"mailinator.com"}
+			}),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@@mailinator.com", Age: 20},
+			expectedCodes: []string{ErrorEmailFormat},
+		},
+		{
+			name:          "optional last name",
+			config:        withConfig(func(config *ValidationConfig) { config.RequiredFields = []string{FieldFirstName, FieldEmail} }),
+			user:          User{ID: "1", FirstName: "Cher", Email: "cher@example.com", Age: 20},
+			expectedCodes: nil,
+		},
+		{
+			name:   "valid profile",
+			config: DefaultValidationConfig(),
+			user: User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, Phone: "+4930123456",
+				Address: Address{Line1: "Unter den Linden 1", City: "Berlin", PostalCode: "10117", Country: "DE"}, Locale: "de-DE", TimeZone: "Europe/Berlin"},
+			expectedCodes: nil,
+		},
+		{
+			name:          "phone without country code",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, Phone: "030123456"},
+			expectedCodes: []string{ErrorPhoneFormat},
+		},
+		{
+			name:          "phone too long",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, Phone: "+1234567890123456"},
+			expectedCodes: []string{ErrorPhoneFormat},
+		},
+		{
+			name:          "incomplete address",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, Address: Address{PostalCode: "10117"}},
+			expectedCodes: []string{ErrorAddressRequired, ErrorAddressRequired, ErrorAddressRequired},
+		},
+		{
+			name:   "invalid country and postal code",
+			config: DefaultValidationConfig(),
+			user: User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20,
+				Address: Address{Line1: "1 Main St", City: "Springfield", PostalCode: "12345-67890", Country: "XX"}},
+			expectedCodes: []string{ErrorAddressCountry, ErrorPostalCode},
+		},
+		{
+			name:          "invalid locale",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, Locale: "english"},
+			expectedCodes: []string{ErrorLocaleFormat},
+		},
+		{
+			name:          "unknown time zone",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, TimeZone: "Mars/Olympus_Mons"},
+			expectedCodes: []string{ErrorTimeZoneUnknown},
+		},
+		{
+			name:          "server local time zone",
+			config:        DefaultValidationConfig(),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20, TimeZone: "Local"},
+			expectedCodes: []string{ErrorTimeZoneUnknown},
+		},
+		{
+			name:          "disabled rule",
+			config:        withConfig(func(config *ValidationConfig) { config.Rules = []string{RuleRequiredFields, RuleEmailFormat} }),
+			user:          User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 12},
+			expectedCodes: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validationService, err := NewConfiguredValidationService(test.config)
+			if err != nil {
+				t.Fatalf("NewConfiguredValidationService() error = %v", err)
+			}
+
+			var codes []string
+			for _, validationError := range shared.ValidationErrors(validationService.ValidateUser(test.user)) {
+				codes = append(codes, validationError.Code)
+			}
+
+			if !reflect.DeepEqual(codes, test.expectedCodes) {
+				t.Errorf("ValidateUser() codes = %v, want %v", codes, test.expectedCodes)
+			}
+		})
+	}
+}
+
+func TestNewRules_ErrorDetails(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.Rules = append(config.Rules, RuleNameLength)
+	config.MinAge, config.NameMaxLength = 21, 4
+	validationService, err := NewConfiguredValidationService(config)
+	if err != nil {
+		t.Fatalf("NewConfiguredValidationService() error = %v", err)
+	}
+
+	got := shared.ValidationErrors(validationService.ValidateUser(User{ID: "1", FirstName: "Johnny", Email: "john", Age: 20}))
+
+	expected := []shared.ValidationError{
+		NewAgeMinimumError(20, 21),
+		NewNameRequiredError(FieldLastName),
+		NewEmailFormatError("john"),
+		NewNameLengthError(FieldFirstName, "Johnny", 1, 4),
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("ValidateUser() errors = %+v, want %+v", got, expected)
+	}
+	if got[0].Field != FieldAge || got[0].Value != "20" || got[0].Params["min"] != "21" || got[0].Severity != shared.SeverityError {
+		t.Errorf("age minimum error = %+v", got[0])
+	}
+	if redacted := got[2].Redacted(); redacted.Value != "" || redacted.Field != FieldEmail {
+		t.Errorf("Redacted() = %+v", redacted)
+	}
+}
+
+func TestNewConfiguredValidationService_DefaultRules(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.Rules = nil
+	config.MinAge = 21
+	validationService, err := NewConfiguredValidationService(config)
+	if err != nil {
+		t.Fatalf("NewConfiguredValidationService() error = %v", err)
+	}
+
+	var codes []string
+	for _, validationError := range shared.ValidationErrors(validationService.ValidateUser(
+		User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john.doe@example.com", Age: 20})) {
+		codes = append(codes, validationError.Code)
+	}
+
+	if !reflect.DeepEqual(codes, []string{ErrorAgeMinimum}) {
+		t.Errorf("ValidateUser() codes = %v, want the configured minimum age to be kept", codes)
+	}
+}
+
+func TestNewRules_DateOfBirth(t *testing.T) {
+	config := DefaultValidationConfig()
+	config.Rules = []string{RuleAgeMinimum, RuleAgeMaximum}
+	config.MaxAge = 100
+	config.Now = func() time.Time { return time.Date(2024, 6, 15, 9, 30, 0, 0, time.UTC) }
+	validationService, err := NewConfiguredValidationService(config)
+	if err != nil {
+		t.Fatalf("NewConfiguredValidationService() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		user     User
+		expected []shared.ValidationError
+	}{
+		{
+			name: "18 on the day of onboarding",
+			user: User{DateOfBirth: time.Date(2006, 6, 15, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "18 tomorrow",
+			user: User{DateOfBirth: time.Date(2006, 6, 16, 0, 0, 0, 0, time.UTC), Age: 40},
+			expected: []shared.ValidationError{{
+				Code: ErrorAgeMinimum, Message: "User does not meet minimum age requirement of 18",
+				Field: FieldDateOfBirth, Value: "2006-06-16", Params: map[string]string{"min": "18"}, Severity: shared.SeverityError,
+			}},
+		},
+		{
+			name: "too old",
+			user: User{DateOfBirth: time.Date(1920, 1, 1, 0, 0, 0, 0, time.UTC)},
+			expected: []shared.ValidationError{{
+				Code: ErrorAgeMaximum, Message: "User exceeds the maximum age of 100",
+				Field: FieldDateOfBirth, Value: "1920-01-01", Params: map[string]string{"max": "100"}, Severity: shared.SeverityError,
+			}},
+		},
+		{
+			name:     "legacy age",
+			user:     User{Age: 17},
+			expected: []shared.ValidationError{NewAgeMinimumError(17, 18)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := shared.ValidationErrors(validationService.ValidateUser(test.user))
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("ValidateUser() errors = %+v, want %+v", got, test.expected)
+			}
+		})
+	}
+}
+
+func TestNewRules_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(config *ValidationConfig)
+	}{
+		{name: "unknown rule", change: func(config *ValidationConfig) { config.Rules = []string{"age_between"} }},
+		{name: "rule enabled twice", change: func(config *ValidationConfig) { config.Rules = []string{RuleAgeMinimum, RuleAgeMinimum} }},
+		{name: "invalid email pattern", change: func(config *ValidationConfig) { config.EmailPattern = "(" }},
+		{name: "invalid disposable domain", change: func(config *ValidationConfig) {
+			config.Rules = []string{RuleDisposableEmail}
+			config.DisposableDomains = []string{"bad domain.com"}
+		}},
+		{name: "unknown required field", change: func(config *ValidationConfig) { config.RequiredFields = []string{"nickname"} }},
+		{name: "maximum age below minimum", change: func(config *ValidationConfig) {
+			config.Rules = []string{RuleAgeMaximum}
+			config.MaxAge = 10
+		}},
+		{name: "inverted name bounds", change: func(config *ValidationConfig) {
+			config.Rules = []string{RuleNameLength}
+			config.NameMinLength, config.NameMaxLength = 5, 2
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultValidationConfig()
+			test.change(&config)
+			if _, err := NewRules(config); err == nil {
+				t.Errorf("NewRules() expected an error")
+			}
+		})
+	}
+}
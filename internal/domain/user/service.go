@@ -3,44 +3,62 @@ package user
 import (
 	"errors"
 	"strings"
+	"time"
 )
 
-type validationService struct{}
-
-// NewValidationService creates a new validation service
-func NewValidationService() *validationService {
-	return &validationService{}
+type validationService struct {
+	rules                 []Rule
+	gmailCanonicalization bool
 }
 
-// ValidateUser validates a user
-func (s *validationService) ValidateUser(user User) error {
-	return errors.Join(
-		s.validateAge(user),
-		s.validateEmail(user),
-		s.validateName(user),
-	)
+// NewValidationService creates a new validation service with the DefaultValidationConfig
+func NewValidationService() *validationService {
+	// the default config always builds
+	s, _ := NewConfiguredValidationService(DefaultValidationConfig())
+	return s
 }
 
-func (s *validationService) validateAge(user User) error {
-	if user.Age < 18 {
-		return NewAgeMinimumError()
+// NewConfiguredValidationService creates a new validation service running the rules of the config in order,
+// a config without rules runs the rules of the DefaultValidationConfig with the config's parameters
+func NewConfiguredValidationService(config ValidationConfig) (*validationService, error) {
+	if len(config.Rules) == 0 {
+		config.Rules = DefaultValidationConfig().Rules
+	}
+	rules, err := NewRules(config)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	return &validationService{rules: rules, gmailCanonicalization: config.GmailCanonicalization}, nil
 }
 
-func (s *validationService) validateEmail(user User) error {
-	if user.Email == "" {
-		return NewEmailRequiredError()
+// Normalize normalizes the user's names, trims the user's email, lowercases its domain and sets its canonical form.
+// An invalid email is only trimmed and left to the validation.
+// A date of birth is truncated to its UTC date and replaces the age, which is then computed from it.
+// The phone, address and locale are normalized to the forms they are validated in.
+func (s *validationService) Normalize(user *User) {
+	user.Phone = NormalizePhone(user.Phone)
+	user.Address = NormalizeAddress(user.Address)
+	user.Locale = NormalizeLocale(user.Locale)
+	user.TimeZone = strings.TrimSpace(user.TimeZone)
+	if !user.DateOfBirth.IsZero() {
+		year, month, day := user.DateOfBirth.Date()
+		user.DateOfBirth = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		user.Age = 0
 	}
-	if !strings.Contains(user.Email, "@") {
-		return NewEmailFormatError()
+	user.FirstName = NormalizeName(user.FirstName)
+	user.LastName = NormalizeName(user.LastName)
+	user.Email = strings.TrimSpace(user.Email)
+	user.CanonicalEmail = ""
+	if email, err := ParseEmail(user.Email, s.gmailCanonicalization); err == nil {
+		user.Email, user.CanonicalEmail = email.Display, email.Canonical
 	}
-	return nil
 }
 
-func (s *validationService) validateName(user User) error {
-	if user.FirstName == "" || user.LastName == "" {
-		return NewNameRequiredError()
+// ValidateUser validates a user, aggregating the failures of every rule
+func (s *validationService) ValidateUser(user User) error {
+	errs := make([]error, 0, len(s.rules))
+	for _, rule := range s.rules {
+		errs = append(errs, rule.Check(user))
 	}
-	return nil
+	return errors.Join(errs...)
 }
@@ -31,7 +31,7 @@ func TestValidateUser(t *testing.T) {
 				Email:     "john.doe@example.com",
 				Age:       17,
 			},
-			want: NewAgeMinimumError(),
+			want: NewAgeMinimumError(17, 18),
 		},
 		{
 			name: "user without an email",
@@ -53,7 +53,7 @@ func TestValidateUser(t *testing.T) {
 				Email:     "john.doe",
 				Age:       20,
 			},
-			want: NewEmailFormatError(),
+			want: NewEmailFormatError("john.doe"),
 		},
 		{
 			name: "user with invalid first name",
@@ -64,7 +64,7 @@ func TestValidateUser(t *testing.T) {
 				Email:     "john.doe@example.com",
 				Age:       20,
 			},
-			want: NewNameRequiredError(),
+			want: NewNameRequiredError(FieldFirstName),
 		},
 		{
 			name: "user with invalid last name",
@@ -75,7 +75,7 @@ func TestValidateUser(t *testing.T) {
 				Email:     "john.doe@example.com",
 				Age:       20,
 			},
-			want: NewNameRequiredError(),
+			want: NewNameRequiredError(FieldLastName),
 		},
 	}
 
@@ -0,0 +1,89 @@
+package user
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Status is the onboarding lifecycle status of a user
+type Status string
+
+const (
+	// StatusPending is the status of new users
+	StatusPending Status = "pending"
+	// StatusVerified is the status of users whose identity was verified
+	StatusVerified Status = "verified"
+	// StatusActive is the status of onboarded users, and of users saved before the lifecycle was recorded
+	StatusActive Status = "active"
+	// StatusSuspended is the status of users whose access is suspended until they are activated again
+	StatusSuspended Status = "suspended"
+	// StatusOffboarded is the final status of users who left
+	StatusOffboarded Status = "offboarded"
+)
+
+// ErrInvalidTransition is returned when a user cannot change from its status to another
+var ErrInvalidTransition = errors.New("user status transition is not allowed")
+
+// transitions are the statuses each status may change to, users without a status are created pending
+var transitions = map[Status][]Status{
+	"":              {StatusPending},
+	StatusPending:   {StatusVerified, StatusOffboarded},
+	StatusVerified:  {StatusActive, StatusOffboarded},
+	StatusActive:    {StatusSuspended, StatusOffboarded},
+	StatusSuspended: {StatusActive, StatusOffboarded},
+}
+
+// Statuses returns the statuses in lifecycle order
+func Statuses() []Status {
+	return []Status{StatusPending, StatusVerified, StatusActive, StatusSuspended, StatusOffboarded}
+}
+
+// ParseStatus parses a status name
+func ParseStatus(name string) (Status, error) {
+	if status := Status(name); slices.Contains(Statuses(), status) {
+		return status, nil
+	}
+	return "", fmt.Errorf("user: unknown status %q, use one of %v", name, Statuses())
+}
+
+// CanTransitionTo reports whether a user with the status may change to the target status
+func (s Status) CanTransitionTo(target Status) bool {
+	return slices.Contains(transitions[s], target)
+}
+
+// StatusTransition is a recorded change of the status of a user
+type StatusTransition struct {
+	// From is empty for the transition of a new user to StatusPending
+	From   Status
+	To     Status
+	At     time.Time
+	Actor  string
+	Reason string
+}
+
+// TransitionError is returned when a user cannot change from its status to another, it wraps ErrInvalidTransition
+type TransitionError struct {
+	From Status
+	To   Status
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("user status cannot change from %s to %s", e.From, e.To)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// Transition changes the status of the user to the target status and appends the transition to its history.
+// It returns a TransitionError when the transition is not allowed.
+func (u *User) Transition(target Status, at time.Time, actor string, reason string) error {
+	if !u.Status.CanTransitionTo(target) {
+		return &TransitionError{From: u.Status, To: target}
+	}
+	u.StatusHistory = append(u.StatusHistory, StatusTransition{From: u.Status, To: target, At: at, Actor: actor, Reason: reason})
+	u.Status = target
+	return nil
+}
@@ -0,0 +1,66 @@
+package user
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from     Status
+		to       Status
+		expected bool
+	}{
+		{from: "", to: StatusPending, expected: true},
+		{from: "", to: StatusActive, expected: false},
+		{from: StatusPending, to: StatusVerified, expected: true},
+		{from: StatusPending, to: StatusActive, expected: false},
+		{from: StatusVerified, to: StatusActive, expected: true},
+		{from: StatusActive, to: StatusSuspended, expected: true},
+		{from: StatusSuspended, to: StatusActive, expected: true},
+		{from: StatusSuspended, to: StatusVerified, expected: false},
+		{from: StatusActive, to: StatusOffboarded, expected: true},
+		{from: StatusOffboarded, to: StatusActive, expected: false},
+		{from: StatusActive, to: StatusActive, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.from)+"->"+string(test.to), func(t *testing.T) {
+			if allowed := test.from.CanTransitionTo(test.to); allowed != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, allowed)
+			}
+		})
+	}
+}
+
+func TestParseStatus(t *testing.T) {
+	if status, err := ParseStatus("suspended"); err != nil || status != StatusSuspended {
+		t.Errorf("ParseStatus(suspended) = %q, %v", status, err)
+	}
+	if _, err := ParseStatus("deleted"); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
+
+func TestUser_Transition(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	user := User{ID: "1", Status: StatusPending}
+
+	if err := user.Transition(StatusVerified, at, "admin", "documents checked"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := StatusTransition{From: StatusPending, To: StatusVerified, At: at, Actor: "admin", Reason: "documents checked"}
+	if user.Status != StatusVerified || len(user.StatusHistory) != 1 || user.StatusHistory[0] != expected {
+		t.Errorf("expected status %q with history [%v], got %q with %v", StatusVerified, expected, user.Status, user.StatusHistory)
+	}
+
+	err := user.Transition(StatusSuspended, at, "admin", "")
+	var transitionErr *TransitionError
+	if !errors.Is(err, ErrInvalidTransition) || !errors.As(err, &transitionErr) || transitionErr.From != StatusVerified || transitionErr.To != StatusSuspended {
+		t.Errorf("expected a transition error from verified to suspended, got %v", err)
+	}
+	if user.Status != StatusVerified || len(user.StatusHistory) != 1 {
+		t.Errorf("expected a rejected transition to leave the user unchanged, got %q with %v", user.Status, user.StatusHistory)
+	}
+}
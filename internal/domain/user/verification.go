@@ -0,0 +1,32 @@
+package user
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrEmailMissing is returned when verifying the email of a user without one
+	ErrEmailMissing = errors.New("user has no email to verify")
+	// ErrEmailAlreadyVerified is returned when sending a verification for an email that is verified
+	ErrEmailAlreadyVerified = errors.New("user email is already verified")
+	// ErrEmailChanged is returned when verifying an email that is no longer the email of the user
+	ErrEmailChanged = errors.New("user email changed since the verification was sent")
+)
+
+// EmailVerified reports whether the current email of the user was verified
+func (u User) EmailVerified() bool {
+	return !u.EmailVerifiedAt.IsZero()
+}
+
+// VerifyEmail records that the user received mail sent to email at the time.
+// It returns ErrEmailChanged when email is not the email of the user, verifying a verified email keeps the first time.
+func (u *User) VerifyEmail(email string, at time.Time) error {
+	if email == "" || email != u.Email {
+		return ErrEmailChanged
+	}
+	if !u.EmailVerified() {
+		u.EmailVerifiedAt = at
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package webhook
+
+import (
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// DeliveryStatus is the state of a delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are attempted once NextAttemptAt is reached
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded deliveries were accepted by the endpoint
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryDead deliveries failed every attempt and are no longer retried
+	DeliveryDead DeliveryStatus = "dead"
+)
+
+// Delivery is an event to deliver to a subscription and the log of its attempts
+type Delivery struct {
+	ID             string
+	SubscriptionID string
+	EventID        string
+	EventType      user.EventType
+	// Payload is the request body, it is kept so every attempt sends the same bytes
+	Payload       []byte
+	Status        DeliveryStatus
+	Attempts      []Attempt
+	NextAttemptAt time.Time
+	CreatedAt     time.Time
+}
+
+// Attempt is a single attempt to deliver an event
+type Attempt struct {
+	At time.Time
+	// StatusCode is the response status, zero when no response was received
+	StatusCode int
+	Error      string
+}
+
+// DeliveryID returns the id of the delivery of an event to a subscription.
+// It is derived from both ids so an event relayed more than once is only delivered once.
+func DeliveryID(subscriptionID string, eventID string) string {
+	return subscriptionID + "-" + eventID
+}
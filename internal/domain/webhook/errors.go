@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+const (
+	ErrorURLInvalid     = "WEBHOOK_URL_INVALID"
+	ErrorURLPrivate     = "WEBHOOK_URL_PRIVATE"
+	ErrorEventUnknown   = "WEBHOOK_EVENT_UNKNOWN"
+	ErrorSecretTooShort = "WEBHOOK_SECRET_TOO_SHORT"
+)
+
+// ErrNotFound is returned when a subscription does not exist
+var ErrNotFound = errors.New("webhook subscription not found")
+
+// NewURLInvalidError creates a new url invalid error
+func NewURLInvalidError(url string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorURLInvalid,
+		Message:  "Webhook url must be an absolute http or https url",
+		Field:    "url",
+		Value:    url,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewURLPrivateError creates a new url private error
+func NewURLPrivateError(url string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorURLPrivate,
+		Message:  "Webhook url must not point to a loopback, private or link-local address",
+		Field:    "url",
+		Value:    url,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewEventUnknownError creates a new event unknown error
+func NewEventUnknownError(eventType string) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorEventUnknown,
+		Message:  "Webhook events must be user.created, user.updated or user.deleted",
+		Field:    "events",
+		Value:    eventType,
+		Severity: shared.SeverityError,
+	}
+}
+
+// NewSecretTooShortError creates a new secret too short error, the secret is never recorded
+func NewSecretTooShortError(minLength int) shared.ValidationError {
+	return shared.ValidationError{
+		Code:     ErrorSecretTooShort,
+		Message:  fmt.Sprintf("Webhook secret must be at least %d characters", minLength),
+		Field:    "secret",
+		Params:   map[string]string{"min": strconv.Itoa(minLength)},
+		Severity: shared.SeverityError,
+	}
+}
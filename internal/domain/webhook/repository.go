@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"context"
+	"time"
+)
+
+// SubscriptionRepository stores webhook subscriptions
+type SubscriptionRepository interface {
+	// FindByID finds a subscription by id
+	FindByID(ctx context.Context, id string) (*Subscription, error)
+	// Save saves a subscription
+	Save(ctx context.Context, subscription *Subscription) error
+	// Delete deletes a subscription by id, returning ErrNotFound when there is no such subscription
+	Delete(ctx context.Context, id string) error
+	// List lists the subscriptions ordered by creation time
+	List(ctx context.Context) ([]*Subscription, error)
+}
+
+// DeliveryRepository stores deliveries and their attempts
+type DeliveryRepository interface {
+	// Add adds a delivery, a delivery with the same id is left unchanged
+	Add(ctx context.Context, delivery *Delivery) error
+	// Save replaces a delivery
+	Save(ctx context.Context, delivery *Delivery) error
+	// Due lists up to limit pending deliveries whose next attempt is at or before now, oldest first
+	Due(ctx context.Context, now time.Time, limit int) ([]*Delivery, error)
+	// ListBySubscriptionID lists up to limit deliveries of a subscription, newest first
+	ListBySubscriptionID(ctx context.Context, subscriptionID string, limit int) ([]*Delivery, error)
+}
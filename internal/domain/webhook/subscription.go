@@ -0,0 +1,98 @@
+// Package webhook contains the logic for the Webhook domain.
+package webhook
+
+import (
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// minSecretLength is the shortest secret accepted for signing deliveries
+const minSecretLength = 16
+
+// Subscription is a partner endpoint receiving user events
+type Subscription struct {
+	ID  string
+	URL string
+	// Events are the event types delivered to the subscription, every type when empty
+	Events []user.EventType
+	// Secret signs the deliveries
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Matches reports whether the subscription receives events of the type
+func (s *Subscription) Matches(eventType user.EventType) bool {
+	if len(s.Events) == 0 {
+		return true
+	}
+	for _, subscribed := range s.Events {
+		if subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate validates the subscription, returning every failure joined
+func (s *Subscription) Validate() error {
+	var errs []error
+	if u, err := url.Parse(s.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, NewURLInvalidError(s.URL))
+	} else if !AllowedHost(u.Hostname()) {
+		errs = append(errs, NewURLPrivateError(s.URL))
+	}
+	for _, eventType := range s.Events {
+		if eventType != user.EventUserCreated && eventType != user.EventUserUpdated && eventType != user.EventUserDeleted {
+			errs = append(errs, NewEventUnknownError(string(eventType)))
+			break
+		}
+	}
+	if len(s.Secret) < minSecretLength {
+		errs = append(errs, NewSecretTooShortError(minSecretLength))
+	}
+	return errors.Join(errs...)
+}
+
+// AllowedHost reports whether deliveries may be sent to the host.
+// Host names other than localhost are allowed, their addresses are checked with AllowedAddress when connecting.
+func AllowedHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return AllowedAddress(addr)
+	}
+	return true
+}
+
+// deniedPrefixes are the ranges refused besides the address classes AllowedAddress checks
+var deniedPrefixes = []netip.Prefix{
+	// "this network", not only the unspecified address
+	netip.MustParsePrefix("0.0.0.0/8"),
+	// shared address space of carrier-grade NAT, also used for cloud metadata and internal load balancers
+	netip.MustParsePrefix("100.64.0.0/10"),
+	// benchmarking
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// AllowedAddress reports whether deliveries may be sent to the address,
+// loopback, private, link-local, multicast, unspecified, shared and benchmarking addresses are refused
+func AllowedAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return false
+	}
+	for _, prefix := range deniedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,45 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+// FileMailer drops every message in a directory as an .eml file that mail clients can open
+type FileMailer struct {
+	dir string
+	now func() time.Time
+}
+
+// NewFileMailer creates a mailer writing to dir, which is created on the first message
+func NewFileMailer(dir string) *FileMailer {
+	return &FileMailer{dir: dir, now: time.Now}
+}
+
+func (m *FileMailer) Send(ctx context.Context, message shared.Message) error {
+	now := m.now()
+	data, err := format(message, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("mail: failed to create mail directory: %w", err)
+	}
+	// the time prefix lists the messages in the order they were sent
+	file, err := os.CreateTemp(m.dir, now.UTC().Format("20060102T150405.000Z")+"-*.eml")
+	if err != nil {
+		return fmt.Errorf("mail: failed to create message file: %w", err)
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("mail: failed to write message file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("mail: failed to write message file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+var testMessage = shared.Message{
+	From:    "Onboarding <no-reply@example.com>",
+	To:      "jane@example.com",
+	Subject: "Vérifiez votre e-mail",
+	Body:    "Open https://example.com/verify?token=abc=def\n",
+}
+
+func TestFormat(t *testing.T) {
+	data, err := format(testMessage, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{
+		"From: Onboarding <no-reply@example.com>\r\n",
+		"To: jane@example.com\r\n",
+		"Subject: =?utf-8?q?V=C3=A9rifiez_votre_e-mail?=\r\n",
+		"Date: Wed, 01 May 2024 12:00:00 +0000\r\n",
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n",
+		"token=3Dabc=3Ddef\r\n",
+	} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected the message to contain %q, got %q", expected, data)
+		}
+	}
+
+	injected := testMessage
+	injected.To = "jane@example.com\r\nBcc: everyone@example.com"
+	if _, err := format(injected, time.Now()); err == nil {
+		t.Error("expected an error for a header with a line break")
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer := NewFileMailer(dir)
+	mailer.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	for range 2 {
+		if err := mailer.Send(context.Background(), testMessage); err != nil {
+			t.Fatalf("Send() unexpected error: %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "20240501T120000.000Z-*.eml"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("expected 2 message files, got %v (%v)", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil || !strings.Contains(string(data), "To: jane@example.com\r\n") {
+		t.Errorf("unexpected message file %q (%v)", data, err)
+	}
+}
+
+func TestInMemoryMailer(t *testing.T) {
+	mailer := NewInMemoryMailer()
+	if err := mailer.Send(context.Background(), testMessage); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+	if messages := mailer.Messages(); len(messages) != 1 || messages[0] != testMessage {
+		t.Errorf("expected the sent message, got %v", messages)
+	}
+}
+
+// serveSMTP answers a single SMTP session on listener and sends the commands and data it received
+func serveSMTP(t *testing.T, listener net.Listener, received chan<- []string) {
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Errorf("failed to accept: %v", err)
+		return
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	var lines []string
+	reply("220 localhost ESMTP")
+	inData := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		line = strings.TrimRight(line, "\r\n")
+		lines = append(lines, line)
+		switch {
+		case inData && line == ".":
+			inData = false
+			reply("250 queued")
+		case inData:
+		case strings.HasPrefix(line, "EHLO"):
+			reply("250 localhost")
+		case line == "DATA":
+			inData = true
+			reply("354 go ahead")
+		case line == "QUIT":
+			reply("221 bye")
+			received <- lines
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+	received <- lines
+}
+
+func TestSMTPMailer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go serveSMTP(t, listener, received)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := NewSMTPMailer(SMTPConfig{Addr: listener.Addr().String()}).Send(ctx, testMessage); err != nil {
+		t.Fatalf("Send() unexpected error: %v", err)
+	}
+
+	session := strings.Join(<-received, "\n")
+	for _, expected := range []string{"MAIL FROM:<no-reply@example.com>", "RCPT TO:<jane@example.com>", "To: jane@example.com", "QUIT"} {
+		if !strings.Contains(session, expected) {
+			t.Errorf("expected the session to contain %q, got %q", expected, session)
+		}
+	}
+}
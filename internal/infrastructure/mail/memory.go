@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+// InMemoryMailer keeps the messages it is given instead of sending them
+type InMemoryMailer struct {
+	mu       sync.Mutex
+	messages []shared.Message
+}
+
+// NewInMemoryMailer creates a mailer without messages
+func NewInMemoryMailer() *InMemoryMailer {
+	return &InMemoryMailer{}
+}
+
+func (m *InMemoryMailer) Send(ctx context.Context, message shared.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// Messages returns the messages sent so far in order
+func (m *InMemoryMailer) Messages() []shared.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.messages)
+}
@@ -0,0 +1,41 @@
+// Package mail contains the mailers sending the emails of the application.
+package mail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"strings"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+// format returns the RFC 5322 representation of a message sent at date, with a quoted-printable UTF-8 body
+func format(message shared.Message, date time.Time) ([]byte, error) {
+	for _, header := range []string{message.From, message.To, message.Subject} {
+		// line breaks in a header would let its value add headers
+		if strings.ContainsAny(header, "\r\n") {
+			return nil, errors.New("mail: message headers must not contain line breaks")
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", message.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", message.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	body := quotedprintable.NewWriter(&buf)
+	if _, err := body.Write([]byte(message.Body)); err != nil {
+		return nil, fmt.Errorf("mail: failed to encode message body: %w", err)
+	}
+	if err := body.Close(); err != nil {
+		return nil, fmt.Errorf("mail: failed to encode message body: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,97 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+// SMTPConfig configures the SMTP server messages are sent through
+type SMTPConfig struct {
+	// Addr is the host:port of the server
+	Addr string
+	// Username and Password authenticate with PLAIN auth when Username is set, which requires TLS except on localhost
+	Username string
+	Password string
+}
+
+// SMTPMailer sends messages through an SMTP server, upgrading the connection with STARTTLS when the server offers it
+type SMTPMailer struct {
+	config SMTPConfig
+	now    func() time.Time
+}
+
+// NewSMTPMailer creates a mailer sending through the configured server
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{config: config, now: time.Now}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message shared.Message) error {
+	data, err := format(message, m.now())
+	if err != nil {
+		return err
+	}
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return fmt.Errorf("mail: invalid sender %q: %w", message.From, err)
+	}
+	to, err := mail.ParseAddress(message.To)
+	if err != nil {
+		return fmt.Errorf("mail: invalid recipient %q: %w", message.To, err)
+	}
+	host, _, err := net.SplitHostPort(m.config.Addr)
+	if err != nil {
+		return fmt.Errorf("mail: invalid SMTP address %q: %w", m.config.Addr, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.config.Addr)
+	if err != nil {
+		return fmt.Errorf("mail: failed to connect to SMTP server: %w", err)
+	}
+	// the smtp client does not take a context, the deadline bounds the whole conversation instead
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mail: failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("mail: failed to start TLS: %w", err)
+		}
+	}
+	if m.config.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.config.Username, m.config.Password, host)); err != nil {
+			return fmt.Errorf("mail: failed to authenticate: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("mail: sender rejected: %w", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return fmt.Errorf("mail: recipient rejected: %w", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: failed to send message: %w", err)
+	}
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return fmt.Errorf("mail: failed to send message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("mail: failed to send message: %w", err)
+	}
+	return client.Quit()
+}
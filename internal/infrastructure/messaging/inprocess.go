@@ -0,0 +1,67 @@
+// Package messaging contains the publishers delivering user events to downstream systems.
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// EventHandler handles a user event published in process
+type EventHandler func(ctx context.Context, event *user.Event) error
+
+type subscription struct {
+	handler EventHandler
+	types   map[user.EventType]bool
+}
+
+// InProcessPublisher publishes events to handlers subscribed in the same process
+type InProcessPublisher struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+// NewInProcessPublisher creates a publisher without subscribers
+func NewInProcessPublisher() *InProcessPublisher {
+	return &InProcessPublisher{}
+}
+
+// Subscribe registers a handler for the event types, or for every event when no type is given
+func (p *InProcessPublisher) Subscribe(handler EventHandler, types ...user.EventType) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sub := subscription{handler: handler}
+	if len(types) > 0 {
+		sub.types = make(map[user.EventType]bool, len(types))
+		for _, eventType := range types {
+			sub.types[eventType] = true
+		}
+	}
+	p.subscriptions = append(p.subscriptions, sub)
+}
+
+// Publish calls the subscribed handlers in order.
+// Every handler is called even if one fails, so handlers are called again when the event is redelivered.
+func (p *InProcessPublisher) Publish(ctx context.Context, event *user.Event) error {
+	p.mu.RLock()
+	subscriptions := p.subscriptions
+	p.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range subscriptions {
+		if sub.types != nil && !sub.types[event.Type] {
+			continue
+		}
+		if err := sub.handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("messaging: failed to handle %s event %q: %w", event.Type, event.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// LogPublisher writes events as JSON log lines
+type LogPublisher struct {
+	logger *log.Logger
+}
+
+// NewLogPublisher creates a publisher logging to the logger, the standard logger when nil
+func NewLogPublisher(logger *log.Logger) *LogPublisher {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return &LogPublisher{logger: logger}
+}
+
+type logLine struct {
+	Level      string    `json:"level"`
+	Msg        string    `json:"msg"`
+	EventID    string    `json:"event_id"`
+	Type       string    `json:"type"`
+	UserID     string    `json:"user_id"`
+	Actor      string    `json:"actor"`
+	RequestID  string    `json:"request_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Changed    []string  `json:"changed,omitempty"`
+}
+
+// Publish logs the event without the user's field values, which may be personal data
+func (p *LogPublisher) Publish(ctx context.Context, event *user.Event) error {
+	line := logLine{
+		Level:      "info",
+		Msg:        "user event",
+		EventID:    event.ID,
+		Type:       string(event.Type),
+		UserID:     event.UserID,
+		Actor:      event.Actor,
+		RequestID:  event.RequestID,
+		OccurredAt: event.OccurredAt,
+	}
+	for _, change := range event.Changes {
+		line.Changed = append(line.Changed, change.Field)
+	}
+	b, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("messaging: failed to encode event %q: %w", event.ID, err)
+	}
+	p.logger.Print(string(b))
+	return nil
+}
@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestInProcessPublisher_Publish(t *testing.T) {
+	publisher := NewInProcessPublisher()
+	var all, created []string
+	publisher.Subscribe(func(ctx context.Context, event *user.Event) error {
+		all = append(all, event.ID)
+		return errors.New("handler failed")
+	})
+	publisher.Subscribe(func(ctx context.Context, event *user.Event) error {
+		created = append(created, event.ID)
+		return nil
+	}, user.EventUserCreated)
+
+	err := publisher.Publish(context.Background(), &user.Event{ID: "1", Type: user.EventUserCreated})
+	if err == nil {
+		t.Errorf("Publish() expected the handler error")
+	}
+	if err := publisher.Publish(context.Background(), &user.Event{ID: "2", Type: user.EventUserDeleted}); err == nil {
+		t.Errorf("Publish() expected the handler error")
+	}
+
+	if strings.Join(all, ",") != "1,2" {
+		t.Errorf("unfiltered handler got %v, want [1 2]", all)
+	}
+	if strings.Join(created, ",") != "1" {
+		t.Errorf("created handler got %v, want [1]", created)
+	}
+}
+
+func TestLogPublisher_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := NewLogPublisher(log.New(&buf, "", 0))
+	event := &user.Event{
+		ID: "1", Type: user.EventUserUpdated, UserID: "42",
+		User:    user.User{ID: "42", Email: "john@example.com"},
+		Changes: []user.FieldChange{{Field: user.FieldEmail, Before: "old@example.com", After: "john@example.com"}},
+	}
+
+	if err := publisher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Publish() unexpected error: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, `"type":"user.updated"`) || !strings.Contains(output, `"changed":["email"]`) {
+		t.Errorf("Publish() logged %q", output)
+	}
+	if strings.Contains(output, "john@example.com") {
+		t.Errorf("Publish() logged personal data: %q", output)
+	}
+}
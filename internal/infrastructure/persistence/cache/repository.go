@@ -0,0 +1,210 @@
+// Package cache contains a read-through cache decorating a User repository.
+package cache
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// Config configures the cache
+type Config struct {
+	// Size is the maximum number of cached ids, the least recently used id is evicted beyond it
+	Size int
+	// TTL is how long a found user is cached
+	TTL time.Duration
+	// NegativeTTL is how long a missing id is cached, missing ids are not cached when it is zero
+	NegativeTTL time.Duration
+}
+
+// Stats are the counters of a cache
+type Stats struct {
+	Hits         uint64 `json:"hits"`
+	NegativeHits uint64 `json:"negative_hits"`
+	Misses       uint64 `json:"misses"`
+	Evictions    uint64 `json:"evictions"`
+	Size         int    `json:"size"`
+}
+
+// entry is a cached lookup, user is nil for a missing id
+type entry struct {
+	id        string
+	user      *user.User
+	expiresAt time.Time
+}
+
+type repository struct {
+	user.Repository
+	config Config
+	now    func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// generation is incremented by every write so lookups that started before it do not cache what they read
+	generation uint64
+	// writing counts the uncommitted writes of each id, ids being written are not cached
+	writing map[string]int
+	stats   Stats
+}
+
+// NewRepository creates a repository caching the FindByID lookups of the decorated repository.
+// Writes through it invalidate the cached id, writes made elsewhere are seen once the entry expires.
+// Writes in transactions must go through the Transactor of the cache, so the id is invalidated again once they commit.
+// List, Stream, the name lookups, the duplicate candidates and the tombstones are not cached.
+func NewRepository(decorated user.Repository, config Config) *repository {
+	if config.Size <= 0 {
+		config.Size = 10000
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	return &repository{
+		Repository: decorated,
+		config:     config,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		writing:    make(map[string]int),
+	}
+}
+
+func (r *repository) FindByID(ctx context.Context, id string) (*user.User, error) {
+	r.mu.Lock()
+	if cached, ok := r.lookup(id); ok {
+		r.mu.Unlock()
+		if cached == nil {
+			return nil, fmt.Errorf("cache: failed to find user by ID %q: %w", id, user.ErrNotFound)
+		}
+		found := *cached
+		return &found, nil
+	}
+	r.stats.Misses++
+	generation := r.generation
+	r.mu.Unlock()
+
+	found, err := r.Repository.FindByID(ctx, id)
+	switch {
+	case err == nil:
+		cached := *found
+		r.store(id, &cached, r.config.TTL, generation)
+	case errors.Is(err, user.ErrNotFound) && r.config.NegativeTTL > 0:
+		r.store(id, nil, r.config.NegativeTTL, generation)
+	}
+	return found, err
+}
+
+func (r *repository) Save(ctx context.Context, userToSave *user.User) (*user.User, error) {
+	defer r.write(ctx, userToSave.ID)()
+	return r.Repository.Save(ctx, userToSave)
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	defer r.write(ctx, id)()
+	return r.Repository.Delete(ctx, id)
+}
+
+func (r *repository) Tombstone(ctx context.Context, tombstone *user.Tombstone, version int64) error {
+	defer r.write(ctx, tombstone.ID)()
+	return r.Repository.Tombstone(ctx, tombstone, version)
+}
+
+// Stats returns the counters of the cache
+func (r *repository) Stats() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := r.stats
+	stats.Size = r.lru.Len()
+	return stats
+}
+
+// lookup returns the cached user of an id, a nil user for a cached missing id, and false when the id is not cached.
+// r.mu must be held.
+func (r *repository) lookup(id string) (*user.User, bool) {
+	element, ok := r.entries[id]
+	if !ok {
+		return nil, false
+	}
+	cached := element.Value.(*entry)
+	if !r.now().Before(cached.expiresAt) {
+		r.remove(element)
+		return nil, false
+	}
+	r.lru.MoveToFront(element)
+	if cached.user == nil {
+		r.stats.NegativeHits++
+	} else {
+		r.stats.Hits++
+	}
+	return cached.user, true
+}
+
+// store caches the lookup of an id unless a write happened since the generation it was read at
+func (r *repository) store(id string, found *user.User, ttl time.Duration, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation || r.writing[id] > 0 {
+		return
+	}
+	if element, ok := r.entries[id]; ok {
+		r.remove(element)
+	}
+	r.entries[id] = r.lru.PushFront(&entry{id: id, user: found, expiresAt: r.now().Add(ttl)})
+	for r.lru.Len() > r.config.Size {
+		r.remove(r.lru.Back())
+		r.stats.Evictions++
+	}
+}
+
+// write invalidates an id before it is written and returns the function to call once the write returns.
+// Inside a transaction of the Transactor of the cache the id is only released when the transaction ends,
+// so lookups made before the commit cannot cache the value it replaces.
+func (r *repository) write(ctx context.Context, id string) func() {
+	r.begin(id)
+	if writes, ok := ctx.Value(transactionKey{}).(*transactionWrites); ok {
+		writes.add(id)
+		return func() {}
+	}
+	return func() { r.end(id) }
+}
+
+// begin invalidates an id and keeps it from being cached until end is called
+func (r *repository) begin(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.writing[id]++
+	r.invalidate(id)
+}
+
+// end invalidates an id once its write is committed or rolled back
+func (r *repository) end(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.writing[id]--; r.writing[id] <= 0 {
+		delete(r.writing, id)
+	}
+	r.invalidate(id)
+}
+
+// invalidate removes the cached entry of an id and keeps lookups in flight from caching it, r.mu must be held
+func (r *repository) invalidate(id string) {
+	r.generation++
+	if element, ok := r.entries[id]; ok {
+		r.remove(element)
+	}
+}
+
+// remove removes a cached entry, r.mu must be held
+func (r *repository) remove(element *list.Element) {
+	r.lru.Remove(element)
+	delete(r.entries, element.Value.(*entry).id)
+}
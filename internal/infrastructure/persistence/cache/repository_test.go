@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type mockRepository struct {
+	user.Repository
+	FindByIDFunc  func(ctx context.Context, id string) (*user.User, error)
+	SaveFunc      func(ctx context.Context, user *user.User) (*user.User, error)
+	DeleteFunc    func(ctx context.Context, id string) error
+	TombstoneFunc func(ctx context.Context, tombstone *user.Tombstone, version int64) error
+}
+
+func (m *mockRepository) FindByID(ctx context.Context, id string) (*user.User, error) {
+	return m.FindByIDFunc(ctx, id)
+}
+func (m *mockRepository) Save(ctx context.Context, user *user.User) (*user.User, error) {
+	return m.SaveFunc(ctx, user)
+}
+func (m *mockRepository) Delete(ctx context.Context, id string) error {
+	return m.DeleteFunc(ctx, id)
+}
+func (m *mockRepository) Tombstone(ctx context.Context, tombstone *user.Tombstone, version int64) error {
+	return m.TombstoneFunc(ctx, tombstone, version)
+}
+
+// newCountingRepository creates a mock repository over users that counts the FindByID calls
+func newCountingRepository(users map[string]*user.User, finds *int) *mockRepository {
+	return &mockRepository{
+		FindByIDFunc: func(ctx context.Context, id string) (*user.User, error) {
+			*finds++
+			found, ok := users[id]
+			if !ok {
+				return nil, user.ErrNotFound
+			}
+			copied := *found
+			return &copied, nil
+		},
+		SaveFunc: func(ctx context.Context, userToSave *user.User) (*user.User, error) {
+			users[userToSave.ID] = userToSave
+			return userToSave, nil
+		},
+		DeleteFunc: func(ctx context.Context, id string) error {
+			delete(users, id)
+			return nil
+		},
+		TombstoneFunc: func(ctx context.Context, tombstone *user.Tombstone, version int64) error {
+			delete(users, tombstone.ID)
+			return nil
+		},
+	}
+}
+
+func TestRepository_FindByID(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	users := map[string]*user.User{"1": {ID: "1", FirstName: "John"}, "2": {ID: "2", FirstName: "Jane"}}
+	finds := 0
+	repository := NewRepository(newCountingRepository(users, &finds), Config{Size: 1, TTL: time.Minute, NegativeTTL: 10 * time.Second})
+	repository.now = func() time.Time { return now }
+
+	steps := []struct {
+		name          string
+		do            func()
+		id            string
+		expectedName  string
+		expectedFinds int
+	}{
+		{name: "miss loads the user", id: "1", expectedName: "John", expectedFinds: 1},
+		{name: "hit is served from the cache", id: "1", expectedName: "John", expectedFinds: 1},
+		{name: "expired entry is reloaded", do: func() { now = now.Add(time.Minute) }, id: "1", expectedName: "John", expectedFinds: 2},
+		{name: "save invalidates", do: func() { repository.Save(ctx, &user.User{ID: "1", FirstName: "Johnny"}) }, id: "1", expectedName: "Johnny", expectedFinds: 3},
+		{name: "least recently used is evicted", do: func() { repository.FindByID(ctx, "2") }, id: "1", expectedName: "Johnny", expectedFinds: 5},
+		{name: "missing id is loaded", id: "3", expectedFinds: 6},
+		{name: "missing id is negatively cached", id: "3", expectedFinds: 6},
+		{name: "negative entry expires", do: func() { now = now.Add(10 * time.Second) }, id: "3", expectedFinds: 7},
+		{name: "delete invalidates", do: func() { repository.FindByID(ctx, "2"); repository.Delete(ctx, "2") }, id: "2", expectedFinds: 9},
+		{name: "tombstone invalidates", do: func() {
+			repository.FindByID(ctx, "1")
+			repository.Tombstone(ctx, &user.Tombstone{ID: "1", MergedInto: "2"}, 0)
+		}, id: "1", expectedFinds: 11},
+	}
+
+	for _, step := range steps {
+		if step.do != nil {
+			step.do()
+		}
+		found, err := repository.FindByID(ctx, step.id)
+		if step.expectedName == "" {
+			if !errors.Is(err, user.ErrNotFound) {
+				t.Errorf("%s: FindByID() error = %v, want ErrNotFound", step.name, err)
+			}
+		} else if err != nil || found.FirstName != step.expectedName {
+			t.Errorf("%s: FindByID() = %+v, %v, want %s", step.name, found, err, step.expectedName)
+		}
+		if finds != step.expectedFinds {
+			t.Errorf("%s: decorated FindByID called %d times, want %d", step.name, finds, step.expectedFinds)
+		}
+	}
+
+	stats := repository.Stats()
+	if stats.Hits != 1 || stats.NegativeHits != 1 || stats.Misses != 11 || stats.Evictions != 5 || stats.Size != 1 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestRepository_FindByID_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	finds := 0
+	repository := NewRepository(newCountingRepository(map[string]*user.User{"1": {ID: "1", FirstName: "John"}}, &finds), Config{})
+
+	found, _ := repository.FindByID(ctx, "1")
+	found.FirstName = "Changed"
+	found, _ = repository.FindByID(ctx, "1")
+
+	if found.FirstName != "John" {
+		t.Errorf("FindByID() = %+v, want the cached user unchanged by callers", found)
+	}
+}
+
+type mockTransactor struct {
+	WithinTransactionFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return m.WithinTransactionFunc(ctx, fn)
+}
+
+func TestTransactor_InvalidatesAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	committed := map[string]*user.User{"1": {ID: "1", FirstName: "John"}}
+	staged := map[string]*user.User{}
+	finds := 0
+	// saves are staged until the transaction commits, lookups see the committed users only
+	decorated := newCountingRepository(committed, &finds)
+	decorated.SaveFunc = func(ctx context.Context, userToSave *user.User) (*user.User, error) {
+		staged[userToSave.ID] = userToSave
+		return userToSave, nil
+	}
+	repository := NewRepository(decorated, Config{})
+	transactor := NewTransactor(&mockTransactor{
+		WithinTransactionFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+			if err := fn(ctx); err != nil {
+				return err
+			}
+			for id, stagedUser := range staged {
+				committed[id] = stagedUser
+			}
+			return nil
+		},
+	}, repository)
+
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := repository.Save(ctx, &user.User{ID: "1", FirstName: "Johnny"}); err != nil {
+			return err
+		}
+		// a concurrent lookup before the commit reads the user being replaced
+		found, err := repository.FindByID(context.Background(), "1")
+		if err != nil || found.FirstName != "John" {
+			t.Errorf("FindByID() before commit = %+v, %v, want John", found, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTransaction() error = %v", err)
+	}
+
+	found, err := repository.FindByID(ctx, "1")
+	if err != nil || found.FirstName != "Johnny" {
+		t.Errorf("FindByID() after commit = %+v, %v, want Johnny", found, err)
+	}
+}
@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+// transactionKey is the context key of the writes of the current transaction
+type transactionKey struct{}
+
+// transactionWrites are the ids written through the cache in a transaction
+type transactionWrites struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (w *transactionWrites) add(id string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ids = append(w.ids, id)
+}
+
+type transactor struct {
+	decorated  shared.Transactor
+	repository *repository
+}
+
+// NewTransactor creates a transactor invalidating the ids written through the repository once the decorated
+// transaction ends, whether it commits or rolls back
+func NewTransactor(decorated shared.Transactor, repository *repository) shared.Transactor {
+	return &transactor{decorated: decorated, repository: repository}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// a nested transaction commits with the outer one
+	if _, ok := ctx.Value(transactionKey{}).(*transactionWrites); ok {
+		return t.decorated.WithinTransaction(ctx, fn)
+	}
+	writes := &transactionWrites{}
+	defer func() {
+		for _, id := range writes.ids {
+			t.repository.end(id)
+		}
+	}()
+	return t.decorated.WithinTransaction(context.WithValue(ctx, transactionKey{}, writes), fn)
+}
@@ -0,0 +1,49 @@
+package inmemory
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type auditRepository struct {
+	mu      sync.RWMutex
+	records map[string][]*user.AuditRecord
+}
+
+// NewAuditRepository creates a new audit repository in memory
+func NewAuditRepository() user.AuditRepository {
+	return &auditRepository{
+		records: make(map[string][]*user.AuditRecord),
+	}
+}
+
+// snapshot copies the map of records, appending to a slice does not change the copied length
+func (r *auditRepository) snapshot() (restore func()) {
+	r.mu.RLock()
+	records := maps.Clone(r.records)
+	r.mu.RUnlock()
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.records = records
+	}
+}
+
+func (r *auditRepository) Append(ctx context.Context, record *user.AuditRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[record.UserID] = append(r.records[record.UserID], record)
+	return nil
+}
+
+func (r *auditRepository) ListByUserID(ctx context.Context, userID string) ([]*user.AuditRecord, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// records are appended in time order, the slice is copied so callers cannot see later appends
+	return append([]*user.AuditRecord(nil), r.records[userID]...), nil
+}
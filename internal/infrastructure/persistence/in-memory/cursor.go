@@ -0,0 +1,41 @@
+package inmemory
+
+import (
+	"context"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// cursor iterates over a snapshot of users
+type cursor struct {
+	users []*user.User
+	index int
+	err   error
+}
+
+func (c *cursor) Next(ctx context.Context) bool {
+	if c.err = ctx.Err(); c.err != nil {
+		return false
+	}
+	if c.index+1 >= len(c.users) {
+		return false
+	}
+	c.index++
+	return true
+}
+
+func (c *cursor) User() *user.User {
+	if c.index < 0 || c.index >= len(c.users) {
+		return nil
+	}
+	return c.users[c.index]
+}
+
+func (c *cursor) Err() error {
+	return c.err
+}
+
+func (c *cursor) Close(ctx context.Context) error {
+	c.users = nil
+	return nil
+}
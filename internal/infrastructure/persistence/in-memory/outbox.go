@@ -0,0 +1,68 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type outbox struct {
+	mu     sync.Mutex
+	events []*user.Event
+}
+
+// NewOutbox creates a new outbox in memory, delivered events are dropped
+func NewOutbox() user.Outbox {
+	return &outbox{}
+}
+
+// snapshot remembers the pending events, restore drops the events added since.
+// Events delivered in the meantime stay delivered.
+func (o *outbox) snapshot() (restore func()) {
+	o.mu.Lock()
+	pending := make(map[string]bool, len(o.events))
+	for _, event := range o.events {
+		pending[event.ID] = true
+	}
+	o.mu.Unlock()
+	return func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.events = slices.DeleteFunc(o.events, func(event *user.Event) bool { return !pending[event.ID] })
+	}
+}
+
+func (o *outbox) Add(ctx context.Context, event *user.Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.events = append(o.events, event)
+	return nil
+}
+
+func (o *outbox) Pending(ctx context.Context, limit int) ([]*user.Event, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// events are added in the order they occurred
+	if limit > 0 && len(o.events) > limit {
+		return append([]*user.Event(nil), o.events[:limit]...), nil
+	}
+	return append([]*user.Event(nil), o.events...), nil
+}
+
+func (o *outbox) MarkDelivered(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for i, event := range o.events {
+		if event.ID == id {
+			o.events = append(o.events[:i], o.events[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("inmemory: failed to mark event %q delivered: event is not pending", id)
+}
@@ -0,0 +1,50 @@
+package inmemory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// snapshotter is a store that can be put back to an earlier state
+type snapshotter interface {
+	// snapshot saves the current state, calling restore puts it back
+	snapshot() (restore func())
+}
+
+type transactor struct {
+	mu     sync.Mutex
+	stores []snapshotter
+}
+
+// NewTransactor creates a transactor for the in-memory stores of this package.
+// Transactions are serialized and the stores are restored when the function fails, so a change, its audit record
+// and its event are stored together or not at all. Reads outside a transaction may see writes that are rolled back.
+func NewTransactor(repository user.Repository, auditRepository user.AuditRepository, outbox user.Outbox) shared.Transactor {
+	t := &transactor{}
+	for _, store := range []any{repository, auditRepository, outbox} {
+		if store, ok := store.(snapshotter); ok {
+			t.stores = append(t.stores, store)
+		}
+	}
+	return t
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	restores := make([]func(), 0, len(t.stores))
+	for _, store := range t.stores {
+		restores = append(restores, store.snapshot())
+	}
+	if err := fn(ctx); err != nil {
+		for _, restore := range restores {
+			restore()
+		}
+		return err
+	}
+	return nil
+}
@@ -4,37 +4,122 @@ package inmemory
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
 
 	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
 )
 
 type repository struct {
-	users map[string]*user.User
+	mu         sync.RWMutex
+	users      map[string]*user.User
+	tombstones map[string]*user.Tombstone
 }
 
 // NewRepository creates a new repository in memory
 func NewRepository() user.Repository {
 	return &repository{
-		users: make(map[string]*user.User),
+		users:      make(map[string]*user.User),
+		tombstones: make(map[string]*user.Tombstone),
+	}
+}
+
+// snapshot copies the maps of users and tombstones, the stored values are replaced rather than changed
+func (r *repository) snapshot() (restore func()) {
+	r.mu.RLock()
+	users, tombstones := maps.Clone(r.users), maps.Clone(r.tombstones)
+	r.mu.RUnlock()
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.users, r.tombstones = users, tombstones
 	}
 }
 
 func (r *repository) FindByID(ctx context.Context, id string) (*user.User, error) {
-	user, ok := r.users[id]
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	foundUser, ok := r.users[id]
 	if !ok {
-		return nil, fmt.Errorf("user not found in memory")
+		return nil, fmt.Errorf("inmemory: failed to find user by ID %q: %w", id, user.ErrNotFound)
 	}
-	return user, nil
+	return foundUser, nil
 }
 
-func (r *repository) Save(ctx context.Context, user *user.User) (*user.User, error) {
-	r.users[user.ID] = user
-	return user, nil
+func (r *repository) Save(ctx context.Context, userToSave *user.User) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var storedVersion int64
+	if storedUser, ok := r.users[userToSave.ID]; ok {
+		storedVersion = storedUser.Version
+	}
+	if userToSave.Version != storedVersion {
+		return nil, fmt.Errorf("inmemory: failed to save user %q at version %d, stored version is %d: %w",
+			userToSave.ID, userToSave.Version, storedVersion, user.ErrVersionConflict)
+	}
+	// the names are unique like the name key index of MongoDB makes them
+	key := user.NameKey(userToSave.FirstName, userToSave.LastName)
+	for _, existingUser := range r.users {
+		if existingUser.ID != userToSave.ID && user.NameKey(existingUser.FirstName, existingUser.LastName) == key {
+			return nil, fmt.Errorf("inmemory: failed to save user %q: %w", userToSave.ID, user.ErrNameCombinationExists)
+		}
+	}
+	savedUser := *userToSave
+	// the history is copied so transitions appended by the caller do not change the stored user
+	savedUser.StatusHistory = slices.Clone(userToSave.StatusHistory)
+	savedUser.Version++
+	r.users[userToSave.ID] = &savedUser
+	return &savedUser, nil
 }
 
-func (r *repository) ExistsByFirstNameAndLastName(ctx context.Context, firstName string, lastName string) bool {
+func (r *repository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[id]; !ok {
+		return fmt.Errorf("inmemory: failed to delete user by ID %q: %w", id, user.ErrNotFound)
+	}
+	delete(r.users, id)
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, filter user.ListFilter) ([]*user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []*user.User
 	for _, user := range r.users {
-		if user.FirstName == firstName && user.LastName == lastName {
+		if filter.Matches(user) && (filter.AfterID == "" || user.ID > filter.AfterID) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if filter.Limit > 0 && len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+	return users, nil
+}
+
+func (r *repository) Stream(ctx context.Context, filter user.ListFilter) (user.Cursor, error) {
+	// the users are snapshotted so the cursor does not hold the lock while it is iterated
+	users, err := r.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &cursor{users: users, index: -1}, nil
+}
+
+func (r *repository) ExistsByFirstNameAndLastName(ctx context.Context, firstName string, lastName string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := user.NameKey(firstName, lastName)
+	for _, existingUser := range r.users {
+		if user.NameKey(existingUser.FirstName, existingUser.LastName) == key {
 			return true
 		}
 	}
@@ -42,10 +127,71 @@ func (r *repository) ExistsByFirstNameAndLastName(ctx context.Context, firstName
 }
 
 func (r *repository) ExistsByFirstNameAndLastNameAndIDNot(ctx context.Context, firstName string, lastName string, id string) bool {
-	for _, user := range r.users {
-		if user.FirstName == firstName && user.LastName == lastName && user.ID != id {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := user.NameKey(firstName, lastName)
+	for _, existingUser := range r.users {
+		if existingUser.ID != id && user.NameKey(existingUser.FirstName, existingUser.LastName) == key {
 			return true
 		}
 	}
 	return false
 }
+
+func (r *repository) ListDuplicateCandidates(ctx context.Context, userToMatch *user.User, limit int) ([]*user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	firstName, lastName := user.FoldName(userToMatch.FirstName), user.FoldName(userToMatch.LastName)
+	var emailMatches, nameMatches []*user.User
+	for _, existingUser := range r.users {
+		if userToMatch.ID != "" && existingUser.ID == userToMatch.ID {
+			continue
+		}
+		switch {
+		case userToMatch.CanonicalEmail != "" && existingUser.CanonicalEmail == userToMatch.CanonicalEmail:
+			emailMatches = append(emailMatches, existingUser)
+		case firstName != "" && user.FoldName(existingUser.FirstName) == firstName,
+			lastName != "" && user.FoldName(existingUser.LastName) == lastName:
+			nameMatches = append(nameMatches, existingUser)
+		}
+	}
+	sort.Slice(emailMatches, func(i, j int) bool { return emailMatches[i].ID < emailMatches[j].ID })
+	sort.Slice(nameMatches, func(i, j int) bool { return nameMatches[i].ID < nameMatches[j].ID })
+	// email matches are never cut off by the limit
+	if limit > 0 {
+		nameMatches = nameMatches[:max(0, min(len(nameMatches), limit-len(emailMatches)))]
+	}
+	return append(emailMatches, nameMatches...), nil
+}
+
+func (r *repository) Tombstone(ctx context.Context, tombstone *user.Tombstone, version int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	storedUser, ok := r.users[tombstone.ID]
+	if !ok {
+		return fmt.Errorf("inmemory: failed to tombstone user by ID %q: %w", tombstone.ID, user.ErrNotFound)
+	}
+	if storedUser.Version != version {
+		return fmt.Errorf("inmemory: failed to tombstone user %q at version %d, stored version is %d: %w",
+			tombstone.ID, version, storedUser.Version, user.ErrVersionConflict)
+	}
+	delete(r.users, tombstone.ID)
+	stored := *tombstone
+	r.tombstones[tombstone.ID] = &stored
+	return nil
+}
+
+func (r *repository) FindTombstone(ctx context.Context, id string) (*user.Tombstone, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tombstone, ok := r.tombstones[id]
+	if !ok {
+		return nil, fmt.Errorf("inmemory: failed to find tombstone by ID %q: %w", id, user.ErrNotFound)
+	}
+	found := *tombstone
+	return &found, nil
+}
@@ -2,9 +2,13 @@ package inmemory
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
 )
 
 func TestRepository_FindByID(t *testing.T) {
@@ -90,29 +94,64 @@ func TestRepository_Save(t *testing.T) {
 		name          string
 		existingUsers map[string]*user.User
 		userToSave    *user.User
-		expectedError bool
+		expectedError error
 	}{
 		{
 			name:          "save new user",
 			existingUsers: map[string]*user.User{},
 			userToSave:    &user.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
-			expectedError: false,
 		},
 		{
 			name: "save user with existing ID (overwrite)",
 			existingUsers: map[string]*user.User{
 				"1": {ID: "1", FirstName: "Old", LastName: "Name", Email: "old@example.com", Age: 20},
 			},
-			userToSave:    &user.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
-			expectedError: false,
+			userToSave: &user.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
 		},
 		{
 			name: "save user to non-empty repository",
 			existingUsers: map[string]*user.User{
 				"1": {ID: "1", FirstName: "Jane", LastName: "Smith", Email: "jane@example.com", Age: 30},
 			},
-			userToSave:    &user.User{ID: "2", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
-			expectedError: false,
+			userToSave: &user.User{ID: "2", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+		},
+		{
+			name: "save user at the stored version",
+			existingUsers: map[string]*user.User{
+				"1": {ID: "1", FirstName: "Old", LastName: "Name", Email: "old@example.com", Age: 20, Version: 2},
+			},
+			userToSave: &user.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 2},
+		},
+		{
+			name: "save user at a stale version",
+			existingUsers: map[string]*user.User{
+				"1": {ID: "1", FirstName: "Old", LastName: "Name", Email: "old@example.com", Age: 20, Version: 2},
+			},
+			userToSave:    &user.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 1},
+			expectedError: user.ErrVersionConflict,
+		},
+		{
+			name:          "save new user with a profile",
+			existingUsers: map[string]*user.User{},
+			userToSave: &user.User{ID: "1", FirstName: "John", LastName: "Doe", Phone: "+4930123456", Locale: "de-DE", TimeZone: "Europe/Berlin",
+				Address:       user.Address{Line1: "Unter den Linden 1", City: "Berlin", Country: "DE"},
+				Status:        user.StatusPending,
+				StatusHistory: []user.StatusTransition{{To: user.StatusPending, At: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Actor: "system"}},
+				CreatedAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+		},
+		{
+			name: "save user with the names of another user",
+			existingUsers: map[string]*user.User{
+				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 1},
+			},
+			userToSave:    &user.User{ID: "2", FirstName: "JOHN", LastName: "Doe", Email: "johnny@example.com", Age: 25},
+			expectedError: user.ErrNameCombinationExists,
+		},
+		{
+			name:          "save new user with a version",
+			existingUsers: map[string]*user.User{},
+			userToSave:    &user.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 1},
+			expectedError: user.ErrVersionConflict,
 		},
 	}
 
@@ -122,19 +161,27 @@ func TestRepository_Save(t *testing.T) {
 
 			savedUser, err := repo.Save(context.Background(), tt.userToSave)
 
-			if tt.expectedError && err == nil {
-				t.Errorf("Save() expected error, got nil")
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("Save() error = %v, want %v", err, tt.expectedError)
 			}
-			if !tt.expectedError && err != nil {
-				t.Errorf("Save() unexpected error: %v", err)
+			if tt.expectedError != nil {
+				return
 			}
 
 			if savedUser == nil {
-				t.Errorf("Save() saved user is nil")
+				t.Fatalf("Save() saved user is nil")
 			}
 			if savedUser.ID != tt.userToSave.ID {
 				t.Errorf("Save() saved user ID = %v, want %v", savedUser.ID, tt.userToSave.ID)
 			}
+			if savedUser.Version != tt.userToSave.Version+1 {
+				t.Errorf("Save() saved user version = %d, want %d", savedUser.Version, tt.userToSave.Version+1)
+			}
+			expected := *tt.userToSave
+			expected.Version++
+			if foundUser, _ := repo.FindByID(context.Background(), tt.userToSave.ID); !reflect.DeepEqual(*foundUser, expected) {
+				t.Errorf("FindByID() after Save() = %+v, want %+v", foundUser, expected)
+			}
 		})
 	}
 }
@@ -192,13 +239,22 @@ func TestRepository_ExistsByFirstNameAndLastName(t *testing.T) {
 			expected:      false,
 		},
 		{
-			name: "case sensitive match",
+			name: "case insensitive match",
 			existingUsers: map[string]*user.User{
 				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
 			},
 			firstName: "john",
-			lastName:  "doe",
-			expected:  false,
+			lastName:  "DOE",
+			expected:  true,
+		},
+		{
+			name: "match ignoring whitespace and Unicode composition",
+			existingUsers: map[string]*user.User{
+				"1": {ID: "1", FirstName: "Jos\u00e9 Luis", LastName: "Doe", Email: "jose@example.com", Age: 25},
+			},
+			firstName: " Jose\u0301  Luis ",
+			lastName:  "Doe",
+			expected:  true,
 		},
 		{
 			name: "multiple users with same name",
@@ -286,14 +342,14 @@ func TestRepository_ExistsByFirstNameAndLastNameAndIDNot(t *testing.T) {
 			expected:  true,
 		},
 		{
-			name: "case sensitive match",
+			name: "case insensitive match",
 			existingUsers: map[string]*user.User{
 				"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
 			},
 			firstName: "john",
 			lastName:  "doe",
 			excludeID: "2",
-			expected:  false,
+			expected:  true,
 		},
 	}
 
@@ -310,6 +366,67 @@ func TestRepository_ExistsByFirstNameAndLastNameAndIDNot(t *testing.T) {
 	}
 }
 
+func TestRepository_ListDuplicateCandidates(t *testing.T) {
+	existingUsers := map[string]*user.User{
+		"1": {ID: "1", FirstName: "John", LastName: "Doe", CanonicalEmail: "john@example.com"},
+		"2": {ID: "2", FirstName: "Jane", LastName: "DOE", CanonicalEmail: "jane@example.com"},
+		"3": {ID: "3", FirstName: "Max", LastName: "Mustermann", CanonicalEmail: "jon@example.com"},
+		"4": {ID: "4", FirstName: "Erika", LastName: "Mustermann", CanonicalEmail: "erika@example.com"},
+		"5": {ID: "5", FirstName: " jon ", LastName: "Smith", CanonicalEmail: "smith@example.com"},
+	}
+	tests := []struct {
+		name        string
+		user        *user.User
+		limit       int
+		expectedIDs []string
+	}{
+		{
+			name:        "same canonical email then same folded first or last name",
+			user:        &user.User{FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com"},
+			expectedIDs: []string{"3", "1", "2", "5"},
+		},
+		{
+			name:        "the user is not its own candidate",
+			user:        &user.User{ID: "1", FirstName: "John", LastName: "Doe", CanonicalEmail: "john@example.com"},
+			expectedIDs: []string{"2"},
+		},
+		{
+			name:        "limit",
+			user:        &user.User{FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com"},
+			limit:       2,
+			expectedIDs: []string{"3", "1"},
+		},
+		{
+			name:        "email matches beyond the limit",
+			user:        &user.User{FirstName: "Jon", LastName: "Doe", CanonicalEmail: "jon@example.com"},
+			limit:       1,
+			expectedIDs: []string{"3"},
+		},
+		{
+			name: "no candidates",
+			user: &user.User{FirstName: "Ada", LastName: "Lovelace", CanonicalEmail: "ada@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository{users: existingUsers}
+
+			candidates, err := repo.ListDuplicateCandidates(context.Background(), tt.user, tt.limit)
+			if err != nil {
+				t.Fatalf("ListDuplicateCandidates() error = %v", err)
+			}
+			var ids []string
+			for _, candidate := range candidates {
+				ids = append(ids, candidate.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.expectedIDs) {
+				t.Errorf("ListDuplicateCandidates() = %v, want %v", ids, tt.expectedIDs)
+			}
+		})
+	}
+}
+
 func TestRepository_EdgeCases(t *testing.T) {
 	repo := NewRepository()
 
@@ -356,3 +473,236 @@ func TestRepository_EdgeCases(t *testing.T) {
 		}
 	})
 }
+
+func TestRepository_List(t *testing.T) {
+	existingUsers := map[string]*user.User{
+		"1": {ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+		"2": {ID: "2", FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 30},
+		"3": {ID: "3", FirstName: "John", LastName: "Smith", Email: "smith@example.com", Age: 40},
+	}
+	tests := []struct {
+		name        string
+		filter      user.ListFilter
+		expectedIDs []string
+	}{
+		{name: "no filter", filter: user.ListFilter{}, expectedIDs: []string{"1", "2", "3"}},
+		{name: "filter by first name", filter: user.ListFilter{FirstName: "John"}, expectedIDs: []string{"1", "3"}},
+		{name: "filter by last name and email", filter: user.ListFilter{LastName: "Doe", Email: "jane@example.com"}, expectedIDs: []string{"2"}},
+		{name: "after id", filter: user.ListFilter{AfterID: "1"}, expectedIDs: []string{"2", "3"}},
+		{name: "limit", filter: user.ListFilter{Limit: 2}, expectedIDs: []string{"1", "2"}},
+		{name: "no match", filter: user.ListFilter{FirstName: "Nobody"}, expectedIDs: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repository{users: existingUsers}
+
+			users, err := repo.List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("List() unexpected error: %v", err)
+			}
+
+			var ids []string
+			for _, user := range users {
+				ids = append(ids, user.ID)
+			}
+			if len(ids) != len(tt.expectedIDs) {
+				t.Fatalf("List() ids = %v, want %v", ids, tt.expectedIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.expectedIDs[i] {
+					t.Errorf("List() ids = %v, want %v", ids, tt.expectedIDs)
+				}
+			}
+		})
+	}
+}
+
+func TestRepository_Stream(t *testing.T) {
+	repo := &repository{users: map[string]*user.User{
+		"2": {ID: "2", FirstName: "Jane", LastName: "Doe"},
+		"1": {ID: "1", FirstName: "John", LastName: "Doe"},
+		"3": {ID: "3", FirstName: "John", LastName: "Smith"},
+	}}
+	ctx := context.Background()
+
+	cursor, err := repo.Stream(ctx, user.ListFilter{LastName: "Doe"})
+	if err != nil {
+		t.Fatalf("Stream() unexpected error: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var ids []string
+	for cursor.Next(ctx) {
+		ids = append(ids, cursor.User().ID)
+	}
+	if cursor.Err() != nil {
+		t.Errorf("Stream() unexpected cursor error: %v", cursor.Err())
+	}
+	if len(ids) != 2 || ids[0] != "1" || ids[1] != "2" {
+		t.Errorf("Stream() ids = %v, want [1 2]", ids)
+	}
+
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	cursor, _ = repo.Stream(ctx, user.ListFilter{})
+	if cursor.Next(canceledCtx) || cursor.Err() == nil {
+		t.Errorf("Stream() expected a canceled context to stop the cursor")
+	}
+}
+
+func TestAuditRepository_ListByUserID(t *testing.T) {
+	ctx := context.Background()
+	repo := NewAuditRepository()
+	for _, record := range []*user.AuditRecord{
+		{ID: "1", UserID: "1", Action: user.AuditActionCreate},
+		{ID: "2", UserID: "2", Action: user.AuditActionCreate},
+		{ID: "3", UserID: "1", Action: user.AuditActionUpdate},
+	} {
+		if err := repo.Append(ctx, record); err != nil {
+			t.Fatalf("Append() unexpected error: %v", err)
+		}
+	}
+
+	records, err := repo.ListByUserID(ctx, "1")
+	if err != nil {
+		t.Fatalf("ListByUserID() unexpected error: %v", err)
+	}
+	if len(records) != 2 || records[0].ID != "1" || records[1].ID != "3" {
+		t.Errorf("ListByUserID() = %v, want records 1 and 3", records)
+	}
+}
+
+func TestOutbox_PendingAndMarkDelivered(t *testing.T) {
+	ctx := context.Background()
+	outbox := NewOutbox()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := outbox.Add(ctx, &user.Event{ID: id, Type: user.EventUserCreated}); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+
+	pending, err := outbox.Pending(ctx, 2)
+	if err != nil || len(pending) != 2 || pending[0].ID != "1" {
+		t.Fatalf("Pending() = %v, %v, want events 1 and 2", pending, err)
+	}
+	if err := outbox.MarkDelivered(ctx, "1"); err != nil {
+		t.Fatalf("MarkDelivered() unexpected error: %v", err)
+	}
+	if err := outbox.MarkDelivered(ctx, "1"); err == nil {
+		t.Errorf("MarkDelivered() delivered event expected error")
+	}
+
+	pending, _ = outbox.Pending(ctx, 10)
+	if len(pending) != 2 || pending[0].ID != "2" || pending[1].ID != "3" {
+		t.Errorf("Pending() after delivery = %v, want events 2 and 3", pending)
+	}
+}
+
+func TestTransactor_RollsBackFailedTransaction(t *testing.T) {
+	ctx := context.Background()
+	repository, auditRepository, outbox := NewRepository(), NewAuditRepository(), NewOutbox()
+	transactor := NewTransactor(repository, auditRepository, outbox)
+	if _, err := repository.Save(ctx, &user.User{ID: "1", FirstName: "John", LastName: "Doe"}); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if _, err := repository.Save(ctx, &user.User{ID: "2", FirstName: "Jane", LastName: "Doe"}); err != nil {
+			return err
+		}
+		if err := auditRepository.Append(ctx, &user.AuditRecord{UserID: "2", Action: user.AuditActionCreate}); err != nil {
+			return err
+		}
+		if err := outbox.Add(ctx, &user.Event{ID: "e2", Type: user.EventUserCreated, UserID: "2"}); err != nil {
+			return err
+		}
+		if err := repository.Tombstone(ctx, &user.Tombstone{ID: "1", MergedInto: "2"}, 1); err != nil {
+			return err
+		}
+		return repository.Tombstone(ctx, &user.Tombstone{ID: "3", MergedInto: "2"}, 1)
+	})
+	if !errors.Is(err, user.ErrNotFound) {
+		t.Fatalf("WithinTransaction() error = %v, want ErrNotFound", err)
+	}
+
+	if _, err := repository.FindByID(ctx, "1"); err != nil {
+		t.Errorf("FindByID(1) after rollback error = %v, want the user", err)
+	}
+	if _, err := repository.FindTombstone(ctx, "1"); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("FindTombstone(1) after rollback error = %v, want ErrNotFound", err)
+	}
+	if _, err := repository.FindByID(ctx, "2"); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("FindByID(2) after rollback error = %v, want ErrNotFound", err)
+	}
+	if records, _ := auditRepository.ListByUserID(ctx, "2"); len(records) != 0 {
+		t.Errorf("ListByUserID(2) after rollback = %v, want none", records)
+	}
+	if pending, _ := outbox.Pending(ctx, 10); len(pending) != 0 {
+		t.Errorf("Pending() after rollback = %v, want none", pending)
+	}
+}
+
+func TestWebhookDeliveryRepository_Due(t *testing.T) {
+	ctx := context.Background()
+	deliveries := NewWebhookDeliveryRepository()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i, id := range []string{"s1-e1", "s1-e2", "s1-e3"} {
+		delivery := &webhook.Delivery{ID: id, SubscriptionID: "s1", Status: webhook.DeliveryPending,
+			NextAttemptAt: now.Add(time.Duration(i) * time.Minute), CreatedAt: now.Add(time.Duration(i) * time.Minute)}
+		if err := deliveries.Add(ctx, delivery); err != nil {
+			t.Fatalf("Add() unexpected error: %v", err)
+		}
+	}
+
+	due, err := deliveries.Due(ctx, now.Add(time.Minute), 10)
+	if err != nil || len(due) != 2 || due[0].ID != "s1-e1" {
+		t.Fatalf("Due() = %v, %v, want deliveries s1-e1 and s1-e2", due, err)
+	}
+	due[0].Status = webhook.DeliverySucceeded
+	if err := deliveries.Add(ctx, due[0]); err != nil {
+		t.Fatalf("Add() unexpected error: %v", err)
+	}
+	if due, _ = deliveries.Due(ctx, now, 10); len(due) != 1 {
+		t.Fatalf("Due() after adding an existing delivery = %v, want it unchanged", due)
+	}
+	due[0].Status = webhook.DeliverySucceeded
+	if err := deliveries.Save(ctx, due[0]); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+
+	log, _ := deliveries.ListBySubscriptionID(ctx, "s1", 2)
+	if len(log) != 2 || log[0].ID != "s1-e3" || log[1].ID != "s1-e2" {
+		t.Errorf("ListBySubscriptionID() = %v, want the 2 newest deliveries", log)
+	}
+	if due, _ = deliveries.Due(ctx, now, 10); len(due) != 0 {
+		t.Errorf("Due() after success = %v, want none", due)
+	}
+}
+
+func TestRepository_Tombstone(t *testing.T) {
+	ctx := context.Background()
+	repo := &repository{
+		users:      map[string]*user.User{"1": {ID: "1", FirstName: "John"}, "2": {ID: "2", FirstName: "Jon", Version: 2}},
+		tombstones: make(map[string]*user.Tombstone),
+	}
+
+	if err := repo.Tombstone(ctx, &user.Tombstone{ID: "2", MergedInto: "1"}, 1); !errors.Is(err, user.ErrVersionConflict) {
+		t.Errorf("Tombstone() changed user error = %v, want %v", err, user.ErrVersionConflict)
+	}
+	if err := repo.Tombstone(ctx, &user.Tombstone{ID: "2", MergedInto: "1"}, 2); err != nil {
+		t.Fatalf("Tombstone() unexpected error: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, "2"); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("FindByID() error = %v, want the tombstoned user removed", err)
+	}
+	if tombstone, err := repo.FindTombstone(ctx, "2"); err != nil || tombstone.MergedInto != "1" {
+		t.Errorf("FindTombstone() = %+v, %v, want user 2 merged into 1", tombstone, err)
+	}
+	if _, err := repo.FindTombstone(ctx, "1"); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("FindTombstone() error = %v, want %v", err, user.ErrNotFound)
+	}
+	if err := repo.Tombstone(ctx, &user.Tombstone{ID: "3", MergedInto: "1"}, 0); !errors.Is(err, user.ErrNotFound) {
+		t.Errorf("Tombstone() missing user error = %v, want %v", err, user.ErrNotFound)
+	}
+}
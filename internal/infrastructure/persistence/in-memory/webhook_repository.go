@@ -0,0 +1,153 @@
+package inmemory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+type webhookSubscriptionRepository struct {
+	mu            sync.RWMutex
+	subscriptions map[string]*webhook.Subscription
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook subscription repository in memory
+func NewWebhookSubscriptionRepository() webhook.SubscriptionRepository {
+	return &webhookSubscriptionRepository{
+		subscriptions: make(map[string]*webhook.Subscription),
+	}
+}
+
+func (r *webhookSubscriptionRepository) FindByID(ctx context.Context, id string) (*webhook.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscription, ok := r.subscriptions[id]
+	if !ok {
+		return nil, fmt.Errorf("inmemory: failed to find webhook subscription by ID %q: %w", id, webhook.ErrNotFound)
+	}
+	return subscription, nil
+}
+
+func (r *webhookSubscriptionRepository) Save(ctx context.Context, subscription *webhook.Subscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (r *webhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.subscriptions[id]; !ok {
+		return fmt.Errorf("inmemory: failed to delete webhook subscription by ID %q: %w", id, webhook.ErrNotFound)
+	}
+	delete(r.subscriptions, id)
+	return nil
+}
+
+func (r *webhookSubscriptionRepository) List(ctx context.Context) ([]*webhook.Subscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	subscriptions := make([]*webhook.Subscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		subscriptions = append(subscriptions, subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].CreatedAt.Equal(subscriptions[j].CreatedAt) {
+			return subscriptions[i].CreatedAt.Before(subscriptions[j].CreatedAt)
+		}
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+	return subscriptions, nil
+}
+
+type webhookDeliveryRepository struct {
+	mu         sync.RWMutex
+	deliveries map[string]webhook.Delivery
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository in memory.
+// Deliveries are copied in and out so the dispatcher can update them while they are listed.
+func NewWebhookDeliveryRepository() webhook.DeliveryRepository {
+	return &webhookDeliveryRepository{
+		deliveries: make(map[string]webhook.Delivery),
+	}
+}
+
+func (r *webhookDeliveryRepository) Add(ctx context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.deliveries[delivery.ID]; !ok {
+		r.deliveries[delivery.ID] = copyDelivery(delivery)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepository) Save(ctx context.Context, delivery *webhook.Delivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.ID] = copyDelivery(delivery)
+	return nil
+}
+
+func (r *webhookDeliveryRepository) Due(ctx context.Context, now time.Time, limit int) ([]*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var due []*webhook.Delivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == webhook.DeliveryPending && !delivery.NextAttemptAt.After(now) {
+			delivery := copyDelivery(&delivery)
+			due = append(due, &delivery)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if !due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		}
+		return due[i].ID < due[j].ID
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *webhookDeliveryRepository) ListBySubscriptionID(ctx context.Context, subscriptionID string, limit int) ([]*webhook.Delivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var deliveries []*webhook.Delivery
+	for _, delivery := range r.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			delivery := copyDelivery(&delivery)
+			deliveries = append(deliveries, &delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if !deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+		}
+		return deliveries[i].ID > deliveries[j].ID
+	})
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func copyDelivery(delivery *webhook.Delivery) webhook.Delivery {
+	copied := *delivery
+	copied.Attempts = append([]webhook.Attempt(nil), delivery.Attempts...)
+	return copied
+}
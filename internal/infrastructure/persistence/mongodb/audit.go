@@ -0,0 +1,57 @@
+package mongodb
+
+import (
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type auditRecord struct {
+	ID         string        `bson:"_id"`
+	UserID     string        `bson:"user_id"`
+	Action     string        `bson:"action"`
+	Actor      string        `bson:"actor"`
+	RequestID  string        `bson:"request_id,omitempty"`
+	Timestamp  time.Time     `bson:"timestamp"`
+	Changes    []fieldChange `bson:"changes,omitempty"`
+	MergedIDs  []string      `bson:"merged_ids,omitempty"`
+	MergedInto string        `bson:"merged_into,omitempty"`
+}
+
+type fieldChange struct {
+	Field  string `bson:"field"`
+	Before string `bson:"before"`
+	After  string `bson:"after"`
+}
+
+func (a *auditRecord) ToEntity() *userEntity.AuditRecord {
+	record := &userEntity.AuditRecord{
+		ID:         a.ID,
+		UserID:     a.UserID,
+		Action:     userEntity.AuditAction(a.Action),
+		Actor:      a.Actor,
+		RequestID:  a.RequestID,
+		Timestamp:  a.Timestamp.UTC(),
+		MergedIDs:  a.MergedIDs,
+		MergedInto: a.MergedInto,
+	}
+	for _, change := range a.Changes {
+		record.Changes = append(record.Changes, userEntity.FieldChange{Field: change.Field, Before: change.Before, After: change.After})
+	}
+	return record
+}
+
+func (a *auditRecord) FromEntity(record *userEntity.AuditRecord) {
+	a.ID = record.ID
+	a.UserID = record.UserID
+	a.Action = string(record.Action)
+	a.Actor = record.Actor
+	a.RequestID = record.RequestID
+	a.Timestamp = record.Timestamp
+	a.MergedIDs = record.MergedIDs
+	a.MergedInto = record.MergedInto
+	a.Changes = nil
+	for _, change := range record.Changes {
+		a.Changes = append(a.Changes, fieldChange{Field: change.Field, Before: change.Before, After: change.After})
+	}
+}
@@ -0,0 +1,56 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// auditCollectionSuffix names the collection holding the audit trail of a user collection
+const auditCollectionSuffix = "_audit"
+
+type auditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository creates a new audit repository using a MongoDB client.
+// Records are stored in the <collection>_audit collection next to the users.
+func NewAuditRepository(client *MongoDBClient) userEntity.AuditRepository {
+	return &auditRepository{collection: auditCollection(client.GetCollection())}
+}
+
+func auditCollection(users *mongo.Collection) *mongo.Collection {
+	return users.Database().Collection(users.Name() + auditCollectionSuffix)
+}
+
+func (r *auditRepository) Append(ctx context.Context, record *userEntity.AuditRecord) error {
+	var recordDTO auditRecord
+	recordDTO.FromEntity(record)
+
+	if _, err := r.collection.InsertOne(ctx, recordDTO); err != nil {
+		return fmt.Errorf("mongodb: failed to append audit record: %w", err)
+	}
+	return nil
+}
+
+func (r *auditRepository) ListByUserID(ctx context.Context, userID string) ([]*userEntity.AuditRecord, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to list audit records of user %q: %w", userID, err)
+	}
+	var recordDTOs []auditRecord
+	if err := cursor.All(ctx, &recordDTOs); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode audit records: %w", err)
+	}
+
+	records := make([]*userEntity.AuditRecord, 0, len(recordDTOs))
+	for _, recordDTO := range recordDTOs {
+		records = append(records, recordDTO.ToEntity())
+	}
+	return records, nil
+}
@@ -0,0 +1,44 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// cursor decodes users from a MongoDB cursor one batch at a time
+type cursor struct {
+	cursor *mongo.Cursor
+	user   *userEntity.User
+	err    error
+}
+
+func (c *cursor) Next(ctx context.Context) bool {
+	if !c.cursor.Next(ctx) {
+		if err := c.cursor.Err(); err != nil {
+			c.err = fmt.Errorf("mongodb: failed to iterate users: %w", err)
+		}
+		return false
+	}
+	var userDTO user
+	if err := c.cursor.Decode(&userDTO); err != nil {
+		c.err = fmt.Errorf("mongodb: failed to decode user: %w", err)
+		return false
+	}
+	c.user = userDTO.ToEntity()
+	return true
+}
+
+func (c *cursor) User() *userEntity.User {
+	return c.user
+}
+
+func (c *cursor) Err() error {
+	return c.err
+}
+
+func (c *cursor) Close(ctx context.Context) error {
+	return c.cursor.Close(ctx)
+}
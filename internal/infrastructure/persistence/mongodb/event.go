@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type event struct {
+	ID          string        `bson:"_id"`
+	Type        string        `bson:"type"`
+	UserID      string        `bson:"user_id"`
+	User        user          `bson:"user"`
+	Changes     []fieldChange `bson:"changes,omitempty"`
+	Actor       string        `bson:"actor"`
+	RequestID   string        `bson:"request_id,omitempty"`
+	OccurredAt  time.Time     `bson:"occurred_at"`
+	DeliveredAt *time.Time    `bson:"delivered_at,omitempty"`
+}
+
+func (e *event) ToEntity() *userEntity.Event {
+	domainEvent := &userEntity.Event{
+		ID:         e.ID,
+		Type:       userEntity.EventType(e.Type),
+		UserID:     e.UserID,
+		User:       *e.User.ToEntity(),
+		Actor:      e.Actor,
+		RequestID:  e.RequestID,
+		OccurredAt: e.OccurredAt.UTC(),
+	}
+	for _, change := range e.Changes {
+		domainEvent.Changes = append(domainEvent.Changes, userEntity.FieldChange{Field: change.Field, Before: change.Before, After: change.After})
+	}
+	return domainEvent
+}
+
+func (e *event) FromEntity(domainEvent *userEntity.Event) {
+	e.ID = domainEvent.ID
+	e.Type = string(domainEvent.Type)
+	e.UserID = domainEvent.UserID
+	e.User.FromEntity(&domainEvent.User)
+	e.Actor = domainEvent.Actor
+	e.RequestID = domainEvent.RequestID
+	e.OccurredAt = domainEvent.OccurredAt
+	e.Changes = nil
+	for _, change := range domainEvent.Changes {
+		e.Changes = append(e.Changes, fieldChange{Field: change.Field, Before: change.Before, After: change.After})
+	}
+}
@@ -0,0 +1,283 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// migrationsCollectionSuffix names the collection recording the migrations applied to a collection
+	migrationsCollectionSuffix = "_migrations"
+	// nameKeyIndex is the unique index of the name keys of users
+	nameKeyIndex = "name_key"
+)
+
+// Migration is a versioned change to the MongoDB schema
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, collection *mongo.Collection) error
+}
+
+// migrationRecord is the bson representation of an applied migration
+type migrationRecord struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"applied_at"`
+}
+
+// MigrationConfig configures the migrations computing values the application derives from its configuration
+type MigrationConfig struct {
+	// GmailCanonicalization is the VALIDATION_GMAIL_CANONICALIZATION the canonical emails are computed with
+	GmailCanonicalization bool
+}
+
+// NewMigrations returns the migrations of the user collection in version order
+func NewMigrations(config MigrationConfig) []Migration {
+	return []Migration{
+		{
+			Version:     1,
+			Description: "index users by first and last name",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "first_name", Value: 1}, {Key: "last_name", Value: 1}},
+					Options: options.Index().SetName("first_name_last_name"),
+				})
+				return err
+			},
+		},
+		{
+			Version:     2,
+			Description: "index users by email",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "email", Value: 1}},
+					Options: options.Index().SetName("email"),
+				})
+				return err
+			},
+		},
+		{
+			Version:     3,
+			Description: "index audit records by user and time",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := auditCollection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "timestamp", Value: 1}},
+					Options: options.Index().SetName("user_id_timestamp"),
+				})
+				return err
+			},
+		},
+		{
+			Version:     4,
+			Description: "index pending outbox events by time",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := outboxCollection(collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys: bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}},
+					Options: options.Index().SetName("pending_occurred_at").
+						SetPartialFilterExpression(bson.M{"delivered_at": bson.M{"$exists": false}}),
+				})
+				return err
+			},
+		},
+		{
+			Version:     5,
+			Description: "index webhook deliveries by due time and subscription",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := webhookDeliveryCollection(collection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+						Options: options.Index().SetName("status_next_attempt_at"),
+					},
+					{
+						Keys:    bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}},
+						Options: options.Index().SetName("subscription_id_created_at"),
+					},
+				})
+				return err
+			},
+		},
+		{
+			Version:     6,
+			Description: "set and index the name key of users",
+			// the index is unique, creating it fails while users share a name key
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				cursor, err := collection.Find(ctx, bson.M{"name_key": bson.M{"$exists": false}},
+					options.Find().SetProjection(bson.M{"first_name": 1, "last_name": 1}))
+				if err != nil {
+					return err
+				}
+				defer cursor.Close(ctx)
+				for cursor.Next(ctx) {
+					var userDTO user
+					if err := cursor.Decode(&userDTO); err != nil {
+						return err
+					}
+					nameKey := userEntity.NameKey(userDTO.FirstName, userDTO.LastName)
+					if _, err := collection.UpdateByID(ctx, userDTO.ID, bson.M{"$set": bson.M{"name_key": nameKey}}); err != nil {
+						return err
+					}
+				}
+				if err := cursor.Err(); err != nil {
+					return err
+				}
+				return createNameKeyIndex(ctx, collection)
+			},
+		},
+		{
+			Version:     7,
+			Description: "set users saved before the lifecycle as active and index users by status",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				_, err := collection.UpdateMany(ctx, bson.M{"status": bson.M{"$exists": false}},
+					bson.M{"$set": bson.M{"status": string(userEntity.StatusActive)}})
+				if err != nil {
+					return err
+				}
+				_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+					Keys:    bson.D{{Key: "status", Value: 1}, {Key: "_id", Value: 1}},
+					Options: options.Index().SetName("status_id"),
+				})
+				return err
+			},
+		},
+		{
+			Version:     8,
+			Description: "set and index the first and last name keys and the canonical email of users",
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"first_name": 1, "last_name": 1, "email": 1}))
+				if err != nil {
+					return err
+				}
+				defer cursor.Close(ctx)
+				for cursor.Next(ctx) {
+					var userDTO user
+					if err := cursor.Decode(&userDTO); err != nil {
+						return err
+					}
+					set := bson.M{
+						"first_name_key": userEntity.FoldName(userDTO.FirstName),
+						"last_name_key":  userEntity.FoldName(userDTO.LastName),
+					}
+					// users saved before canonical emails have none, invalid emails keep none
+					if email, err := userEntity.ParseEmail(userDTO.Email, config.GmailCanonicalization); err == nil {
+						set["canonical_email"] = email.Canonical
+					}
+					if _, err := collection.UpdateByID(ctx, userDTO.ID, bson.M{"$set": set}); err != nil {
+						return err
+					}
+				}
+				if err := cursor.Err(); err != nil {
+					return err
+				}
+				_, err = collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+					{
+						Keys:    bson.D{{Key: "first_name_key", Value: 1}},
+						Options: options.Index().SetName("first_name_key"),
+					},
+					{
+						Keys:    bson.D{{Key: "last_name_key", Value: 1}},
+						Options: options.Index().SetName("last_name_key"),
+					},
+					{
+						Keys:    bson.D{{Key: "canonical_email", Value: 1}},
+						Options: options.Index().SetName("canonical_email"),
+					},
+				})
+				return err
+			},
+		},
+		{
+			Version:     9,
+			Description: "make the name key index unique",
+			// migration 6 created the index without uniqueness, it is replaced and fails while users share a name key
+			Up: func(ctx context.Context, collection *mongo.Collection) error {
+				cursor, err := collection.Indexes().List(ctx)
+				if err != nil {
+					return err
+				}
+				var indexes []struct {
+					Name   string `bson:"name"`
+					Unique bool   `bson:"unique"`
+				}
+				if err := cursor.All(ctx, &indexes); err != nil {
+					return err
+				}
+				for _, index := range indexes {
+					if index.Name != nameKeyIndex {
+						continue
+					}
+					if index.Unique {
+						return nil
+					}
+					if _, err := collection.Indexes().DropOne(ctx, nameKeyIndex); err != nil {
+						return err
+					}
+				}
+				return createNameKeyIndex(ctx, collection)
+			},
+		},
+	}
+}
+
+// createNameKeyIndex creates the unique index of the name keys, Save reports its duplicate key errors as
+// userEntity.ErrNameCombinationExists
+func createNameKeyIndex(ctx context.Context, collection *mongo.Collection) error {
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name_key", Value: 1}},
+		Options: options.Index().SetName(nameKeyIndex).SetUnique(true),
+	})
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("users share a name key, merge or rename them and migrate again: %w", err)
+	}
+	return err
+}
+
+// Migrate applies the migrations that have not been applied yet and returns them.
+// Applied versions are recorded in the <collection>_migrations collection so running it again is a no-op.
+func Migrate(ctx context.Context, client *MongoDBClient, migrations []Migration) ([]Migration, error) {
+	records := client.collection.Database().Collection(client.collection.Name() + migrationsCollectionSuffix)
+
+	applied, err := appliedVersions(ctx, records)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+		if err := migration.Up(ctx, client.collection); err != nil {
+			return ran, fmt.Errorf("mongodb: failed to apply migration %d: %w", migration.Version, err)
+		}
+		record := migrationRecord{Version: migration.Version, Description: migration.Description, AppliedAt: time.Now().UTC()}
+		if _, err := records.InsertOne(ctx, record); err != nil {
+			return ran, fmt.Errorf("mongodb: failed to record migration %d: %w", migration.Version, err)
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+func appliedVersions(ctx context.Context, records *mongo.Collection) (map[int]bool, error) {
+	cursor, err := records.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to read applied migrations: %w", err)
+	}
+	var applied []migrationRecord
+	if err := cursor.All(ctx, &applied); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode applied migrations: %w", err)
+	}
+
+	versions := make(map[int]bool, len(applied))
+	for _, record := range applied {
+		versions[record.Version] = true
+	}
+	return versions, nil
+}
@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// outboxCollectionSuffix names the collection holding the events of a user collection
+const outboxCollectionSuffix = "_outbox"
+
+type outbox struct {
+	collection *mongo.Collection
+}
+
+// NewOutbox creates a new outbox using a MongoDB client.
+// Events are stored in the <collection>_outbox collection and kept with their delivery time once delivered.
+func NewOutbox(client *MongoDBClient) userEntity.Outbox {
+	return &outbox{collection: outboxCollection(client.GetCollection())}
+}
+
+func outboxCollection(users *mongo.Collection) *mongo.Collection {
+	return users.Database().Collection(users.Name() + outboxCollectionSuffix)
+}
+
+func (o *outbox) Add(ctx context.Context, domainEvent *userEntity.Event) error {
+	var eventDTO event
+	eventDTO.FromEntity(domainEvent)
+
+	if _, err := o.collection.InsertOne(ctx, eventDTO); err != nil {
+		return fmt.Errorf("mongodb: failed to add event to the outbox: %w", err)
+	}
+	return nil
+}
+
+func (o *outbox) Pending(ctx context.Context, limit int) ([]*userEntity.Event, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := o.collection.Find(ctx, bson.M{"delivered_at": bson.M{"$exists": false}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to list pending events: %w", err)
+	}
+	var eventDTOs []event
+	if err := cursor.All(ctx, &eventDTOs); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode events: %w", err)
+	}
+
+	events := make([]*userEntity.Event, 0, len(eventDTOs))
+	for _, eventDTO := range eventDTOs {
+		events = append(events, eventDTO.ToEntity())
+	}
+	return events, nil
+}
+
+func (o *outbox) MarkDelivered(ctx context.Context, id string) error {
+	update := bson.M{"$set": bson.M{"delivered_at": time.Now().UTC()}}
+	result, err := o.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to mark event %q delivered: %w", id, err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("mongodb: failed to mark event %q delivered: event not found", id)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package mongodb
+
+import (
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type userTombstone struct {
+	ID         string    `bson:"_id"`
+	MergedInto string    `bson:"merged_into"`
+	MergedAt   time.Time `bson:"merged_at"`
+}
+
+func (t *userTombstone) ToEntity() *userEntity.Tombstone {
+	return &userEntity.Tombstone{ID: t.ID, MergedInto: t.MergedInto, MergedAt: t.MergedAt.UTC()}
+}
+
+func (t *userTombstone) FromEntity(tombstone *userEntity.Tombstone) {
+	t.ID = tombstone.ID
+	t.MergedInto = tombstone.MergedInto
+	t.MergedAt = tombstone.MergedAt
+}
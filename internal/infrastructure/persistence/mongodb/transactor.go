@@ -0,0 +1,99 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTransactionsUnsupported is returned when running a transaction on a standalone server
+var ErrTransactionsUnsupported = errors.New("mongodb: transactions need a replica set or sharded cluster")
+
+type transactor struct {
+	client          *MongoDBClient
+	allowStandalone bool
+
+	mu        sync.Mutex
+	checked   bool
+	supported bool
+}
+
+// TransactorOption configures a transactor
+type TransactorOption func(t *transactor)
+
+// AllowStandalone runs functions without a transaction on a standalone server instead of failing with
+// ErrTransactionsUnsupported. A failing function then leaves its earlier writes behind.
+func AllowStandalone() TransactorOption {
+	return func(t *transactor) {
+		t.allowStandalone = true
+	}
+}
+
+// NewTransactor creates a transactor running functions in MongoDB transactions.
+// Transactions need a replica set or sharded cluster, see TransactionsSupported and AllowStandalone.
+func NewTransactor(client *MongoDBClient, opts ...TransactorOption) shared.Transactor {
+	t := &transactor{client: client}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	supported, err := t.transactionsSupported(ctx)
+	if err != nil {
+		return err
+	}
+	if !supported {
+		if !t.allowStandalone {
+			return ErrTransactionsUnsupported
+		}
+		return fn(ctx)
+	}
+
+	session, err := t.client.GetClient().StartSession()
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
+
+// transactionsSupported caches the answer of TransactionsSupported once the server was reached
+func (t *transactor) transactionsSupported(ctx context.Context) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.checked {
+		return t.supported, nil
+	}
+
+	supported, err := TransactionsSupported(ctx, t.client)
+	if err != nil {
+		return false, err
+	}
+	t.checked = true
+	t.supported = supported
+	return supported, nil
+}
+
+// TransactionsSupported reports whether the server is a replica set member or a mongos, which support transactions
+func TransactionsSupported(ctx context.Context, client *MongoDBClient) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	err := client.GetClient().Database("admin").RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, fmt.Errorf("mongodb: failed to check transaction support: %w", err)
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
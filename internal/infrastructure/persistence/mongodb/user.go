@@ -1,29 +1,143 @@
 package mongodb
 
-import userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+import (
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
 
 type user struct {
-	ID        string `bson:"_id,omitempty"`
-	FirstName string `bson:"first_name,omitempty"`
-	LastName  string `bson:"last_name,omitempty"`
-	Email     string `bson:"email,omitempty"`
-	Age       int    `bson:"age,omitempty"`
+	ID              string             `bson:"_id,omitempty"`
+	FirstName       string             `bson:"first_name,omitempty"`
+	LastName        string             `bson:"last_name,omitempty"`
+	NameKey         string             `bson:"name_key,omitempty"`
+	FirstNameKey    string             `bson:"first_name_key,omitempty"`
+	LastNameKey     string             `bson:"last_name_key,omitempty"`
+	Email           string             `bson:"email,omitempty"`
+	CanonicalEmail  string             `bson:"canonical_email,omitempty"`
+	EmailVerifiedAt time.Time          `bson:"email_verified_at,omitempty"`
+	DateOfBirth     time.Time          `bson:"date_of_birth,omitempty"`
+	Age             int                `bson:"age,omitempty"`
+	Phone           string             `bson:"phone,omitempty"`
+	Address         *address           `bson:"address,omitempty"`
+	Locale          string             `bson:"locale,omitempty"`
+	TimeZone        string             `bson:"time_zone,omitempty"`
+	Status          string             `bson:"status,omitempty"`
+	StatusHistory   []statusTransition `bson:"status_history,omitempty"`
+	CreatedAt       time.Time          `bson:"created_at,omitempty"`
+	Version         int64              `bson:"version,omitempty"`
+	UpdatedAt       time.Time          `bson:"updated_at,omitempty"`
 }
 
 func (u *user) ToEntity() *userEntity.User {
-	return &userEntity.User{
-		ID:        u.ID,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Email:     u.Email,
-		Age:       u.Age,
+	entity := &userEntity.User{
+		ID:              u.ID,
+		FirstName:       u.FirstName,
+		LastName:        u.LastName,
+		Email:           u.Email,
+		CanonicalEmail:  u.CanonicalEmail,
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		DateOfBirth:     u.DateOfBirth,
+		Age:             u.Age,
+		Phone:           u.Phone,
+		Address:         u.Address.toEntity(),
+		Locale:          u.Locale,
+		TimeZone:        u.TimeZone,
+		Status:          userEntity.StatusActive,
+		CreatedAt:       u.CreatedAt,
+		Version:         u.Version,
+		UpdatedAt:       u.UpdatedAt,
+	}
+	// users saved before the lifecycle was recorded were onboarded, migration 7 stores their status
+	if u.Status != "" {
+		entity.Status = userEntity.Status(u.Status)
 	}
+	for _, transition := range u.StatusHistory {
+		entity.StatusHistory = append(entity.StatusHistory, userEntity.StatusTransition{
+			From:   userEntity.Status(transition.From),
+			To:     userEntity.Status(transition.To),
+			At:     transition.At,
+			Actor:  transition.Actor,
+			Reason: transition.Reason,
+		})
+	}
+	return entity
 }
 
 func (u *user) FromEntity(user *userEntity.User) {
 	u.ID = user.ID
 	u.FirstName = user.FirstName
 	u.LastName = user.LastName
+	u.NameKey = userEntity.NameKey(user.FirstName, user.LastName)
+	u.FirstNameKey = userEntity.FoldName(user.FirstName)
+	u.LastNameKey = userEntity.FoldName(user.LastName)
 	u.Email = user.Email
+	u.CanonicalEmail = user.CanonicalEmail
+	u.EmailVerifiedAt = user.EmailVerifiedAt
+	u.DateOfBirth = user.DateOfBirth
 	u.Age = user.Age
+	u.Phone = user.Phone
+	u.Address = newAddress(user.Address)
+	u.Locale = user.Locale
+	u.TimeZone = user.TimeZone
+	u.Status = string(user.Status)
+	u.StatusHistory = nil
+	for _, transition := range user.StatusHistory {
+		u.StatusHistory = append(u.StatusHistory, statusTransition{
+			From:   string(transition.From),
+			To:     string(transition.To),
+			At:     transition.At,
+			Actor:  transition.Actor,
+			Reason: transition.Reason,
+		})
+	}
+	u.CreatedAt = user.CreatedAt
+	u.Version = user.Version
+	u.UpdatedAt = user.UpdatedAt
+}
+
+type address struct {
+	Line1      string `bson:"line1,omitempty"`
+	Line2      string `bson:"line2,omitempty"`
+	City       string `bson:"city,omitempty"`
+	Region     string `bson:"region,omitempty"`
+	PostalCode string `bson:"postal_code,omitempty"`
+	Country    string `bson:"country,omitempty"`
+}
+
+// newAddress returns the document of an address, nil for an empty address so none is stored
+func newAddress(entity userEntity.Address) *address {
+	if entity.IsZero() {
+		return nil
+	}
+	return &address{
+		Line1:      entity.Line1,
+		Line2:      entity.Line2,
+		City:       entity.City,
+		Region:     entity.Region,
+		PostalCode: entity.PostalCode,
+		Country:    entity.Country,
+	}
+}
+
+func (a *address) toEntity() userEntity.Address {
+	if a == nil {
+		return userEntity.Address{}
+	}
+	return userEntity.Address{
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+type statusTransition struct {
+	From   string    `bson:"from,omitempty"`
+	To     string    `bson:"to"`
+	At     time.Time `bson:"at"`
+	Actor  string    `bson:"actor,omitempty"`
+	Reason string    `bson:"reason,omitempty"`
 }
@@ -2,10 +2,20 @@ package mongodb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// tombstoneCollectionSuffix names the collection storing the tombstones of the users merged away from a collection
+	tombstoneCollectionSuffix = "_tombstones"
+	// duplicateKeyCode is the code of the errors violating a unique index
+	duplicateKeyCode = 11000
 )
 
 type repository struct {
@@ -23,6 +33,9 @@ func (r *repository) FindByID(ctx context.Context, id string) (*userEntity.User,
 
 	var userDTO user
 	err := r.client.GetCollection().FindOne(ctx, filter).Decode(&userDTO)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("mongodb: failed to find user by ID %q: %w", id, userEntity.ErrNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("mongodb: failed to find user by ID %q: %w", id, err)
 	}
@@ -30,21 +43,75 @@ func (r *repository) FindByID(ctx context.Context, id string) (*userEntity.User,
 	return userDTO.ToEntity(), nil
 }
 
-func (r *repository) Save(ctx context.Context, userEntity *userEntity.User) (*userEntity.User, error) {
+func (r *repository) Save(ctx context.Context, userToSave *userEntity.User) (*userEntity.User, error) {
 	var userDTO user
-	userDTO.FromEntity(userEntity)
+	userDTO.FromEntity(userToSave)
+	userDTO.Version++
 
-	_, err := r.client.GetCollection().InsertOne(ctx, userDTO)
+	// replace the document only at the expected version, documents saved before versioning have no version.
+	// A new user is upserted, which fails on the unique _id when another version exists.
+	filter := bson.M{"_id": userDTO.ID, "version": userToSave.Version}
+	if userToSave.Version == 0 {
+		filter["version"] = nil
+	}
+	result, err := r.client.GetCollection().ReplaceOne(ctx, filter, userDTO, options.Replace().SetUpsert(userToSave.Version == 0))
+	if isDuplicateNameKey(err) {
+		return nil, fmt.Errorf("mongodb: failed to save user %q: %w", userDTO.ID, userEntity.ErrNameCombinationExists)
+	}
+	if mongo.IsDuplicateKeyError(err) || (err == nil && result.MatchedCount == 0 && result.UpsertedCount == 0) {
+		return nil, fmt.Errorf("mongodb: failed to save user %q at version %d: %w", userDTO.ID, userToSave.Version, userEntity.ErrVersionConflict)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("mongodb: failed to save user: %w", err)
 	}
 
 	return userDTO.ToEntity(), nil
+}
+
+func (r *repository) Delete(ctx context.Context, id string) error {
+	result, err := r.client.GetCollection().DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to delete user by ID %q: %w", id, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("mongodb: failed to delete user by ID %q: %w", id, userEntity.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *repository) List(ctx context.Context, listFilter userEntity.ListFilter) ([]*userEntity.User, error) {
+	filter := listFilterToBSON(listFilter)
+	opts := findOptions(listFilter)
+
+	cursor, err := r.client.GetCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to list users: %w", err)
+	}
+	var userDTOs []user
+	if err := cursor.All(ctx, &userDTOs); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode users: %w", err)
+	}
+
+	users := make([]*userEntity.User, 0, len(userDTOs))
+	for _, userDTO := range userDTOs {
+		users = append(users, userDTO.ToEntity())
+	}
+	return users, nil
+}
+
+func (r *repository) Stream(ctx context.Context, listFilter userEntity.ListFilter) (userEntity.Cursor, error) {
+	filter := listFilterToBSON(listFilter)
+	opts := findOptions(listFilter)
 
+	mongoCursor, err := r.client.GetCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to stream users: %w", err)
+	}
+	return &cursor{cursor: mongoCursor}, nil
 }
 
 func (r *repository) ExistsByFirstNameAndLastName(ctx context.Context, firstName string, lastName string) bool {
-	filter := bson.M{"first_name": firstName, "last_name": lastName}
+	filter := bson.M{"name_key": userEntity.NameKey(firstName, lastName)}
 
 	var userDTO user
 	err := r.client.GetCollection().FindOne(ctx, filter).Decode(&userDTO)
@@ -53,7 +120,7 @@ func (r *repository) ExistsByFirstNameAndLastName(ctx context.Context, firstName
 }
 
 func (r *repository) ExistsByFirstNameAndLastNameAndIDNot(ctx context.Context, firstName string, lastName string, id string) bool {
-	filter := bson.M{"first_name": firstName, "last_name": lastName, "_id": bson.M{"$ne": id}}
+	filter := bson.M{"name_key": userEntity.NameKey(firstName, lastName), "_id": bson.M{"$ne": id}}
 
 	var userDTO user
 	err := r.client.GetCollection().FindOne(ctx, filter).Decode(&userDTO)
@@ -61,3 +128,150 @@ func (r *repository) ExistsByFirstNameAndLastNameAndIDNot(ctx context.Context, f
 	// if there is no error then user exists
 	return err == nil
 }
+
+func (r *repository) ListDuplicateCandidates(ctx context.Context, userToMatch *userEntity.User, limit int) ([]*userEntity.User, error) {
+	excluded := bson.M{}
+	if userToMatch.ID != "" {
+		excluded["$ne"] = userToMatch.ID
+	}
+
+	// email matches are the strongest signal, they are never cut off by the limit
+	var candidates []*userEntity.User
+	if userToMatch.CanonicalEmail != "" {
+		filter := bson.M{"canonical_email": userToMatch.CanonicalEmail}
+		if len(excluded) > 0 {
+			filter["_id"] = excluded
+		}
+		emailMatches, err := r.findUsers(ctx, filter, findOptions(userEntity.ListFilter{}))
+		if err != nil {
+			return nil, fmt.Errorf("mongodb: failed to list duplicate candidates by email: %w", err)
+		}
+		candidates = emailMatches
+	}
+	if limit > 0 && len(candidates) >= limit {
+		return candidates, nil
+	}
+
+	var keys bson.A
+	if firstNameKey := userEntity.FoldName(userToMatch.FirstName); firstNameKey != "" {
+		keys = append(keys, bson.M{"first_name_key": firstNameKey})
+	}
+	if lastNameKey := userEntity.FoldName(userToMatch.LastName); lastNameKey != "" {
+		keys = append(keys, bson.M{"last_name_key": lastNameKey})
+	}
+	if len(keys) == 0 {
+		return candidates, nil
+	}
+	filter := bson.M{"$or": keys}
+	if len(excluded) > 0 {
+		filter["_id"] = excluded
+	}
+	if userToMatch.CanonicalEmail != "" {
+		filter["canonical_email"] = bson.M{"$ne": userToMatch.CanonicalEmail}
+	}
+	nameLimit := 0
+	if limit > 0 {
+		nameLimit = limit - len(candidates)
+	}
+	nameMatches, err := r.findUsers(ctx, filter, findOptions(userEntity.ListFilter{Limit: nameLimit}))
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to list duplicate candidates by name: %w", err)
+	}
+	return append(candidates, nameMatches...), nil
+}
+
+// findUsers finds the users matching the filter
+func (r *repository) findUsers(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*userEntity.User, error) {
+	cursor, err := r.client.GetCollection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	var userDTOs []user
+	if err := cursor.All(ctx, &userDTOs); err != nil {
+		return nil, err
+	}
+	users := make([]*userEntity.User, 0, len(userDTOs))
+	for _, userDTO := range userDTOs {
+		users = append(users, userDTO.ToEntity())
+	}
+	return users, nil
+}
+
+func (r *repository) Tombstone(ctx context.Context, tombstone *userEntity.Tombstone, version int64) error {
+	// delete the document only at the expected version like Save replaces it
+	filter := bson.M{"_id": tombstone.ID, "version": version}
+	if version == 0 {
+		filter["version"] = nil
+	}
+	result, err := r.client.GetCollection().DeleteOne(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to tombstone user by ID %q: %w", tombstone.ID, err)
+	}
+	if result.DeletedCount == 0 {
+		if _, err := r.FindByID(ctx, tombstone.ID); err != nil {
+			return fmt.Errorf("mongodb: failed to tombstone user: %w", err)
+		}
+		return fmt.Errorf("mongodb: failed to tombstone user %q at version %d: %w", tombstone.ID, version, userEntity.ErrVersionConflict)
+	}
+	var tombstoneDTO userTombstone
+	tombstoneDTO.FromEntity(tombstone)
+	// a user merged away twice, after its id was reused, keeps the latest tombstone
+	_, err = tombstoneCollection(r.client.GetCollection()).ReplaceOne(ctx, bson.M{"_id": tombstoneDTO.ID}, tombstoneDTO, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to save tombstone of user %q: %w", tombstone.ID, err)
+	}
+	return nil
+}
+
+func (r *repository) FindTombstone(ctx context.Context, id string) (*userEntity.Tombstone, error) {
+	var tombstoneDTO userTombstone
+	err := tombstoneCollection(r.client.GetCollection()).FindOne(ctx, bson.M{"_id": id}).Decode(&tombstoneDTO)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("mongodb: failed to find tombstone by ID %q: %w", id, userEntity.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to find tombstone by ID %q: %w", id, err)
+	}
+	return tombstoneDTO.ToEntity(), nil
+}
+
+// isDuplicateNameKey reports whether err is a duplicate key error of the unique name key index
+func isDuplicateNameKey(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCodeWithMessage(duplicateKeyCode, "index: "+nameKeyIndex+" ")
+}
+
+// tombstoneCollection returns the collection of the tombstones of a user collection
+func tombstoneCollection(users *mongo.Collection) *mongo.Collection {
+	return users.Database().Collection(users.Name() + tombstoneCollectionSuffix)
+}
+
+// listFilterToBSON converts a list filter to a query filter, ignoring the limit
+func listFilterToBSON(listFilter userEntity.ListFilter) bson.M {
+	filter := bson.M{}
+	if listFilter.FirstName != "" {
+		filter["first_name"] = listFilter.FirstName
+	}
+	if listFilter.LastName != "" {
+		filter["last_name"] = listFilter.LastName
+	}
+	if listFilter.Email != "" {
+		filter["email"] = listFilter.Email
+	}
+	if listFilter.Status != "" {
+		filter["status"] = string(listFilter.Status)
+	}
+	if listFilter.AfterID != "" {
+		filter["_id"] = bson.M{"$gt": listFilter.AfterID}
+	}
+	return filter
+}
+
+// findOptions sorts by id and applies the limit of a list filter
+func findOptions(listFilter userEntity.ListFilter) *options.FindOptions {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	if listFilter.Limit > 0 {
+		opts.SetLimit(int64(listFilter.Limit))
+	}
+	return opts
+}
@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"time"
+
+	userEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	webhookEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+type webhookSubscription struct {
+	ID        string    `bson:"_id"`
+	URL       string    `bson:"url"`
+	Events    []string  `bson:"events,omitempty"`
+	Secret    string    `bson:"secret"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+func (s *webhookSubscription) ToEntity() *webhookEntity.Subscription {
+	subscription := &webhookEntity.Subscription{
+		ID:        s.ID,
+		URL:       s.URL,
+		Secret:    s.Secret,
+		CreatedAt: s.CreatedAt.UTC(),
+	}
+	for _, eventType := range s.Events {
+		subscription.Events = append(subscription.Events, userEntity.EventType(eventType))
+	}
+	return subscription
+}
+
+func (s *webhookSubscription) FromEntity(subscription *webhookEntity.Subscription) {
+	s.ID = subscription.ID
+	s.URL = subscription.URL
+	s.Secret = subscription.Secret
+	s.CreatedAt = subscription.CreatedAt
+	s.Events = nil
+	for _, eventType := range subscription.Events {
+		s.Events = append(s.Events, string(eventType))
+	}
+}
+
+type webhookDelivery struct {
+	ID             string           `bson:"_id"`
+	SubscriptionID string           `bson:"subscription_id"`
+	EventID        string           `bson:"event_id"`
+	EventType      string           `bson:"event_type"`
+	Payload        []byte           `bson:"payload"`
+	Status         string           `bson:"status"`
+	Attempts       []webhookAttempt `bson:"attempts,omitempty"`
+	NextAttemptAt  time.Time        `bson:"next_attempt_at"`
+	CreatedAt      time.Time        `bson:"created_at"`
+}
+
+type webhookAttempt struct {
+	At         time.Time `bson:"at"`
+	StatusCode int       `bson:"status_code,omitempty"`
+	Error      string    `bson:"error,omitempty"`
+}
+
+func (d *webhookDelivery) ToEntity() *webhookEntity.Delivery {
+	delivery := &webhookEntity.Delivery{
+		ID:             d.ID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      userEntity.EventType(d.EventType),
+		Payload:        d.Payload,
+		Status:         webhookEntity.DeliveryStatus(d.Status),
+		NextAttemptAt:  d.NextAttemptAt.UTC(),
+		CreatedAt:      d.CreatedAt.UTC(),
+	}
+	for _, attempt := range d.Attempts {
+		delivery.Attempts = append(delivery.Attempts, webhookEntity.Attempt{At: attempt.At.UTC(), StatusCode: attempt.StatusCode, Error: attempt.Error})
+	}
+	return delivery
+}
+
+func (d *webhookDelivery) FromEntity(delivery *webhookEntity.Delivery) {
+	d.ID = delivery.ID
+	d.SubscriptionID = delivery.SubscriptionID
+	d.EventID = delivery.EventID
+	d.EventType = string(delivery.EventType)
+	d.Payload = delivery.Payload
+	d.Status = string(delivery.Status)
+	d.NextAttemptAt = delivery.NextAttemptAt
+	d.CreatedAt = delivery.CreatedAt
+	d.Attempts = nil
+	for _, attempt := range delivery.Attempts {
+		d.Attempts = append(d.Attempts, webhookAttempt{At: attempt.At, StatusCode: attempt.StatusCode, Error: attempt.Error})
+	}
+}
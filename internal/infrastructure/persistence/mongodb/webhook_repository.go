@@ -0,0 +1,159 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	webhookEntity "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// webhookCollectionSuffix names the collection holding the webhook subscriptions of a user collection
+	webhookCollectionSuffix = "_webhooks"
+	// webhookDeliveryCollectionSuffix names the collection holding the webhook deliveries of a user collection
+	webhookDeliveryCollectionSuffix = "_webhook_deliveries"
+)
+
+type webhookSubscriptionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWebhookSubscriptionRepository creates a new webhook subscription repository using a MongoDB client.
+// Subscriptions are stored in the <collection>_webhooks collection.
+func NewWebhookSubscriptionRepository(client *MongoDBClient) webhookEntity.SubscriptionRepository {
+	return &webhookSubscriptionRepository{collection: webhookCollection(client.GetCollection())}
+}
+
+func webhookCollection(users *mongo.Collection) *mongo.Collection {
+	return users.Database().Collection(users.Name() + webhookCollectionSuffix)
+}
+
+func (r *webhookSubscriptionRepository) FindByID(ctx context.Context, id string) (*webhookEntity.Subscription, error) {
+	var subscriptionDTO webhookSubscription
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&subscriptionDTO)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("mongodb: failed to find webhook subscription by ID %q: %w", id, webhookEntity.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to find webhook subscription by ID %q: %w", id, err)
+	}
+	return subscriptionDTO.ToEntity(), nil
+}
+
+func (r *webhookSubscriptionRepository) Save(ctx context.Context, subscription *webhookEntity.Subscription) error {
+	var subscriptionDTO webhookSubscription
+	subscriptionDTO.FromEntity(subscription)
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": subscriptionDTO.ID}, subscriptionDTO, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to save webhook subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to delete webhook subscription by ID %q: %w", id, err)
+	}
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("mongodb: failed to delete webhook subscription by ID %q: %w", id, webhookEntity.ErrNotFound)
+	}
+	return nil
+}
+
+func (r *webhookSubscriptionRepository) List(ctx context.Context) ([]*webhookEntity.Subscription, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to list webhook subscriptions: %w", err)
+	}
+	var subscriptionDTOs []webhookSubscription
+	if err := cursor.All(ctx, &subscriptionDTOs); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode webhook subscriptions: %w", err)
+	}
+
+	subscriptions := make([]*webhookEntity.Subscription, 0, len(subscriptionDTOs))
+	for _, subscriptionDTO := range subscriptionDTOs {
+		subscriptions = append(subscriptions, subscriptionDTO.ToEntity())
+	}
+	return subscriptions, nil
+}
+
+type webhookDeliveryRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWebhookDeliveryRepository creates a new webhook delivery repository using a MongoDB client.
+// Deliveries are stored in the <collection>_webhook_deliveries collection.
+func NewWebhookDeliveryRepository(client *MongoDBClient) webhookEntity.DeliveryRepository {
+	return &webhookDeliveryRepository{collection: webhookDeliveryCollection(client.GetCollection())}
+}
+
+func webhookDeliveryCollection(users *mongo.Collection) *mongo.Collection {
+	return users.Database().Collection(users.Name() + webhookDeliveryCollectionSuffix)
+}
+
+func (r *webhookDeliveryRepository) Add(ctx context.Context, delivery *webhookEntity.Delivery) error {
+	var deliveryDTO webhookDelivery
+	deliveryDTO.FromEntity(delivery)
+
+	_, err := r.collection.InsertOne(ctx, deliveryDTO)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to add webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepository) Save(ctx context.Context, delivery *webhookEntity.Delivery) error {
+	var deliveryDTO webhookDelivery
+	deliveryDTO.FromEntity(delivery)
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": deliveryDTO.ID}, deliveryDTO, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("mongodb: failed to save webhook delivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookDeliveryRepository) Due(ctx context.Context, now time.Time, limit int) ([]*webhookEntity.Delivery, error) {
+	filter := bson.M{"status": string(webhookEntity.DeliveryPending), "next_attempt_at": bson.M{"$lte": now}}
+	opts := options.Find().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	return r.find(ctx, filter, opts)
+}
+
+func (r *webhookDeliveryRepository) ListBySubscriptionID(ctx context.Context, subscriptionID string, limit int) ([]*webhookEntity.Delivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	return r.find(ctx, bson.M{"subscription_id": subscriptionID}, opts)
+}
+
+func (r *webhookDeliveryRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*webhookEntity.Delivery, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb: failed to list webhook deliveries: %w", err)
+	}
+	var deliveryDTOs []webhookDelivery
+	if err := cursor.All(ctx, &deliveryDTOs); err != nil {
+		return nil, fmt.Errorf("mongodb: failed to decode webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]*webhookEntity.Delivery, 0, len(deliveryDTOs))
+	for _, deliveryDTO := range deliveryDTOs {
+		deliveries = append(deliveries, deliveryDTO.ToEntity())
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,108 @@
+// Package bulk contains the file formats used to import and export users in bulk.
+package bulk
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// Columns are the CSV header columns of a user
+var Columns = []string{"id", "first_name", "last_name", "email", "age", "date_of_birth", "phone",
+	"address_line1", "address_line2", "address_city", "address_region", "address_postal_code", "address_country", "locale", "time_zone", "status"}
+
+// CSVReader reads users from CSV with a header row, columns may be in any order
+type CSVReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// NewCSVReader creates a new CSV reader
+func NewCSVReader(r io.Reader) *CSVReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	return &CSVReader{reader: reader}
+}
+
+// Read reads the next user row
+func (r *CSVReader) Read() (userApplication.ImportRow, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return userApplication.ImportRow{}, err
+		}
+	}
+
+	record, err := r.reader.Read()
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		// malformed rows are reported and skipped
+		return userApplication.ImportRow{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+	}
+	if err != nil {
+		return userApplication.ImportRow{}, err
+	}
+	line, _ := r.reader.FieldPos(0)
+
+	user := &userDomain.User{
+		ID:        r.field(record, "id"),
+		FirstName: r.field(record, "first_name"),
+		LastName:  r.field(record, "last_name"),
+		Email:     r.field(record, "email"),
+		Phone:     r.field(record, "phone"),
+		Address: userDomain.Address{
+			Line1:      r.field(record, "address_line1"),
+			Line2:      r.field(record, "address_line2"),
+			City:       r.field(record, "address_city"),
+			Region:     r.field(record, "address_region"),
+			PostalCode: r.field(record, "address_postal_code"),
+			Country:    r.field(record, "address_country"),
+		},
+		Locale:   r.field(record, "locale"),
+		TimeZone: r.field(record, "time_zone"),
+	}
+	if age := r.field(record, "age"); age != "" {
+		if user.Age, err = strconv.Atoi(age); err != nil {
+			return userApplication.ImportRow{Line: line, Err: fmt.Errorf("age %q must be an integer", age)}, nil
+		}
+	}
+	if user.DateOfBirth, err = userDomain.ParseDateOfBirth(r.field(record, "date_of_birth")); err != nil {
+		return userApplication.ImportRow{Line: line, Err: err}, nil
+	}
+	return userApplication.ImportRow{Line: line, User: user}, nil
+}
+
+func (r *CSVReader) readHeader() error {
+	header, err := r.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return fmt.Errorf("bulk: failed to read CSV header: %w", err)
+	}
+	r.columns = make(map[string]int, len(header))
+	for i, column := range header {
+		r.columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))] = i
+	}
+	for _, column := range Columns {
+		if _, ok := r.columns[column]; ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("bulk: CSV header must contain at least one of %s", strings.Join(Columns, ", "))
+}
+
+func (r *CSVReader) field(record []string, column string) string {
+	i, ok := r.columns[column]
+	if !ok || i >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(unescapeFormula(record[i]))
+}
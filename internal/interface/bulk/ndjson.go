@@ -0,0 +1,57 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+)
+
+// MaxNDJSONLineBytes is the longest NDJSON line accepted, longer lines fail the read with bufio.ErrTooLong
+const MaxNDJSONLineBytes = 1 << 20
+
+// NDJSONReader reads users from newline delimited JSON, one user object per line
+type NDJSONReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewNDJSONReader creates a new NDJSON reader
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxNDJSONLineBytes)
+	return &NDJSONReader{scanner: scanner}
+}
+
+// Read reads the next user row, skipping blank lines
+func (r *NDJSONReader) Read() (userApplication.ImportRow, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := bytes.TrimSpace(r.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.DisallowUnknownFields()
+		var record Record
+		if err := decoder.Decode(&record); err != nil {
+			return userApplication.ImportRow{Line: r.line, Err: err}, nil
+		}
+		if decoder.More() {
+			return userApplication.ImportRow{Line: r.line, Err: fmt.Errorf("line must contain a single JSON object")}, nil
+		}
+		user, err := record.ToEntity()
+		if err != nil {
+			return userApplication.ImportRow{Line: r.line, Err: err}, nil
+		}
+		return userApplication.ImportRow{Line: r.line, User: user}, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return userApplication.ImportRow{}, fmt.Errorf("bulk: failed to read NDJSON line %d: %w", r.line+1, err)
+	}
+	return userApplication.ImportRow{}, io.EOF
+}
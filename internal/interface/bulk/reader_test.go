@@ -0,0 +1,76 @@
+package bulk
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+)
+
+func readAll(t *testing.T, reader userApplication.ImportRowReader) []userApplication.ImportRow {
+	t.Helper()
+	var rows []userApplication.ImportRow
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		if err != nil {
+			t.Fatalf("Read() unexpected error: %v", err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestCSVReader(t *testing.T) {
+	input := "email,first_name,last_name,age,id\n" +
+		"john@example.com,John,Doe,25,1\n" +
+		"jane@example.com,Jane,Doe,twenty,2\n" +
+		"\"broken,Jim,Doe,30,3\n"
+
+	rows := readAll(t, NewCSVReader(strings.NewReader(input)))
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].Line != 2 || rows[0].User.ID != "1" || rows[0].User.Email != "john@example.com" || rows[0].User.Age != 25 {
+		t.Errorf("unexpected first row %+v (%+v)", rows[0], rows[0].User)
+	}
+	if rows[1].Err == nil || rows[1].Line != 3 {
+		t.Errorf("expected age error on line 3, got %+v", rows[1])
+	}
+	if rows[2].Err == nil || rows[2].Line != 4 {
+		t.Errorf("expected parse error on line 4, got %+v", rows[2])
+	}
+}
+
+func TestCSVReader_InvalidHeader(t *testing.T) {
+	_, err := NewCSVReader(strings.NewReader("name,surname\nJohn,Doe\n")).Read()
+	if err == nil || errors.Is(err, io.EOF) {
+		t.Errorf("expected header error, got %v", err)
+	}
+}
+
+func TestNDJSONReader(t *testing.T) {
+	input := `{"id":"1","first_name":"John","last_name":"Doe","email":"john@example.com","age":25}` + "\n" +
+		"\n" +
+		`{"id":"2","age":"twenty"}` + "\n" +
+		`{"id":"3","nickname":"JD"}` + "\n"
+
+	rows := readAll(t, NewNDJSONReader(strings.NewReader(input)))
+
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].Line != 1 || rows[0].User.FirstName != "John" || rows[0].User.Age != 25 {
+		t.Errorf("unexpected first row %+v", rows[0])
+	}
+	if rows[1].Err == nil || rows[1].Line != 3 {
+		t.Errorf("expected type error on line 3, got %+v", rows[1])
+	}
+	if rows[2].Err == nil || rows[2].Line != 4 {
+		t.Errorf("expected unknown field error on line 4, got %+v", rows[2])
+	}
+}
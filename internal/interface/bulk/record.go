@@ -0,0 +1,85 @@
+package bulk
+
+import (
+	"strconv"
+	"time"
+
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// Record is the JSON and Parquet representation of a user in bulk files, the address is flattened like in CSV files
+type Record struct {
+	ID        string `json:"id" parquet:"id"`
+	FirstName string `json:"first_name" parquet:"first_name"`
+	LastName  string `json:"last_name" parquet:"last_name"`
+	Email     string `json:"email" parquet:"email"`
+	// DateOfBirth is in userDomain.DateLayout, it is empty for legacy users saved with an age only
+	DateOfBirth string `json:"date_of_birth,omitempty" parquet:"date_of_birth"`
+	// Age is ignored on import when DateOfBirth is set
+	Age               int    `json:"age" parquet:"age"`
+	Phone             string `json:"phone,omitempty" parquet:"phone"`
+	AddressLine1      string `json:"address_line1,omitempty" parquet:"address_line1"`
+	AddressLine2      string `json:"address_line2,omitempty" parquet:"address_line2"`
+	AddressCity       string `json:"address_city,omitempty" parquet:"address_city"`
+	AddressRegion     string `json:"address_region,omitempty" parquet:"address_region"`
+	AddressPostalCode string `json:"address_postal_code,omitempty" parquet:"address_postal_code"`
+	AddressCountry    string `json:"address_country,omitempty" parquet:"address_country"`
+	Locale            string `json:"locale,omitempty" parquet:"locale"`
+	TimeZone          string `json:"time_zone,omitempty" parquet:"time_zone"`
+	// Status is exported only, imported users are pending when new and keep their status otherwise
+	Status string `json:"status,omitempty" parquet:"status"`
+}
+
+// ToEntity converts a Record to a userDomain.User, it fails when the date of birth is not a date
+func (r *Record) ToEntity() (*userDomain.User, error) {
+	dateOfBirth, err := userDomain.ParseDateOfBirth(r.DateOfBirth)
+	if err != nil {
+		return nil, err
+	}
+	return &userDomain.User{
+		ID:          r.ID,
+		FirstName:   r.FirstName,
+		LastName:    r.LastName,
+		Email:       r.Email,
+		DateOfBirth: dateOfBirth,
+		Age:         r.Age,
+		Phone:       r.Phone,
+		Address: userDomain.Address{
+			Line1:      r.AddressLine1,
+			Line2:      r.AddressLine2,
+			City:       r.AddressCity,
+			Region:     r.AddressRegion,
+			PostalCode: r.AddressPostalCode,
+			Country:    r.AddressCountry,
+		},
+		Locale:   r.Locale,
+		TimeZone: r.TimeZone,
+	}, nil
+}
+
+// FromEntity converts a userDomain.User to a Record, the age being the user's age at now
+func (r *Record) FromEntity(user *userDomain.User, now time.Time) {
+	r.ID = user.ID
+	r.FirstName = user.FirstName
+	r.LastName = user.LastName
+	r.Email = user.Email
+	r.DateOfBirth = userDomain.FormatDateOfBirth(user.DateOfBirth)
+	// exports are a snapshot, the age is the age on the day of the export
+	r.Age = user.AgeAt(now)
+	r.Phone = user.Phone
+	r.AddressLine1 = user.Address.Line1
+	r.AddressLine2 = user.Address.Line2
+	r.AddressCity = user.Address.City
+	r.AddressRegion = user.Address.Region
+	r.AddressPostalCode = user.Address.PostalCode
+	r.AddressCountry = user.Address.Country
+	r.Locale = user.Locale
+	r.TimeZone = user.TimeZone
+	r.Status = string(user.Status)
+}
+
+// csvFields returns the fields of the record in the order of Columns
+func (r *Record) csvFields() []string {
+	return []string{r.ID, r.FirstName, r.LastName, r.Email, strconv.Itoa(r.Age), r.DateOfBirth, r.Phone,
+		r.AddressLine1, r.AddressLine2, r.AddressCity, r.AddressRegion, r.AddressPostalCode, r.AddressCountry, r.Locale, r.TimeZone, r.Status}
+}
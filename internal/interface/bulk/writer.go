@@ -0,0 +1,159 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// Format is a bulk export file format
+type Format string
+
+const (
+	FormatCSV     Format = "csv"
+	FormatNDJSON  Format = "ndjson"
+	FormatParquet Format = "parquet"
+)
+
+// parquetRowGroupSize bounds the rows buffered in memory before a Parquet row group is written
+const parquetRowGroupSize = 10000
+
+// ParseFormat parses a format name
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatCSV, FormatNDJSON, FormatParquet:
+		return format, nil
+	default:
+		return "", fmt.Errorf("bulk: unknown format %q, use csv, ndjson or parquet", name)
+	}
+}
+
+// ContentType returns the media type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	default:
+		return "application/vnd.apache.parquet"
+	}
+}
+
+// Writer writes users in a bulk export format
+type Writer interface {
+	// Write writes a single user
+	Write(user *userDomain.User) error
+	// Close flushes buffered users and writes any trailer, the underlying writer is not closed
+	Close() error
+}
+
+// NewWriter creates a writer for the format, the exported ages are the ages at now
+func NewWriter(format Format, w io.Writer, now time.Time) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, now), nil
+	case FormatNDJSON:
+		return &ndjsonWriter{encoder: json.NewEncoder(w), now: now}, nil
+	case FormatParquet:
+		return &parquetWriter{writer: parquet.NewGenericWriter[Record](w, parquet.MaxRowsPerRowGroup(parquetRowGroupSize)), now: now}, nil
+	default:
+		return nil, fmt.Errorf("bulk: unknown format %q", format)
+	}
+}
+
+type csvWriter struct {
+	writer        *csv.Writer
+	now           time.Time
+	headerWritten bool
+}
+
+func newCSVWriter(w io.Writer, now time.Time) *csvWriter {
+	return &csvWriter{writer: csv.NewWriter(w), now: now}
+}
+
+func (c *csvWriter) Write(user *userDomain.User) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	var record Record
+	record.FromEntity(user, c.now)
+	fields := record.csvFields()
+	for i, field := range fields {
+		fields[i] = escapeFormula(field)
+	}
+	return c.writer.Write(fields)
+}
+
+// formulaPrefixes are the first characters that make spreadsheets evaluate a cell as a formula
+const formulaPrefixes = "=+-@\t\r"
+
+// escapeFormula prefixes a cell spreadsheets would evaluate as a formula with a quote, CSVReader removes it
+func escapeFormula(field string) string {
+	if field != "" && strings.ContainsRune(formulaPrefixes, rune(field[0])) {
+		return "'" + field
+	}
+	return field
+}
+
+// unescapeFormula removes the quote escapeFormula prefixes a cell with
+func unescapeFormula(field string) string {
+	if len(field) > 1 && field[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(field[1])) {
+		return field[1:]
+	}
+	return field
+}
+
+func (c *csvWriter) Close() error {
+	// an empty export still gets a header
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.writer.Write(Columns)
+}
+
+type ndjsonWriter struct {
+	encoder *json.Encoder
+	now     time.Time
+}
+
+func (n *ndjsonWriter) Write(user *userDomain.User) error {
+	var record Record
+	record.FromEntity(user, n.now)
+	return n.encoder.Encode(record)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+type parquetWriter struct {
+	writer *parquet.GenericWriter[Record]
+	now    time.Time
+}
+
+func (p *parquetWriter) Write(user *userDomain.User) error {
+	var record Record
+	record.FromEntity(user, p.now)
+	_, err := p.writer.Write([]Record{record})
+	return err
+}
+
+func (p *parquetWriter) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,72 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestCSVWriter_EscapesFormulas(t *testing.T) {
+	user := &userDomain.User{ID: "1", FirstName: "=HYPERLINK(\"http://evil.example\")", LastName: "@SUM(A1)", Email: "john@example.com",
+		Phone: "+14155550123", Address: userDomain.Address{Line1: "-1 Main St", City: "\tBerlin", Region: "\rBE"}}
+	var buf bytes.Buffer
+	writer, _ := NewWriter(FormatCSV, &buf, time.Now())
+	if err := writer.Write(user); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	records, err := csv.NewReader(bytes.NewReader(buf.Bytes())).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read csv: %v", err)
+	}
+	expected := map[string]string{
+		"id":             "1",
+		"first_name":     "'=HYPERLINK(\"http://evil.example\")",
+		"last_name":      "'@SUM(A1)",
+		"email":          "john@example.com",
+		"phone":          "'+14155550123",
+		"address_line1":  "'-1 Main St",
+		"address_city":   "'\tBerlin",
+		"address_region": "'\rBE",
+	}
+	for i, column := range Columns {
+		if value, ok := expected[column]; ok && records[1][i] != value {
+			t.Errorf("column %s = %q, want %q", column, records[1][i], value)
+		}
+	}
+
+	// the quotes are removed when the export is imported again
+	rows := readAll(t, NewCSVReader(bytes.NewReader(buf.Bytes())))
+	if len(rows) != 1 || rows[0].Err != nil {
+		t.Fatalf("expected the exported row to be read, got %+v", rows)
+	}
+	imported := rows[0].User
+	if imported.FirstName != user.FirstName || imported.LastName != user.LastName || imported.Phone != user.Phone || imported.Address.Line1 != user.Address.Line1 {
+		t.Errorf("Read() = %+v, want the exported %+v", imported, user)
+	}
+}
+
+func TestNDJSONWriter_AgeAtExport(t *testing.T) {
+	user := &userDomain.User{ID: "1", DateOfBirth: time.Date(2000, 6, 15, 0, 0, 0, 0, time.UTC)}
+	var buf bytes.Buffer
+	writer, _ := NewWriter(FormatNDJSON, &buf, time.Date(2024, 6, 14, 12, 0, 0, 0, time.UTC))
+	if err := writer.Write(user); err != nil {
+		t.Fatalf("Write() unexpected error: %v", err)
+	}
+	writer.Close()
+
+	var record Record
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("failed to decode the record: %v", err)
+	}
+	if record.Age != 23 {
+		t.Errorf("Write() age = %d, want the age on the day before the birthday 23", record.Age)
+	}
+}
@@ -0,0 +1,54 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+type cborCodec struct {
+	decMode cbor.DecMode
+}
+
+// CBOR creates a codec for application/cbor that rejects unknown fields and trailing data
+func CBOR() Codec {
+	decMode, err := cbor.DecOptions{ExtraReturnErrors: cbor.ExtraDecErrorUnknownField}.DecMode()
+	if err != nil {
+		// the options are static so this only fails on a programming error
+		panic(fmt.Sprintf("codec: invalid CBOR decoding options: %v", err))
+	}
+	return cborCodec{decMode: decMode}
+}
+
+func (cborCodec) MediaTypes() []string {
+	return []string{"application/cbor"}
+}
+
+func (c cborCodec) Decode(r io.Reader, v any) error {
+	decoder := c.decMode.NewDecoder(r)
+
+	if err := decoder.Decode(v); err != nil {
+		var typeErr *cbor.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return ErrEmptyBody
+		case errors.As(err, &typeErr) && typeErr.StructFieldName != "":
+			// StructFieldName is reported as <go type>.<field>
+			field := typeErr.StructFieldName[strings.LastIndex(typeErr.StructFieldName, ".")+1:]
+			return &FieldError{Field: field, Reason: fmt.Sprintf("must be of type %s, got %s", typeErr.GoType, typeErr.CBORType), Err: err}
+		}
+		return err
+	}
+	var trailing cbor.RawMessage
+	if err := decoder.Decode(&trailing); !errors.Is(err, io.EOF) {
+		return errors.Join(ErrTrailingData, err)
+	}
+	return nil
+}
+
+func (cborCodec) Encode(w io.Writer, v any) error {
+	return cbor.NewEncoder(w).Encode(v)
+}
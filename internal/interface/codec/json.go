@@ -0,0 +1,53 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type jsonCodec struct{}
+
+// JSON creates a codec for application/json that rejects unknown fields and trailing data
+func JSON() Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) MediaTypes() []string {
+	return []string{"application/json"}
+}
+
+func (jsonCodec) Decode(r io.Reader, v any) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(v); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return ErrEmptyBody
+		case errors.As(err, &syntaxErr):
+			return fmt.Errorf("malformed JSON at position %d: %w", syntaxErr.Offset, err)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return fmt.Errorf("malformed JSON: %w", err)
+		case errors.As(err, &typeErr) && typeErr.Field != "":
+			return &FieldError{Field: typeErr.Field, Reason: fmt.Sprintf("must be of type %s, got %s", typeErr.Type, typeErr.Value), Err: err}
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			// encoding/json does not export a type for unknown fields
+			field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+			return &FieldError{Field: field, Reason: "is not a known field", Err: err}
+		}
+		return err
+	}
+	if err := decoder.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		return errors.Join(ErrTrailingData, err)
+	}
+	return nil
+}
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
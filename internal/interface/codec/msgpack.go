@@ -0,0 +1,47 @@
+package codec
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type msgpackCodec struct{}
+
+// MessagePack creates a codec for application/msgpack that rejects unknown fields and trailing data
+func MessagePack() Codec {
+	return msgpackCodec{}
+}
+
+func (msgpackCodec) MediaTypes() []string {
+	return []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"}
+}
+
+func (msgpackCodec) Decode(r io.Reader, v any) error {
+	decoder := msgpack.NewDecoder(r)
+	decoder.DisallowUnknownFields(true)
+
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrEmptyBody
+		}
+		if field, ok := strings.CutPrefix(err.Error(), "msgpack: unknown field "); ok {
+			if unquoted, unquoteErr := strconv.Unquote(field); unquoteErr == nil {
+				field = unquoted
+			}
+			return &FieldError{Field: field, Reason: "is not a known field", Err: err}
+		}
+		return err
+	}
+	if err := decoder.Skip(); !errors.Is(err, io.EOF) {
+		return errors.Join(ErrTrailingData, err)
+	}
+	return nil
+}
+
+func (msgpackCodec) Encode(w io.Writer, v any) error {
+	return msgpack.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,190 @@
+// Package codec contains the content negotiation for the api layer.
+package codec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// DefaultMaxBodyBytes is the default request body limit
+const DefaultMaxBodyBytes = shared.DefaultMaxBodyBytes
+
+var (
+	// ErrEmptyBody is returned when the request body is empty
+	ErrEmptyBody = shared.ErrEmptyBody
+	// ErrTrailingData is returned when the request body contains more than one value
+	ErrTrailingData = shared.ErrTrailingData
+)
+
+// FieldError is returned when a value could not be decoded into a field
+type FieldError = shared.FieldError
+
+// Codec reads and writes a single media type
+type Codec interface {
+	// MediaTypes returns the media types handled by the codec, the first one is used for responses
+	MediaTypes() []string
+	// Decode strictly decodes a single value from r into v
+	Decode(r io.Reader, v any) error
+	// Encode encodes v to w
+	Encode(w io.Writer, v any) error
+}
+
+// Registry selects the request decoder from the Content-Type header and the response encoder from the Accept header
+type Registry struct {
+	codecs      []Codec
+	byMediaType map[string]Codec
+	decoder     *shared.RequestDecoder
+}
+
+// NewRegistry creates a new registry, the first codec is used when the client accepts any media type
+func NewRegistry(maxBodyBytes int64, codecs ...Codec) *Registry {
+	r := &Registry{
+		codecs:      codecs,
+		byMediaType: make(map[string]Codec),
+		decoder:     shared.NewRequestDecoder(maxBodyBytes),
+	}
+	for _, codec := range codecs {
+		for _, mediaType := range codec.MediaTypes() {
+			r.byMediaType[mediaType] = codec
+		}
+	}
+	return r
+}
+
+// NewDefaultRegistry creates a registry for JSON, MessagePack, CBOR and XML with JSON as the default
+func NewDefaultRegistry() *Registry {
+	return NewRegistry(DefaultMaxBodyBytes, JSON(), MessagePack(), CBOR(), XML())
+}
+
+// Decode decodes the request body into dst with the codec of its Content-Type,
+// returning a *shared.ProblemError when the request is rejected
+func (r *Registry) Decode(w http.ResponseWriter, req *http.Request, dst any) error {
+	codec, err := r.decoderFor(req.Header.Get("Content-Type"))
+	if err != nil {
+		return err
+	}
+	return r.decoder.Decode(w, req, codec.MediaTypes()[0], func(body io.Reader) error {
+		return codec.Decode(body, dst)
+	})
+}
+
+// Negotiate selects the response codec from the Accept header, returning a *shared.ProblemError
+// when none of the accepted media types is supported
+func (r *Registry) Negotiate(req *http.Request) (Codec, error) {
+	accept := req.Header.Get("Accept")
+	if accept == "" {
+		return r.codecs[0], nil
+	}
+	for _, mediaRange := range parseAccept(accept) {
+		switch {
+		case mediaRange == "*/*":
+			return r.codecs[0], nil
+		case strings.HasSuffix(mediaRange, "/*"):
+			prefix := strings.TrimSuffix(mediaRange, "*")
+			for _, codec := range r.codecs {
+				for _, mediaType := range codec.MediaTypes() {
+					if strings.HasPrefix(mediaType, prefix) {
+						return codec, nil
+					}
+				}
+			}
+		default:
+			if codec, ok := r.byMediaType[mediaRange]; ok {
+				return codec, nil
+			}
+		}
+	}
+	return nil, problemError(http.StatusNotAcceptable, fmt.Sprintf("none of the accepted media types are supported, use one of %s", strings.Join(r.mediaTypes(), ", ")), nil)
+}
+
+// Encode writes v with the given status using the codec negotiated from the Accept header
+func (r *Registry) Encode(w http.ResponseWriter, req *http.Request, status int, v any) error {
+	codec, err := r.Negotiate(req)
+	if err != nil {
+		return err
+	}
+	return Write(w, codec, status, v)
+}
+
+// Write encodes v with the codec and writes it with the given status
+func Write(w http.ResponseWriter, codec Codec, status int, v any) error {
+	// encode before writing the header so encoding failures can still be reported
+	var buf bytes.Buffer
+	if err := codec.Encode(&buf, v); err != nil {
+		return fmt.Errorf("codec: failed to encode response: %w", err)
+	}
+	w.Header().Set("Content-Type", codec.MediaTypes()[0])
+	w.Header().Add("Vary", "Accept")
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (r *Registry) decoderFor(contentType string) (Codec, error) {
+	if contentType == "" {
+		return nil, problemError(http.StatusUnsupportedMediaType, fmt.Sprintf("Content-Type header is required, use one of %s", strings.Join(r.mediaTypes(), ", ")), nil)
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		if codec, ok := r.byMediaType[mediaType]; ok {
+			return codec, nil
+		}
+		if strings.HasSuffix(mediaType, "+json") {
+			if codec, ok := r.byMediaType["application/json"]; ok {
+				return codec, nil
+			}
+		}
+	}
+	return nil, problemError(http.StatusUnsupportedMediaType, fmt.Sprintf("Content-Type %q is not supported, use one of %s", contentType, strings.Join(r.mediaTypes(), ", ")), err)
+}
+
+func (r *Registry) mediaTypes() []string {
+	mediaTypes := make([]string, 0, len(r.codecs))
+	for _, codec := range r.codecs {
+		mediaTypes = append(mediaTypes, codec.MediaTypes()[0])
+	}
+	return mediaTypes
+}
+
+// parseAccept returns the media ranges of an Accept header ordered by preference, dropping q=0 entries
+func parseAccept(accept string) []string {
+	type mediaRange struct {
+		value string
+		q     float64
+	}
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q > 0 {
+			ranges = append(ranges, mediaRange{value: mediaType, q: q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	values := make([]string, len(ranges))
+	for i, r := range ranges {
+		values[i] = r.value
+	}
+	return values
+}
+
+func problemError(status int, detail string, err error) error {
+	return &shared.ProblemError{Problem: shared.NewProblem(status, detail), Err: err}
+}
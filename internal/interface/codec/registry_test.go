@@ -0,0 +1,128 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+type testPayload struct {
+	Name string `json:"name" msgpack:"name" cbor:"name" xml:"name"`
+	Age  int    `json:"age" msgpack:"age" cbor:"age" xml:"age"`
+}
+
+func TestRegistry_Negotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		accept         string
+		expectedType   string
+		expectedStatus int
+	}{
+		{name: "no accept header", accept: "", expectedType: "application/json"},
+		{name: "any media type", accept: "*/*", expectedType: "application/json"},
+		{name: "exact media type", accept: "application/cbor", expectedType: "application/cbor"},
+		{name: "media type alias", accept: "application/x-msgpack", expectedType: "application/msgpack"},
+		{name: "quality ordering", accept: "application/json;q=0.5, application/xml", expectedType: "application/xml"},
+		{name: "subtype wildcard", accept: "text/*", expectedType: "application/xml"},
+		{name: "skips unsupported types", accept: "text/html, application/msgpack;q=0.8", expectedType: "application/msgpack"},
+		{name: "not acceptable", accept: "text/html", expectedStatus: http.StatusNotAcceptable},
+		{name: "q=0 excludes the type", accept: "application/json;q=0", expectedStatus: http.StatusNotAcceptable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			codec, err := NewDefaultRegistry().Negotiate(req)
+			if test.expectedStatus != 0 {
+				var problemErr *shared.ProblemError
+				if !errors.As(err, &problemErr) || problemErr.Problem.Status != test.expectedStatus {
+					t.Errorf("Negotiate() error = %v, want status %d", err, test.expectedStatus)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Negotiate() unexpected error: %v", err)
+			}
+			if got := codec.MediaTypes()[0]; got != test.expectedType {
+				t.Errorf("Negotiate() = %s, want %s", got, test.expectedType)
+			}
+		})
+	}
+}
+
+func TestRegistry_RoundTrip(t *testing.T) {
+	for _, codec := range []Codec{JSON(), MessagePack(), CBOR(), XML()} {
+		t.Run(codec.MediaTypes()[0], func(t *testing.T) {
+			want := testPayload{Name: "John", Age: 25}
+			var body bytes.Buffer
+			if err := codec.Encode(&body, want); err != nil {
+				t.Fatalf("Encode() unexpected error: %v", err)
+			}
+
+			req := httptest.NewRequest("POST", "/", &body)
+			req.Header.Set("Content-Type", codec.MediaTypes()[0])
+			var got testPayload
+			if err := NewDefaultRegistry().Decode(httptest.NewRecorder(), req, &got); err != nil {
+				t.Fatalf("Decode() unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("Decode() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestRegistry_Decode_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           []byte
+		maxBodyBytes   int64
+		expectedStatus int
+		expectedParam  string
+	}{
+		{name: "missing content type", body: []byte(`{}`), expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "unsupported content type", contentType: "text/plain", body: []byte(`{}`), expectedStatus: http.StatusUnsupportedMediaType},
+		{name: "empty body", contentType: "application/json", expectedStatus: http.StatusBadRequest},
+		{name: "json type mismatch", contentType: "application/json", body: []byte(`{"age":"twenty"}`), expectedStatus: http.StatusBadRequest, expectedParam: "age"},
+		{name: "json unknown field", contentType: "application/json", body: []byte(`{"nickname":"JD"}`), expectedStatus: http.StatusBadRequest, expectedParam: "nickname"},
+		{name: "json trailing data", contentType: "application/json", body: []byte(`{} {}`), expectedStatus: http.StatusBadRequest},
+		{name: "json body too large", contentType: "application/json", body: []byte(`{"name":"` + strings.Repeat("a", 64) + `"}`), maxBodyBytes: 16, expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "msgpack unknown field", contentType: "application/msgpack", body: []byte{0x81, 0xa4, 'n', 'i', 'c', 'k', 0xa2, 'J', 'D'}, expectedStatus: http.StatusBadRequest, expectedParam: "nick"},
+		{name: "cbor type mismatch", contentType: "application/cbor", body: []byte{0xa1, 0x63, 'a', 'g', 'e', 0x62, 't', 'w'}, expectedStatus: http.StatusBadRequest, expectedParam: "age"},
+		{name: "xml trailing data", contentType: "application/xml", body: []byte(`<p><name>a</name></p><p></p>`), expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", bytes.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			registry := NewRegistry(test.maxBodyBytes, JSON(), MessagePack(), CBOR(), XML())
+
+			var payload testPayload
+			err := registry.Decode(httptest.NewRecorder(), req, &payload)
+
+			var problemErr *shared.ProblemError
+			if !errors.As(err, &problemErr) {
+				t.Fatalf("Decode() error = %v, want problem error", err)
+			}
+			if problemErr.Problem.Status != test.expectedStatus {
+				t.Errorf("Decode() status = %d, want %d (%v)", problemErr.Problem.Status, test.expectedStatus, err)
+			}
+			params := problemErr.Problem.InvalidParams
+			if test.expectedParam != "" && (len(params) != 1 || params[0].Name != test.expectedParam) {
+				t.Errorf("Decode() invalid params = %v, want %q", params, test.expectedParam)
+			}
+		})
+	}
+}
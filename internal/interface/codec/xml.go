@@ -0,0 +1,56 @@
+package codec
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+type xmlCodec struct{}
+
+// XML creates a codec for application/xml that rejects trailing data.
+// encoding/xml cannot report unknown elements, so those are ignored.
+func XML() Codec {
+	return xmlCodec{}
+}
+
+func (xmlCodec) MediaTypes() []string {
+	return []string{"application/xml", "text/xml"}
+}
+
+func (xmlCodec) Decode(r io.Reader, v any) error {
+	decoder := xml.NewDecoder(r)
+
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, io.EOF) {
+			return ErrEmptyBody
+		}
+		return err
+	}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errors.Join(ErrTrailingData, err)
+		}
+		switch token := token.(type) {
+		case xml.Comment, xml.ProcInst:
+		case xml.CharData:
+			if strings.TrimSpace(string(token)) != "" {
+				return ErrTrailingData
+			}
+		default:
+			return ErrTrailingData
+		}
+	}
+}
+
+func (xmlCodec) Encode(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return xml.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,91 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain is the domain of the google.rpc.ErrorInfo details
+const errorDomain = "tag-onboarding"
+
+// toStatus maps a domain error to a gRPC status error.
+// Validation errors are returned with an ErrorInfo detail per ValidationError, its metadata holding the message,
+// field, severity and the rule params prefixed with param_, followed by a BadRequest detail of the field violations.
+// Rejected values are never returned. Users merged into another user are not found, with an ErrorInfo detail
+// holding the survivor.
+// Statuses carry a fixed message rather than the wrapped error, errors without a status of their own are logged
+// with the request ID and reported as internal errors, their message may describe the storage.
+func toStatus(ctx context.Context, err error) error {
+	if validationErrors := shared.ValidationErrors(err); len(validationErrors) > 0 {
+		details := make([]protoadapt.MessageV1, 0, len(validationErrors)+1)
+		badRequest := &errdetails.BadRequest{}
+		for _, validationError := range validationErrors {
+			metadata := map[string]string{
+				"message":  validationError.Message,
+				"field":    validationError.Field,
+				"severity": string(validationError.Severity),
+			}
+			for name, value := range validationError.Params {
+				metadata["param_"+name] = value
+			}
+			details = append(details, &errdetails.ErrorInfo{
+				Reason:   validationError.Code,
+				Domain:   errorDomain,
+				Metadata: metadata,
+			})
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       validationError.Field,
+				Description: validationError.Message,
+			})
+		}
+		details = append(details, badRequest)
+		st, detailErr := status.New(codes.InvalidArgument, "user is invalid").WithDetails(details...)
+		if detailErr != nil {
+			return status.Error(codes.InvalidArgument, "user is invalid")
+		}
+		return st.Err()
+	}
+
+	var mergedErr *userDomain.MergedError
+	switch {
+	case errors.As(err, &mergedErr):
+		// clients follow the merge to the survivor with the merged_into metadata
+		st, detailErr := status.New(codes.NotFound, userDomain.ErrMerged.Error()).WithDetails(&errdetails.ErrorInfo{
+			Reason:   "USER_MERGED",
+			Domain:   errorDomain,
+			Metadata: map[string]string{"merged_into": mergedErr.MergedInto},
+		})
+		if detailErr != nil {
+			return status.Error(codes.NotFound, userDomain.ErrMerged.Error())
+		}
+		return st.Err()
+	case errors.Is(err, userDomain.ErrNotFound):
+		return status.Error(codes.NotFound, userDomain.ErrNotFound.Error())
+	case errors.Is(err, userDomain.ErrAlreadyExists):
+		return status.Error(codes.AlreadyExists, userDomain.ErrAlreadyExists.Error())
+	case errors.Is(err, userDomain.ErrNameCombinationExists):
+		return status.Error(codes.AlreadyExists, userDomain.ErrNameCombinationExists.Error())
+	case errors.Is(err, userDomain.ErrPossibleDuplicate):
+		return status.Error(codes.AlreadyExists, userDomain.ErrPossibleDuplicate.Error())
+	case errors.Is(err, userDomain.ErrVersionConflict):
+		return status.Error(codes.Aborted, userDomain.ErrVersionConflict.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, context.Canceled.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, context.DeadlineExceeded.Error())
+	default:
+		method, _ := grpc.Method(ctx)
+		log.Printf(`{"level":"error","msg":"request failed","method":"%s","request_id":"%s","error":%q}`,
+			method, shared.RequestIDFromContext(ctx), err.Error())
+		return status.Error(codes.Internal, "internal error")
+	}
+}
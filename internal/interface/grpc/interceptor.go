@@ -0,0 +1,39 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	interfaceShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	requestIDMetadata = "x-request-id"
+	actorMetadata     = "x-actor"
+)
+
+// RequestContextInterceptor adds the request ID and actor from the x-request-id and x-actor metadata to the context.
+// A request ID is generated when none or an invalid one is sent, as over HTTP, and is returned in the response header.
+func RequestContextInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	requestID := firstMetadataValue(md, requestIDMetadata)
+	if !interfaceShared.ValidRequestID(requestID) {
+		requestID = interfaceShared.NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, requestID))
+
+	ctx = shared.WithRequestID(ctx, requestID)
+	if actor := firstMetadataValue(md, actorMetadata); actor != "" {
+		ctx = shared.WithActor(ctx, actor)
+	}
+	return handler(ctx, req)
+}
+
+func firstMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,44 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestContextInterceptor(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestID         string
+		expectedRequestID string
+	}{
+		{name: "request id propagated", requestID: "request-1", expectedRequestID: "request-1"},
+		{name: "request id generated"},
+		{name: "invalid request id replaced", requestID: "bad id\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.requestID != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(requestIDMetadata, test.requestID))
+			}
+
+			var requestID string
+			RequestContextInterceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+				requestID = shared.RequestIDFromContext(ctx)
+				return nil, nil
+			})
+
+			if test.expectedRequestID != "" && requestID != test.expectedRequestID {
+				t.Errorf("request ID = %q, want %q", requestID, test.expectedRequestID)
+			}
+			if requestID == "" || requestID == test.requestID && test.expectedRequestID == "" {
+				t.Errorf("request ID = %q, want a generated id", requestID)
+			}
+		})
+	}
+}
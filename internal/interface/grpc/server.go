@@ -0,0 +1,188 @@
+// Package grpc contains the gRPC api layer for the user domain.
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	userv1 "github.com/surajswarnapuri/ps-tag-onboarding-go/api/user/v1"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type userApplicationService interface {
+	// Find finds a user by id
+	Find(ctx context.Context, id string) (*userDomain.User, error)
+	// Create creates a new user
+	Create(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	// Update updates an existing user
+	Update(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	// List lists the users matching the filter
+	List(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error)
+}
+
+// Server is the gRPC server for the user domain
+type Server struct {
+	userv1.UnimplementedUserServiceServer
+	userService userApplicationService
+	now         func() time.Time
+}
+
+// ServerOption configures the server
+type ServerOption func(s *Server)
+
+// WithClock sets the clock the ages of users are computed with, it is time.Now by default
+func WithClock(now func() time.Time) ServerOption {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// NewServer creates a new gRPC server for the user domain
+func NewServer(userService userApplicationService, opts ...ServerOption) *Server {
+	s := &Server{userService: userService, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Register registers the user service on the gRPC server
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	userv1.RegisterUserServiceServer(registrar, s)
+}
+
+// GetUser returns a user by id
+func (s *Server) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	user, err := s.userService.Find(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toProto(user, s.now()), nil
+}
+
+// CreateUser creates a new user
+func (s *Server) CreateUser(ctx context.Context, req *userv1.CreateUserRequest) (*userv1.User, error) {
+	if req.GetUser() == nil {
+		return nil, status.Error(codes.InvalidArgument, "user is required")
+	}
+	user, err := s.userService.Create(ctx, toEntity(req.GetUser()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toProto(user, s.now()), nil
+}
+
+// UpdateUser replaces an existing user at the version of the request, keeping the date of birth and profile fields
+// the api cannot set
+func (s *Server) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.User, error) {
+	if req.GetUser().GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user id is required")
+	}
+	userToUpdate := toEntity(req.GetUser())
+	currentUser, err := s.userService.Find(ctx, userToUpdate.ID)
+	switch {
+	case err == nil:
+		userToUpdate.DateOfBirth = currentUser.DateOfBirth
+		userToUpdate.Phone, userToUpdate.Address = currentUser.Phone, currentUser.Address
+		userToUpdate.Locale, userToUpdate.TimeZone = currentUser.Locale, currentUser.TimeZone
+	case !errors.Is(err, userDomain.ErrNotFound):
+		return nil, toStatus(ctx, err)
+	}
+	user, err := s.userService.Update(ctx, userToUpdate)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toProto(user, s.now()), nil
+}
+
+// ListUsers lists users ordered by id
+func (s *Server) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size must not be negative")
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+	afterID, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "page_token is invalid")
+	}
+
+	filter := userDomain.ListFilter{
+		FirstName: req.GetFirstName(),
+		LastName:  req.GetLastName(),
+		Email:     req.GetEmail(),
+		AfterID:   afterID,
+		// fetch one extra user to know whether there is a next page
+		Limit: pageSize + 1,
+	}
+	if req.GetStatus() != "" {
+		filter.Status, err = userDomain.ParseStatus(req.GetStatus())
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, "status must be one of pending, verified, active, suspended or offboarded")
+		}
+	}
+	users, err := s.userService.List(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	response := &userv1.ListUsersResponse{}
+	if len(users) > pageSize {
+		users = users[:pageSize]
+		response.NextPageToken = encodePageToken(users[pageSize-1].ID)
+	}
+	for _, user := range users {
+		response.Users = append(response.Users, toProto(user, s.now()))
+	}
+	return response, nil
+}
+
+func encodePageToken(lastID string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(lastID))
+}
+
+func decodePageToken(token string) (string, error) {
+	lastID, err := base64.RawURLEncoding.DecodeString(token)
+	return string(lastID), err
+}
+
+func toProto(user *userDomain.User, now time.Time) *userv1.User {
+	return &userv1.User{
+		Id:        user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Email:     user.Email,
+		Version:   user.Version,
+		Status:    string(user.Status),
+		// the api has no date of birth, it returns the current age of users with one
+		Age: int32(user.AgeAt(now)),
+	}
+}
+
+func toEntity(user *userv1.User) *userDomain.User {
+	return &userDomain.User{
+		ID:        user.GetId(),
+		FirstName: user.GetFirstName(),
+		LastName:  user.GetLastName(),
+		Email:     user.GetEmail(),
+		Age:       int(user.GetAge()),
+		Version:   user.GetVersion(),
+	}
+}
@@ -0,0 +1,326 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	userv1 "github.com/surajswarnapuri/ps-tag-onboarding-go/api/user/v1"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type mockUserApplicationService struct {
+	FindFunc   func(ctx context.Context, id string) (*userDomain.User, error)
+	CreateFunc func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	UpdateFunc func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	ListFunc   func(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error)
+}
+
+func (m *mockUserApplicationService) Find(ctx context.Context, id string) (*userDomain.User, error) {
+	return m.FindFunc(ctx, id)
+}
+func (m *mockUserApplicationService) Create(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+	return m.CreateFunc(ctx, user)
+}
+func (m *mockUserApplicationService) Update(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+	return m.UpdateFunc(ctx, user)
+}
+func (m *mockUserApplicationService) List(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error) {
+	return m.ListFunc(ctx, filter)
+}
+
+// newTestClient starts the server on an in-memory listener and returns a client connected to it
+func newTestClient(t *testing.T, userService userApplicationService, opts ...ServerOption) userv1.UserServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	NewServer(userService, opts...).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return userv1.NewUserServiceClient(conn)
+}
+
+func TestServer_GetUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		id           string
+		findFunc     func(ctx context.Context, id string) (*userDomain.User, error)
+		expectedCode codes.Code
+	}{
+		{
+			name: "user found",
+			id:   "1",
+			findFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+				return &userDomain.User{ID: id, FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}, nil
+			},
+			expectedCode: codes.OK,
+		},
+		{
+			name: "user not found",
+			id:   "2",
+			findFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+				return nil, fmt.Errorf("service: %w", userDomain.ErrNotFound)
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name:         "missing id",
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, &mockUserApplicationService{FindFunc: test.findFunc})
+			user, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: test.id})
+
+			if code := status.Code(err); code != test.expectedCode {
+				t.Fatalf("GetUser() code = %v, want %v (%v)", code, test.expectedCode, err)
+			}
+			if test.expectedCode == codes.OK && user.GetId() != test.id {
+				t.Errorf("GetUser() id = %v, want %v", user.GetId(), test.id)
+			}
+		})
+	}
+}
+
+func TestServer_GetUser_Age(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	client := newTestClient(t, &mockUserApplicationService{
+		FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			return &userDomain.User{ID: id, FirstName: "John", LastName: "Doe", DateOfBirth: time.Date(2000, 6, 15, 0, 0, 0, 0, time.UTC)}, nil
+		},
+	}, WithClock(func() time.Time { return now }))
+
+	user, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: "1"})
+
+	if err != nil {
+		t.Fatalf("GetUser() error = %v", err)
+	}
+	if user.GetAge() != 24 {
+		t.Errorf("GetUser() age = %d, want the age on the clock's day 24", user.GetAge())
+	}
+}
+
+func TestServer_GetUser_MergedUser(t *testing.T) {
+	client := newTestClient(t, &mockUserApplicationService{
+		FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			return nil, fmt.Errorf("service: %w", &userDomain.MergedError{ID: id, MergedInto: "1"})
+		},
+	})
+
+	_, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: "2"})
+
+	st := status.Convert(err)
+	if st.Code() != codes.NotFound {
+		t.Fatalf("GetUser() code = %v, want %v", st.Code(), codes.NotFound)
+	}
+	details := st.Details()
+	if len(details) != 1 {
+		t.Fatalf("GetUser() details = %v, want an ErrorInfo", details)
+	}
+	if info, ok := details[0].(*errdetails.ErrorInfo); !ok || info.Reason != "USER_MERGED" || info.Metadata["merged_into"] != "1" {
+		t.Errorf("GetUser() details = %v, want the survivor 1", details)
+	}
+}
+
+func TestServer_GetUser_InternalError(t *testing.T) {
+	client := newTestClient(t, &mockUserApplicationService{
+		FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			return nil, fmt.Errorf("service: failed to find user %q: %w", id, errors.New("mongo: connection refused"))
+		},
+	})
+
+	_, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: "1"})
+
+	st := status.Convert(err)
+	if st.Code() != codes.Internal {
+		t.Fatalf("GetUser() code = %v, want %v", st.Code(), codes.Internal)
+	}
+	if st.Message() != "internal error" {
+		t.Errorf("GetUser() message = %q, want the storage error kept out of the status", st.Message())
+	}
+}
+
+func TestServer_GetUser_NotFoundMessage(t *testing.T) {
+	client := newTestClient(t, &mockUserApplicationService{
+		FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			return nil, fmt.Errorf("service: failed to find user %q: %w", id, userDomain.ErrNotFound)
+		},
+	})
+
+	_, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: "1"})
+
+	if st := status.Convert(err); st.Message() != userDomain.ErrNotFound.Error() {
+		t.Errorf("GetUser() message = %q, want %q", st.Message(), userDomain.ErrNotFound.Error())
+	}
+}
+
+func TestServer_CreateUser_ValidationErrorDetails(t *testing.T) {
+	client := newTestClient(t, &mockUserApplicationService{
+		CreateFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+			return nil, fmt.Errorf("service: failed to validate user: %w",
+				errors.Join(userDomain.NewAgeMinimumError(12, 18), userDomain.NewEmailRequiredError()))
+		},
+	})
+
+	_, err := client.CreateUser(context.Background(), &userv1.CreateUserRequest{User: &userv1.User{FirstName: "John"}})
+
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("CreateUser() code = %v, want %v", st.Code(), codes.InvalidArgument)
+	}
+	var reasons, fields []string
+	for _, detail := range st.Details() {
+		switch detail := detail.(type) {
+		case *errdetails.ErrorInfo:
+			reasons = append(reasons, detail.GetReason())
+			if detail.GetReason() == userDomain.ErrorAgeMinimum && detail.GetMetadata()["param_min"] != "18" {
+				t.Errorf("CreateUser() %s metadata = %v, want param_min 18", detail.GetReason(), detail.GetMetadata())
+			}
+		case *errdetails.BadRequest:
+			for _, violation := range detail.GetFieldViolations() {
+				fields = append(fields, violation.GetField())
+			}
+		}
+	}
+	if len(reasons) != 2 || reasons[0] != userDomain.ErrorAgeMinimum || reasons[1] != userDomain.ErrorEmailRequired {
+		t.Errorf("CreateUser() error reasons = %v, want [%s %s]", reasons, userDomain.ErrorAgeMinimum, userDomain.ErrorEmailRequired)
+	}
+	if len(fields) != 2 || fields[0] != userDomain.FieldAge || fields[1] != userDomain.FieldEmail {
+		t.Errorf("CreateUser() field violations = %v, want [%s %s]", fields, userDomain.FieldAge, userDomain.FieldEmail)
+	}
+}
+
+func TestServer_UpdateUser(t *testing.T) {
+	tests := []struct {
+		name         string
+		user         *userv1.User
+		updateErr    error
+		expectedCode codes.Code
+	}{
+		{name: "user updated", user: &userv1.User{Id: "1", FirstName: "John", Version: 3}, expectedCode: codes.OK},
+		{name: "stale version", user: &userv1.User{Id: "1", Version: 2}, updateErr: userDomain.ErrVersionConflict, expectedCode: codes.Aborted},
+		{name: "missing id", user: &userv1.User{FirstName: "John"}, expectedCode: codes.InvalidArgument},
+		{name: "user not found", user: &userv1.User{Id: "1"}, updateErr: userDomain.ErrNotFound, expectedCode: codes.NotFound},
+		{name: "name combination exists", user: &userv1.User{Id: "1"}, updateErr: userDomain.ErrNameCombinationExists, expectedCode: codes.AlreadyExists},
+		{name: "possible duplicate", user: &userv1.User{Id: "1"}, updateErr: &userDomain.DuplicateError{}, expectedCode: codes.AlreadyExists},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dateOfBirth := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+			client := newTestClient(t, &mockUserApplicationService{
+				FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return &userDomain.User{ID: id, DateOfBirth: dateOfBirth}, nil
+				},
+				UpdateFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					if test.updateErr != nil {
+						return nil, test.updateErr
+					}
+					if !user.DateOfBirth.Equal(dateOfBirth) {
+						t.Errorf("expected the stored date of birth to be kept, got %v", user.DateOfBirth)
+					}
+					if user.Version != test.user.GetVersion() {
+						t.Errorf("expected the update at version %d, got %d", test.user.GetVersion(), user.Version)
+					}
+					return user, nil
+				},
+			})
+			_, err := client.UpdateUser(context.Background(), &userv1.UpdateUserRequest{User: test.user})
+
+			if code := status.Code(err); code != test.expectedCode {
+				t.Errorf("UpdateUser() code = %v, want %v (%v)", code, test.expectedCode, err)
+			}
+		})
+	}
+}
+
+func TestServer_ListUsers_Pagination(t *testing.T) {
+	users := []*userDomain.User{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	client := newTestClient(t, &mockUserApplicationService{
+		ListFunc: func(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error) {
+			var page []*userDomain.User
+			for _, user := range users {
+				if user.ID > filter.AfterID && len(page) < filter.Limit {
+					page = append(page, user)
+				}
+			}
+			return page, nil
+		},
+	})
+
+	var ids []string
+	pageToken := ""
+	for pages := 0; pages < 5; pages++ {
+		response, err := client.ListUsers(context.Background(), &userv1.ListUsersRequest{PageSize: 2, PageToken: pageToken})
+		if err != nil {
+			t.Fatalf("ListUsers() unexpected error: %v", err)
+		}
+		for _, user := range response.GetUsers() {
+			ids = append(ids, user.GetId())
+		}
+		if pageToken = response.GetNextPageToken(); pageToken == "" {
+			break
+		}
+	}
+
+	if fmt.Sprint(ids) != "[1 2 3]" {
+		t.Errorf("ListUsers() ids = %v, want [1 2 3]", ids)
+	}
+}
+
+func TestServer_ListUsers_Status(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         string
+		expectedCode   codes.Code
+		expectedFilter userDomain.Status
+	}{
+		{name: "no status", expectedCode: codes.OK},
+		{name: "status filtered", status: "active", expectedCode: codes.OK, expectedFilter: userDomain.StatusActive},
+		{name: "unknown status", status: "deleted", expectedCode: codes.InvalidArgument},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := newTestClient(t, &mockUserApplicationService{
+				ListFunc: func(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error) {
+					if filter.Status != test.expectedFilter {
+						t.Errorf("List() status = %q, want %q", filter.Status, test.expectedFilter)
+					}
+					return []*userDomain.User{{ID: "1", Status: userDomain.StatusActive}}, nil
+				},
+			})
+
+			response, err := client.ListUsers(context.Background(), &userv1.ListUsersRequest{Status: test.status})
+
+			if code := status.Code(err); code != test.expectedCode {
+				t.Fatalf("ListUsers() code = %v, want %v (%v)", code, test.expectedCode, err)
+			}
+			if test.expectedCode == codes.OK && response.GetUsers()[0].GetStatus() != string(userDomain.StatusActive) {
+				t.Errorf("ListUsers() user status = %q, want %q", response.GetUsers()[0].GetStatus(), userDomain.StatusActive)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+// Package i18n localizes the messages of API errors with message catalogs embedded in the binary.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"golang.org/x/text/language"
+)
+
+// DefaultLanguage is the language used when no accepted language has a message, every catalog must have it
+var DefaultLanguage = language.English
+
+//go:embed messages/*.json
+var embeddedMessages embed.FS
+
+// defaultCatalog is the catalog of the embedded messages, they are checked by the tests so loading them cannot fail
+var defaultCatalog = sync.OnceValue(func() *Catalog {
+	messages, err := fs.Sub(embeddedMessages, "messages")
+	if err != nil {
+		panic(err)
+	}
+	catalog, err := NewCatalog(messages)
+	if err != nil {
+		panic(err)
+	}
+	return catalog
+})
+
+// Catalog holds the messages of each language by key.
+// Validation messages are keyed by their ValidationError code.
+type Catalog struct {
+	messages map[language.Tag]map[string]string
+}
+
+// NewCatalog loads the <language>.json files of fsys, each a JSON object of messages by key.
+// Messages may hold {param} placeholders.
+func NewCatalog(fsys fs.FS) (*Catalog, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("i18n: failed to list message files: %w", err)
+	}
+	catalog := &Catalog{messages: make(map[language.Tag]map[string]string, len(names))}
+	for _, name := range names {
+		tag, err := language.Parse(strings.TrimSuffix(name, path.Ext(name)))
+		if err != nil {
+			return nil, fmt.Errorf("i18n: message file %q is not named after a language: %w", name, err)
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("i18n: failed to read message file %q: %w", name, err)
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("i18n: failed to decode message file %q: %w", name, err)
+		}
+		catalog.messages[tag] = messages
+	}
+	if _, ok := catalog.messages[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("i18n: no messages in the default language %s", DefaultLanguage)
+	}
+	return catalog, nil
+}
+
+// Languages returns the languages of the catalog
+func (c *Catalog) Languages() []language.Tag {
+	tags := make([]language.Tag, 0, len(c.messages))
+	for tag := range c.messages {
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// Localizer returns the localizer of an Accept-Language header value.
+// Its fallback chain is each accepted language by preference followed by its parent languages, de-CH then de,
+// and the DefaultLanguage last. Languages without messages are skipped.
+func (c *Catalog) Localizer(acceptLanguage string) Localizer {
+	// an invalid header is ignored like a missing one
+	accepted, _, _ := language.ParseAcceptLanguage(acceptLanguage)
+	localizer := Localizer{catalog: c}
+	add := func(tag language.Tag) {
+		if _, ok := c.messages[tag]; !ok {
+			return
+		}
+		for _, chained := range localizer.chain {
+			if chained == tag {
+				return
+			}
+		}
+		localizer.chain = append(localizer.chain, tag)
+	}
+	for _, tag := range accepted {
+		for ; !tag.IsRoot(); tag = tag.Parent() {
+			add(tag)
+		}
+	}
+	add(DefaultLanguage)
+	return localizer
+}
+
+// FromRequest returns the localizer of the request's Accept-Language header with the embedded messages
+func FromRequest(r *http.Request) Localizer {
+	return defaultCatalog().Localizer(r.Header.Get("Accept-Language"))
+}
+
+// Localizer looks up messages along a fallback chain of languages
+type Localizer struct {
+	catalog *Catalog
+	chain   []language.Tag
+}
+
+// Language returns the preferred language of the chain
+func (l Localizer) Language() language.Tag {
+	return l.chain[0]
+}
+
+// Message returns the message of the key in the first language of the chain that has it with its placeholders
+// replaced by the params, fallback is returned when no language has it
+func (l Localizer) Message(key string, params map[string]string, fallback string) string {
+	for _, tag := range l.chain {
+		if message, ok := l.catalog.messages[tag][key]; ok {
+			for name, value := range params {
+				message = strings.ReplaceAll(message, "{"+name+"}", value)
+			}
+			return message
+		}
+	}
+	return fallback
+}
+
+// ValidationError returns the message of the validation error in the language of the chain with its params,
+// its own message when no language has one for its code
+func (l Localizer) ValidationError(err shared.ValidationError) string {
+	return l.Message(err.Code, err.Params, err.Message)
+}
+
+// WriteHeaders sets the Content-Language of a localized response and varies it by Accept-Language
+func (l Localizer) WriteHeaders(header http.Header) {
+	header.Set("Content-Language", l.Language().String())
+	header.Add("Vary", "Accept-Language")
+}
@@ -0,0 +1,103 @@
+package i18n
+
+import (
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"golang.org/x/text/language"
+)
+
+func TestDefaultCatalog_Complete(t *testing.T) {
+	catalog := defaultCatalog()
+	defaults := catalog.messages[DefaultLanguage]
+	for _, tag := range catalog.Languages() {
+		for key := range defaults {
+			if _, ok := catalog.messages[tag][key]; !ok {
+				t.Errorf("%s messages lack %s", tag, key)
+			}
+		}
+		for key := range catalog.messages[tag] {
+			if _, ok := defaults[key]; !ok {
+				t.Errorf("%s messages have %s which the default language lacks", tag, key)
+			}
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	catalog, err := NewCatalog(fstest.MapFS{
+		"en.json":    {Data: []byte(`{"AGE_MINIMUM": "must be at least {min}", "GREETING": "hello"}`)},
+		"de.json":    {Data: []byte(`{"AGE_MINIMUM": "muss mindestens {min} sein"}`)},
+		"pt-BR.json": {Data: []byte(`{"AGE_MINIMUM": "deve ter pelo menos {min}"}`)},
+	})
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+
+	tests := []struct {
+		name             string
+		acceptLanguage   string
+		key              string
+		expected         string
+		expectedLanguage language.Tag
+	}{
+		{name: "no header", acceptLanguage: "", key: "AGE_MINIMUM", expected: "must be at least 18", expectedLanguage: language.English},
+		{name: "exact language", acceptLanguage: "de", key: "AGE_MINIMUM", expected: "muss mindestens 18 sein", expectedLanguage: language.German},
+		{name: "parent language", acceptLanguage: "de-CH", key: "AGE_MINIMUM", expected: "muss mindestens 18 sein", expectedLanguage: language.German},
+		{name: "regional language", acceptLanguage: "pt-BR", key: "AGE_MINIMUM", expected: "deve ter pelo menos 18", expectedLanguage: language.BrazilianPortuguese},
+		{name: "by quality", acceptLanguage: "ja, fr;q=0.9, de;q=0.5", key: "AGE_MINIMUM", expected: "muss mindestens 18 sein", expectedLanguage: language.German},
+		{name: "missing translation falls back along the chain", acceptLanguage: "de", key: "GREETING", expected: "hello", expectedLanguage: language.German},
+		{name: "unknown key", acceptLanguage: "de", key: "UNKNOWN", expected: "fallback", expectedLanguage: language.German},
+		{name: "invalid header", acceptLanguage: ";;q=x", key: "AGE_MINIMUM", expected: "must be at least 18", expectedLanguage: language.English},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			localizer := catalog.Localizer(test.acceptLanguage)
+
+			if got := localizer.Message(test.key, map[string]string{"min": "18"}, "fallback"); got != test.expected {
+				t.Errorf("Message() = %q, want %q", got, test.expected)
+			}
+			if localizer.Language() != test.expectedLanguage {
+				t.Errorf("Language() = %s, want %s", localizer.Language(), test.expectedLanguage)
+			}
+		})
+	}
+}
+
+func TestNewCatalog_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{name: "no default language", fsys: fstest.MapFS{"de.json": {Data: []byte(`{}`)}}},
+		{name: "not a language", fsys: fstest.MapFS{"en.json": {Data: []byte(`{}`)}, "messages.json": {Data: []byte(`{}`)}}},
+		{name: "not an object of strings", fsys: fstest.MapFS{"en.json": {Data: []byte(`{"AGE_MINIMUM": 18}`)}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := NewCatalog(test.fsys); err == nil {
+				t.Errorf("NewCatalog() expected an error")
+			}
+		})
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Language", "fr-CA")
+	localizer := FromRequest(r)
+
+	got := localizer.ValidationError(shared.ValidationError{Code: "EMAIL_REQUIRED", Message: "User email is required"})
+	if got != "L'e-mail de l'utilisateur est obligatoire" {
+		t.Errorf("ValidationError() = %q", got)
+	}
+	header := http.Header{}
+	localizer.WriteHeaders(header)
+	if header.Get("Content-Language") != "fr" || header.Get("Vary") != "Accept-Language" {
+		t.Errorf("WriteHeaders() = %v", header)
+	}
+}
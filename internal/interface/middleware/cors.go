@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	headerOrigin                        = "Origin"
+	headerVary                          = "Vary"
+	headerAccessControlRequestMethod    = "Access-Control-Request-Method"
+	headerAccessControlRequestHeaders   = "Access-Control-Request-Headers"
+	headerAccessControlAllowOrigin      = "Access-Control-Allow-Origin"
+	headerAccessControlAllowMethods     = "Access-Control-Allow-Methods"
+	headerAccessControlAllowHeaders     = "Access-Control-Allow-Headers"
+	headerAccessControlAllowCredentials = "Access-Control-Allow-Credentials"
+	headerAccessControlExposeHeaders    = "Access-Control-Expose-Headers"
+	headerAccessControlMaxAge           = "Access-Control-Max-Age"
+)
+
+// CORSConfig configures which cross-origin requests are allowed.
+//
+// AllowedOrigins accepts exact origins ("https://app.example.com"), wildcard
+// subdomains ("https://*.example.com") or "*" to allow any origin.
+// AllowedHeaders accepts "*" to allow any request header.
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// CORS is a middleware that handles cross-origin resource sharing.
+type CORS struct {
+	config       CORSConfig
+	anyOrigin    bool
+	origins      map[string]bool
+	wildcards    []originPattern
+	methods      map[string]bool
+	anyHeader    bool
+	headers      map[string]bool
+	router       *mux.Router
+	maxAge       string
+	allowMethods string
+}
+
+// originPattern is a wildcard subdomain origin such as https://*.example.com
+type originPattern struct {
+	scheme string
+	suffix string
+}
+
+// NewCORS creates a new CORS middleware
+func NewCORS(config CORSConfig) *CORS {
+	c := &CORS{
+		config:       config,
+		origins:      make(map[string]bool),
+		methods:      make(map[string]bool),
+		headers:      make(map[string]bool),
+		allowMethods: strings.Join(config.AllowedMethods, ", "),
+	}
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			c.anyOrigin = true
+		case strings.Contains(origin, "://*."):
+			scheme, host, _ := strings.Cut(origin, "://")
+			c.wildcards = append(c.wildcards, originPattern{scheme: scheme, suffix: strings.TrimPrefix(host, "*")})
+		default:
+			c.origins[origin] = true
+		}
+	}
+	for _, method := range config.AllowedMethods {
+		c.methods[strings.ToUpper(method)] = true
+	}
+	for _, header := range config.AllowedHeaders {
+		if header == "*" {
+			c.anyHeader = true
+			continue
+		}
+		c.headers[http.CanonicalHeaderKey(header)] = true
+	}
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(config.MaxAge.Seconds()))
+	}
+	return c
+}
+
+// Register installs the middleware on the router and answers preflight requests
+// for every route on it, including routes added later through shared.Handler.AddRoute.
+func (c *CORS) Register(r *mux.Router) {
+	c.router = r
+	r.MatcherFunc(isPreflight).HandlerFunc(c.preflight)
+	r.Use(c.Middleware)
+}
+
+// Middleware adds the CORS response headers to actual (non preflight) cross-origin requests.
+// Every response varies by Origin, so caches do not serve a response without the CORS headers to another origin.
+func (c *CORS) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isPreflight(r, nil) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add(headerVary, headerOrigin)
+		if origin := r.Header.Get(headerOrigin); c.isOriginAllowed(origin) {
+			c.setAllowOrigin(w, origin)
+			if len(c.config.ExposedHeaders) > 0 {
+				w.Header().Set(headerAccessControlExposeHeaders, strings.Join(c.config.ExposedHeaders, ", "))
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *CORS) preflight(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add(headerVary, headerOrigin)
+	w.Header().Add(headerVary, headerAccessControlRequestMethod)
+	w.Header().Add(headerVary, headerAccessControlRequestHeaders)
+
+	method := strings.ToUpper(r.Header.Get(headerAccessControlRequestMethod))
+	if status := c.routeStatus(r, method); status != http.StatusOK {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	origin := r.Header.Get(headerOrigin)
+	if !c.isOriginAllowed(origin) || !c.methods[method] || !c.areHeadersAllowed(r.Header.Get(headerAccessControlRequestHeaders)) {
+		// without the allow headers the browser rejects the actual request
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	c.setAllowOrigin(w, origin)
+	w.Header().Set(headerAccessControlAllowMethods, c.allowMethods)
+	if requested := r.Header.Get(headerAccessControlRequestHeaders); requested != "" {
+		w.Header().Set(headerAccessControlAllowHeaders, requested)
+	}
+	if c.maxAge != "" {
+		w.Header().Set(headerAccessControlMaxAge, c.maxAge)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// routeStatus checks that the router has a route for the path and requested method
+func (c *CORS) routeStatus(r *http.Request, method string) int {
+	if c.router == nil {
+		return http.StatusOK
+	}
+	probe := r.Clone(r.Context())
+	probe.Method = method
+	var match mux.RouteMatch
+	if c.router.Match(probe, &match) && match.MatchErr == nil {
+		return http.StatusOK
+	}
+	if match.MatchErr == mux.ErrMethodMismatch {
+		return http.StatusMethodNotAllowed
+	}
+	return http.StatusNotFound
+}
+
+func (c *CORS) setAllowOrigin(w http.ResponseWriter, origin string) {
+	if c.anyOrigin && !c.config.AllowCredentials {
+		w.Header().Set(headerAccessControlAllowOrigin, "*")
+	} else {
+		// credentialed requests must echo the exact origin
+		w.Header().Set(headerAccessControlAllowOrigin, origin)
+	}
+	if c.config.AllowCredentials {
+		w.Header().Set(headerAccessControlAllowCredentials, "true")
+	}
+}
+
+func (c *CORS) isOriginAllowed(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	if c.anyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if c.origins[origin] {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	for _, pattern := range c.wildcards {
+		if u.Scheme == pattern.scheme && strings.HasSuffix(u.Host, pattern.suffix) && len(u.Host) > len(pattern.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) areHeadersAllowed(requested string) bool {
+	if c.anyHeader || requested == "" {
+		return true
+	}
+	for _, header := range strings.Split(requested, ",") {
+		header = strings.TrimSpace(header)
+		if header != "" && !c.headers[http.CanonicalHeaderKey(header)] {
+			return false
+		}
+	}
+	return true
+}
+
+// isPreflight reports whether the request is a CORS preflight request
+func isPreflight(r *http.Request, _ *mux.RouteMatch) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get(headerOrigin) != "" &&
+		r.Header.Get(headerAccessControlRequestMethod) != ""
+}
@@ -0,0 +1,200 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func newCORSRouter(config CORSConfig) *mux.Router {
+	r := mux.NewRouter()
+	NewCORS(config).Register(r)
+	r.Path("/find/{id}").Methods("GET").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return r
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	config := CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type"},
+		AllowCredentials: true,
+		MaxAge:           5 * time.Minute,
+	}
+	tests := []struct {
+		name            string
+		path            string
+		origin          string
+		method          string
+		headers         string
+		expectedStatus  int
+		expectedOrigin  string
+		expectedMaxAge  string
+		expectedMethods string
+	}{
+		{
+			name:            "exact origin allowed",
+			path:            "/find/1",
+			origin:          "https://app.example.com",
+			method:          "GET",
+			headers:         "content-type",
+			expectedStatus:  http.StatusNoContent,
+			expectedOrigin:  "https://app.example.com",
+			expectedMaxAge:  "300",
+			expectedMethods: "GET, POST",
+		},
+		{
+			name:            "wildcard subdomain allowed",
+			path:            "/find/1",
+			origin:          "https://onboarding.example.org",
+			method:          "GET",
+			expectedStatus:  http.StatusNoContent,
+			expectedOrigin:  "https://onboarding.example.org",
+			expectedMaxAge:  "300",
+			expectedMethods: "GET, POST",
+		},
+		{
+			name:           "wildcard does not match bare domain",
+			path:           "/find/1",
+			origin:         "https://example.org",
+			method:         "GET",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "origin not allowed",
+			path:           "/find/1",
+			origin:         "https://evil.com",
+			method:         "GET",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "header not allowed",
+			path:           "/find/1",
+			origin:         "https://app.example.com",
+			method:         "GET",
+			headers:        "X-Custom",
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "method not registered for route",
+			path:           "/find/1",
+			origin:         "https://app.example.com",
+			method:         "DELETE",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		{
+			name:           "unknown route",
+			path:           "/unknown",
+			origin:         "https://app.example.com",
+			method:         "GET",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodOptions, test.path, nil)
+			req.Header.Set("Origin", test.origin)
+			req.Header.Set("Access-Control-Request-Method", test.method)
+			if test.headers != "" {
+				req.Header.Set("Access-Control-Request-Headers", test.headers)
+			}
+			newCORSRouter(config).ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != test.expectedOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", test.expectedOrigin, got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != test.expectedMaxAge {
+				t.Errorf("expected Access-Control-Max-Age %q, got %q", test.expectedMaxAge, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != test.expectedMethods {
+				t.Errorf("expected Access-Control-Allow-Methods %q, got %q", test.expectedMethods, got)
+			}
+		})
+	}
+}
+
+func TestCORS_ActualRequest(t *testing.T) {
+	tests := []struct {
+		name                string
+		config              CORSConfig
+		origin              string
+		expectedOrigin      string
+		expectedCredentials string
+	}{
+		{
+			name:           "any origin without credentials",
+			config:         CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}},
+			origin:         "https://app.example.com",
+			expectedOrigin: "*",
+		},
+		{
+			name:                "any origin with credentials echoes origin",
+			config:              CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowCredentials: true},
+			origin:              "https://app.example.com",
+			expectedOrigin:      "https://app.example.com",
+			expectedCredentials: "true",
+		},
+		{
+			name:           "origin not allowed",
+			config:         CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"GET"}},
+			origin:         "https://evil.com",
+			expectedOrigin: "",
+		},
+		{
+			name:           "same origin request",
+			config:         CORSConfig{AllowedOrigins: []string{"https://app.example.com"}, AllowedMethods: []string{"GET"}},
+			expectedOrigin: "",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/find/1", nil)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			newCORSRouter(test.config).ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("expected status code %d, got %d", http.StatusOK, w.Code)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != test.expectedOrigin {
+				t.Errorf("expected Access-Control-Allow-Origin %q, got %q", test.expectedOrigin, got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != test.expectedCredentials {
+				t.Errorf("expected Access-Control-Allow-Credentials %q, got %q", test.expectedCredentials, got)
+			}
+			// responses to requests without an Origin must not be served by caches to cross-origin requests
+			if got := w.Header().Values("Vary"); len(got) != 1 || got[0] != "Origin" {
+				t.Errorf("expected Vary Origin, got %q", got)
+			}
+		})
+	}
+}
+
+func TestCORS_NonPreflightOptionsAndUnknownRoutes(t *testing.T) {
+	r := newCORSRouter(CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status code %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodOptions, "/find/1", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status code %d, got %d", http.StatusMethodNotAllowed, w.Code)
+	}
+}
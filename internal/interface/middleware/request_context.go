@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	interfaceShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+const (
+	// RequestIDHeader carries the request ID, it is generated when the client does not send one
+	RequestIDHeader = "X-Request-ID"
+	// ActorHeader identifies the caller making the request, it is expected to be set by the authenticating proxy
+	ActorHeader = "X-Actor"
+)
+
+// RequestID is a middleware that adds the request ID to the request context and echoes it in the response.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if !interfaceShared.ValidRequestID(requestID) {
+			requestID = interfaceShared.NewRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+		next.ServeHTTP(w, r.WithContext(shared.WithRequestID(r.Context(), requestID)))
+	})
+}
+
+// Actor is a middleware that adds the caller from the X-Actor header to the request context.
+func Actor(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if actor := r.Header.Get(ActorHeader); actor != "" {
+			r = r.WithContext(shared.WithActor(r.Context(), actor))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+func TestRequestContext(t *testing.T) {
+	tests := []struct {
+		name              string
+		requestID         string
+		actor             string
+		expectedRequestID string
+		expectedActor     string
+	}{
+		{name: "headers propagated", requestID: "request-1", actor: "alice", expectedRequestID: "request-1", expectedActor: "alice"},
+		{name: "request id generated", expectedActor: shared.SystemActor},
+		{name: "invalid request id replaced", requestID: "bad id\n", expectedActor: shared.SystemActor},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestID, actor string
+			handler := RequestID(Actor(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestID = shared.RequestIDFromContext(r.Context())
+				actor = shared.ActorFromContext(r.Context())
+			})))
+			r := httptest.NewRequest("GET", "/", nil)
+			if test.requestID != "" {
+				r.Header.Set(RequestIDHeader, test.requestID)
+			}
+			if test.actor != "" {
+				r.Header.Set(ActorHeader, test.actor)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if test.expectedRequestID != "" && requestID != test.expectedRequestID {
+				t.Errorf("request ID = %q, want %q", requestID, test.expectedRequestID)
+			}
+			if requestID == "" || requestID == test.requestID && test.expectedRequestID == "" {
+				t.Errorf("request ID = %q, want a generated id", requestID)
+			}
+			if got := w.Header().Get(RequestIDHeader); got != requestID {
+				t.Errorf("response %s = %q, want %q", RequestIDHeader, got, requestID)
+			}
+			if actor != test.expectedActor {
+				t.Errorf("actor = %q, want %q", actor, test.expectedActor)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"log"
 	"net/http"
 	"time"
+
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
 )
 
 // RequestLogger is a middleware that logs the HTTP request path and basic metadata.
@@ -17,8 +19,8 @@ func RequestLogger(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 		log.Printf(
-			`{"level":"info","msg":"request completed","method":"%s","path":"%s","duration_ms":%d}`,
-			r.Method, r.URL.Path, duration.Milliseconds(),
+			`{"level":"info","msg":"request completed","method":"%s","path":"%s","request_id":"%s","duration_ms":%d}`,
+			r.Method, r.URL.Path, shared.RequestIDFromContext(r.Context()), duration.Milliseconds(),
 		)
 	})
 }
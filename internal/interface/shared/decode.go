@@ -0,0 +1,82 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultMaxBodyBytes is the default request body limit
+const DefaultMaxBodyBytes = 1 << 20
+
+var (
+	// ErrEmptyBody is returned by a decode function when the request body is empty
+	ErrEmptyBody = errors.New("shared: request body must not be empty")
+	// ErrTrailingData is returned by a decode function when the request body contains more than one value
+	ErrTrailingData = errors.New("shared: request body must contain a single value")
+)
+
+// FieldError is returned by a decode function when a value could not be decoded into a field
+type FieldError struct {
+	Field  string
+	Reason string
+	Err    error
+}
+
+// Error returns the error message
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("shared: field %q %s", e.Field, e.Reason)
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// RequestDecoder decodes request bodies strictly.
+// It caps the body size and reports the failures of the decode function as problem errors.
+type RequestDecoder struct {
+	maxBodyBytes int64
+}
+
+// NewRequestDecoder creates a new request decoder limiting bodies to maxBodyBytes
+func NewRequestDecoder(maxBodyBytes int64) *RequestDecoder {
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultMaxBodyBytes
+	}
+	return &RequestDecoder{maxBodyBytes: maxBodyBytes}
+}
+
+// Decode decodes the request body of the media type with decode, returning a *ProblemError when the request is rejected.
+// decode reads a single value from the capped body and reports failures with ErrEmptyBody, ErrTrailingData and *FieldError.
+func (d *RequestDecoder) Decode(w http.ResponseWriter, r *http.Request, mediaType string, decode func(body io.Reader) error) error {
+	err := decode(http.MaxBytesReader(w, r.Body, d.maxBodyBytes))
+	if err == nil {
+		return nil
+	}
+
+	var fieldErr *FieldError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &ProblemError{
+			Problem: NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body must not be larger than %d bytes", d.maxBodyBytes)),
+			Err:     err,
+		}
+	case errors.As(err, &fieldErr):
+		problem := NewProblem(http.StatusBadRequest, "request body contains an invalid value")
+		problem.InvalidParams = []InvalidParam{{Name: fieldErr.Field, Reason: fieldErr.Reason}}
+		return &ProblemError{Problem: problem, Err: err}
+	case errors.Is(err, ErrEmptyBody):
+		return badRequest(err, "request body must not be empty")
+	case errors.Is(err, ErrTrailingData):
+		return badRequest(err, "request body must contain a single value")
+	default:
+		return badRequest(err, fmt.Sprintf("request body is not valid %s: %v", mediaType, err))
+	}
+}
+
+func badRequest(err error, detail string) error {
+	return &ProblemError{Problem: NewProblem(http.StatusBadRequest, detail), Err: err}
+}
@@ -0,0 +1,54 @@
+package shared
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestDecoder_Decode(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		decodeErr      error
+		expectedStatus int
+		expectedParam  string
+	}{
+		{name: "decoded", body: "{}"},
+		{name: "body too large", body: strings.Repeat("a", 32), expectedStatus: http.StatusRequestEntityTooLarge},
+		{name: "field error", body: "{}", decodeErr: &FieldError{Field: "age", Reason: "must be a number"}, expectedStatus: http.StatusBadRequest, expectedParam: "age"},
+		{name: "empty body", decodeErr: ErrEmptyBody, expectedStatus: http.StatusBadRequest},
+		{name: "trailing data", body: "{}{}", decodeErr: errors.Join(ErrTrailingData, errors.New("extra")), expectedStatus: http.StatusBadRequest},
+		{name: "malformed body", body: "{", decodeErr: io.ErrUnexpectedEOF, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/save", strings.NewReader(test.body))
+
+			err := NewRequestDecoder(16).Decode(httptest.NewRecorder(), req, "application/json", func(body io.Reader) error {
+				if _, err := io.ReadAll(body); err != nil {
+					return err
+				}
+				return test.decodeErr
+			})
+
+			if test.expectedStatus == 0 {
+				if err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				return
+			}
+			var problemErr *ProblemError
+			if !errors.As(err, &problemErr) || problemErr.Problem.Status != test.expectedStatus {
+				t.Fatalf("Decode() error = %v, want a %d problem", err, test.expectedStatus)
+			}
+			if test.expectedParam != "" && (len(problemErr.Problem.InvalidParams) != 1 || problemErr.Problem.InvalidParams[0].Name != test.expectedParam) {
+				t.Errorf("Decode() invalid params = %+v, want %s", problemErr.Problem.InvalidParams, test.expectedParam)
+			}
+		})
+	}
+}
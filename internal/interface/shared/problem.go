@@ -0,0 +1,98 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+)
+
+const problemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details response
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// InvalidParam describes a single request parameter that failed to decode or validate.
+// Params that failed a validation rule also carry its code, parameters and severity.
+type InvalidParam struct {
+	Name     string            `json:"name"`
+	Reason   string            `json:"reason"`
+	Code     string            `json:"code,omitempty"`
+	Value    string            `json:"value,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Severity string            `json:"severity,omitempty"`
+}
+
+// NewInvalidParam describes a validation error with the reason given, redact the error to leave its value out
+func NewInvalidParam(err domainShared.ValidationError, reason string) InvalidParam {
+	return InvalidParam{
+		Name:     err.Field,
+		Reason:   reason,
+		Code:     err.Code,
+		Value:    err.Value,
+		Params:   err.Params,
+		Severity: string(err.Severity),
+	}
+}
+
+// NewProblem creates a problem for the status code with the standard status text as the title
+func NewProblem(status int, detail string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// ProblemError is an error that carries the problem to report to the client
+type ProblemError struct {
+	Problem Problem
+	Err     error
+}
+
+// Error returns the error message
+func (e *ProblemError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Problem.Detail
+}
+
+// Unwrap returns the underlying error
+func (e *ProblemError) Unwrap() error {
+	return e.Err
+}
+
+// WriteProblem writes the problem as an application/problem+json response
+func WriteProblem(w http.ResponseWriter, r *http.Request, problem Problem) {
+	if problem.Instance == "" {
+		problem.Instance = r.URL.Path
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// WriteError writes the error as a problem response.
+// Errors that do not carry a problem are logged with the request ID and reported as internal server errors
+// with a generic detail, their message may describe the storage.
+func WriteError(w http.ResponseWriter, r *http.Request, err error) {
+	var problemErr *ProblemError
+	if errors.As(err, &problemErr) {
+		WriteProblem(w, r, problemErr.Problem)
+		return
+	}
+	log.Printf(`{"level":"error","msg":"request failed","method":"%s","path":"%s","request_id":"%s","error":%q}`,
+		r.Method, r.URL.Path, domainShared.RequestIDFromContext(r.Context()), err.Error())
+	WriteProblem(w, r, NewProblem(http.StatusInternalServerError, "the request could not be processed"))
+}
@@ -0,0 +1,50 @@
+package shared
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedDetail string
+	}{
+		{
+			name:           "problem error",
+			err:            &ProblemError{Problem: NewProblem(http.StatusNotFound, "user not found"), Err: errors.New("not found")},
+			expectedStatus: http.StatusNotFound,
+			expectedDetail: "user not found",
+		},
+		{
+			name:           "internal error",
+			err:            errors.New("mongodb: failed to find user: connection refused"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedDetail: "the request could not be processed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			WriteError(w, httptest.NewRequest("GET", "/v1/users/1", nil), test.err)
+
+			var problem Problem
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if w.Code != test.expectedStatus || problem.Detail != test.expectedDetail {
+				t.Errorf("WriteError() = %d %q, want %d %q", w.Code, problem.Detail, test.expectedStatus, test.expectedDetail)
+			}
+			if w.Code == http.StatusInternalServerError && strings.Contains(problem.Detail, "mongodb") {
+				t.Errorf("WriteError() detail %q leaks the error", problem.Detail)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package shared
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// maxRequestIDLength is the longest client supplied request ID accepted
+const maxRequestIDLength = 128
+
+// ValidRequestID accepts short printable ASCII ids so client supplied ids are safe to log and store
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < '!' || requestID[i] > '~' || requestID[i] == '"' || requestID[i] == '\\' {
+			return false
+		}
+	}
+	return true
+}
+
+// NewRequestID generates a random request ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// AuditTrail is the api handler for the /v1/users/{id}/audit route.
+// The trail of deleted users is still returned, users without any recorded change are not found.
+func (h Handler) AuditTrail() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(auditRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			id := mux.Vars(r)["id"]
+			records, err := h.userService.AuditTrail(r.Context(), id)
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+
+			var auditTrailDTO AuditTrailDTO
+			auditTrailDTO.FromRecords(id, records)
+			if err := codec.Write(w, responseCodec, http.StatusOK, auditTrailDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
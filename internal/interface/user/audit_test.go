@@ -0,0 +1,97 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestAuditTrail(t *testing.T) {
+	timestamp := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		auditFunc      func(ctx context.Context, id string) ([]*userDomain.AuditRecord, error)
+		expectedStatus int
+	}{
+		{
+			name: "records found",
+			auditFunc: func(ctx context.Context, id string) ([]*userDomain.AuditRecord, error) {
+				return []*userDomain.AuditRecord{{
+					ID: "a1", UserID: id, Action: userDomain.AuditActionUpdate, Actor: "alice", RequestID: "request-1", Timestamp: timestamp,
+					Changes: []userDomain.FieldChange{{Field: userDomain.FieldEmail, Before: "john@example.com", After: "johnny@example.com"}},
+				}}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "no records",
+			auditFunc: func(ctx context.Context, id string) ([]*userDomain.AuditRecord, error) {
+				return nil, fmt.Errorf("service: %w", userDomain.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{AuditFunc: test.auditFunc}).AuditTrail().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/1/audit", nil))
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+			var trail AuditTrailDTO
+			if err := json.NewDecoder(w.Body).Decode(&trail); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if trail.UserID != "1" || len(trail.Records) != 1 {
+				t.Fatalf("expected one record for user 1, got %+v", trail)
+			}
+			record := trail.Records[0]
+			if record.Actor != "alice" || record.RequestID != "request-1" || !record.Timestamp.Equal(timestamp) {
+				t.Errorf("unexpected record %+v", record)
+			}
+			if len(record.Changes) != 1 || record.Changes[0].After != "johnny@example.com" {
+				t.Errorf("unexpected changes %+v", record.Changes)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	tests := []struct {
+		name           string
+		deleteErr      error
+		expectedStatus int
+	}{
+		{name: "user deleted", expectedStatus: http.StatusNoContent},
+		{name: "user not found", deleteErr: userDomain.ErrNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{
+				DeleteFunc: func(ctx context.Context, id string) error { return test.deleteErr },
+			}).Delete().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("DELETE", "/v1/users/1", nil))
+
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+		})
+	}
+}
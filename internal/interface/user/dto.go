@@ -1,32 +1,447 @@
 package user
 
-import userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+import (
+	"encoding/xml"
+	"math"
+	"net/http"
+	"time"
+
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/i18n"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
 
 // UserDTO is a user data transfer object
 type UserDTO struct {
-	ID        string `json:"id"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
-	Email     string `json:"email"`
-	Age       int    `json:"age"`
+	XMLName   xml.Name `json:"-" msgpack:"-" cbor:"-" xml:"user"`
+	ID        string   `json:"id" msgpack:"id" cbor:"id" xml:"id"`
+	FirstName string   `json:"first_name" msgpack:"first_name" cbor:"first_name" xml:"first_name"`
+	LastName  string   `json:"last_name" msgpack:"last_name" cbor:"last_name" xml:"last_name"`
+	Email     string   `json:"email" msgpack:"email" cbor:"email" xml:"email"`
+	// DateOfBirth is in userDomain.DateLayout, it is empty for legacy users saved with an age only
+	DateOfBirth string `json:"date_of_birth,omitempty" msgpack:"date_of_birth,omitempty" cbor:"date_of_birth,omitempty" xml:"date_of_birth,omitempty"`
+	// Age is computed from the date of birth in responses, it is ignored in requests with a date of birth
+	Age      int         `json:"age" msgpack:"age" cbor:"age" xml:"age"`
+	Phone    string      `json:"phone,omitempty" msgpack:"phone,omitempty" cbor:"phone,omitempty" xml:"phone,omitempty"`
+	Address  *AddressDTO `json:"address,omitempty" msgpack:"address,omitempty" cbor:"address,omitempty" xml:"address,omitempty"`
+	Locale   string      `json:"locale,omitempty" msgpack:"locale,omitempty" cbor:"locale,omitempty" xml:"locale,omitempty"`
+	TimeZone string      `json:"time_zone,omitempty" msgpack:"time_zone,omitempty" cbor:"time_zone,omitempty" xml:"time_zone,omitempty"`
+	// Status is changed by the lifecycle commands, it is ignored in requests
+	Status string `json:"status,omitempty" msgpack:"status,omitempty" cbor:"status,omitempty" xml:"status,omitempty"`
+	// EmailVerifiedAt is set when the user follows the verification link of its email, it is ignored in requests
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" msgpack:"email_verified_at,omitempty" cbor:"email_verified_at,omitempty" xml:"email_verified_at,omitempty"`
+	// CreatedAt and UpdatedAt are managed by the server, they are ignored in requests
+	CreatedAt *time.Time `json:"created_at,omitempty" msgpack:"created_at,omitempty" cbor:"created_at,omitempty" xml:"created_at,omitempty"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty" msgpack:"updated_at,omitempty" cbor:"updated_at,omitempty" xml:"updated_at,omitempty"`
+	// Warnings are the concerns about a saved user, such as its possible duplicates, they are ignored in requests
+	Warnings []ValidationErrorDTO `json:"warnings,omitempty" msgpack:"warnings,omitempty" cbor:"warnings,omitempty" xml:"warnings>warning,omitempty"`
+}
+
+// AddressDTO is a postal address data transfer object
+type AddressDTO struct {
+	Line1      string `json:"line1,omitempty" msgpack:"line1,omitempty" cbor:"line1,omitempty" xml:"line1,omitempty"`
+	Line2      string `json:"line2,omitempty" msgpack:"line2,omitempty" cbor:"line2,omitempty" xml:"line2,omitempty"`
+	City       string `json:"city,omitempty" msgpack:"city,omitempty" cbor:"city,omitempty" xml:"city,omitempty"`
+	Region     string `json:"region,omitempty" msgpack:"region,omitempty" cbor:"region,omitempty" xml:"region,omitempty"`
+	PostalCode string `json:"postal_code,omitempty" msgpack:"postal_code,omitempty" cbor:"postal_code,omitempty" xml:"postal_code,omitempty"`
+	Country    string `json:"country,omitempty" msgpack:"country,omitempty" cbor:"country,omitempty" xml:"country,omitempty"`
+}
+
+// ToEntity converts an AddressDTO to a userDomain.Address, a nil address is empty
+func (a *AddressDTO) ToEntity() userDomain.Address {
+	if a == nil {
+		return userDomain.Address{}
+	}
+	return userDomain.Address{
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+	}
+}
+
+// newAddressDTO converts a userDomain.Address to an AddressDTO, an empty address is nil
+func newAddressDTO(address userDomain.Address) *AddressDTO {
+	if address.IsZero() {
+		return nil
+	}
+	return &AddressDTO{
+		Line1:      address.Line1,
+		Line2:      address.Line2,
+		City:       address.City,
+		Region:     address.Region,
+		PostalCode: address.PostalCode,
+		Country:    address.Country,
+	}
 }
 
-// ToEntity converts a UserDTO to a userDomain.User
-func (u *UserDTO) ToEntity() *userDomain.User {
+// timestamp returns a pointer to a time, nil for the zero time
+func timestamp(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+// ToEntity converts a UserDTO to a userDomain.User, it fails with a bad request when the date of birth is not a date
+func (u *UserDTO) ToEntity() (*userDomain.User, error) {
+	dateOfBirth, err := parseDateOfBirth(u.DateOfBirth)
+	if err != nil {
+		return nil, err
+	}
 	return &userDomain.User{
-		ID:        u.ID,
-		FirstName: u.FirstName,
-		LastName:  u.LastName,
-		Email:     u.Email,
-		Age:       u.Age,
+		ID:          u.ID,
+		FirstName:   u.FirstName,
+		LastName:    u.LastName,
+		Email:       u.Email,
+		DateOfBirth: dateOfBirth,
+		Age:         u.Age,
+		Phone:       u.Phone,
+		Address:     u.Address.ToEntity(),
+		Locale:      u.Locale,
+		TimeZone:    u.TimeZone,
+	}, nil
+}
+
+// parseDateOfBirth parses the date of birth of a request
+func parseDateOfBirth(value string) (time.Time, error) {
+	dateOfBirth, err := userDomain.ParseDateOfBirth(value)
+	if err != nil {
+		problem := shared.NewProblem(http.StatusBadRequest, "request contains an invalid date of birth")
+		problem.InvalidParams = []shared.InvalidParam{{Name: userDomain.FieldDateOfBirth, Reason: "must be a date formatted as " + userDomain.DateLayout}}
+		return time.Time{}, &shared.ProblemError{Problem: problem, Err: err}
+	}
+	return dateOfBirth, nil
+}
+
+// UserPatchDTO is a partial update of a user, absent fields are left unchanged
+type UserPatchDTO struct {
+	XMLName   xml.Name `json:"-" msgpack:"-" cbor:"-" xml:"user"`
+	FirstName *string  `json:"first_name" msgpack:"first_name" cbor:"first_name" xml:"first_name"`
+	LastName  *string  `json:"last_name" msgpack:"last_name" cbor:"last_name" xml:"last_name"`
+	Email     *string  `json:"email" msgpack:"email" cbor:"email" xml:"email"`
+	// DateOfBirth migrates legacy users to a date of birth, an empty date of birth removes it
+	DateOfBirth *string `json:"date_of_birth" msgpack:"date_of_birth" cbor:"date_of_birth" xml:"date_of_birth"`
+	Age         *int    `json:"age" msgpack:"age" cbor:"age" xml:"age"`
+	Phone       *string `json:"phone" msgpack:"phone" cbor:"phone" xml:"phone"`
+	// Address replaces the whole address, an empty address removes it
+	Address  *AddressDTO `json:"address" msgpack:"address" cbor:"address" xml:"address"`
+	Locale   *string     `json:"locale" msgpack:"locale" cbor:"locale" xml:"locale"`
+	TimeZone *string     `json:"time_zone" msgpack:"time_zone" cbor:"time_zone" xml:"time_zone"`
+}
+
+// Apply sets the fields present in the patch on the user, it fails with a bad request when the date of birth is not a date
+func (p *UserPatchDTO) Apply(user *userDomain.User) error {
+	if p.FirstName != nil {
+		user.FirstName = *p.FirstName
+	}
+	if p.LastName != nil {
+		user.LastName = *p.LastName
+	}
+	if p.Email != nil {
+		user.Email = *p.Email
+	}
+	if p.Age != nil {
+		user.Age = *p.Age
+	}
+	if p.Phone != nil {
+		user.Phone = *p.Phone
+	}
+	if p.Address != nil {
+		user.Address = p.Address.ToEntity()
 	}
+	if p.Locale != nil {
+		user.Locale = *p.Locale
+	}
+	if p.TimeZone != nil {
+		user.TimeZone = *p.TimeZone
+	}
+	if p.DateOfBirth != nil {
+		dateOfBirth, err := parseDateOfBirth(*p.DateOfBirth)
+		if err != nil {
+			return err
+		}
+		user.DateOfBirth = dateOfBirth
+	}
+	return nil
 }
 
-// FromEntity converts a userDomain.User to a UserDTO
-func (u *UserDTO) FromEntity(user *userDomain.User) {
+// FromEntity converts a userDomain.User to a UserDTO with the user's age at now
+func (u *UserDTO) FromEntity(user *userDomain.User, now time.Time) {
 	u.ID = user.ID
 	u.FirstName = user.FirstName
 	u.LastName = user.LastName
 	u.Email = user.Email
-	u.Age = user.Age
+	u.DateOfBirth = userDomain.FormatDateOfBirth(user.DateOfBirth)
+	u.Age = user.AgeAt(now)
+	u.Phone = user.Phone
+	u.Address = newAddressDTO(user.Address)
+	u.Locale = user.Locale
+	u.TimeZone = user.TimeZone
+	u.Status = string(user.Status)
+	u.EmailVerifiedAt = timestamp(user.EmailVerifiedAt)
+	u.CreatedAt = timestamp(user.CreatedAt)
+	u.UpdatedAt = timestamp(user.UpdatedAt)
+}
+
+// UserListDTO is a page of users
+type UserListDTO struct {
+	XMLName       xml.Name  `json:"-" msgpack:"-" cbor:"-" xml:"users"`
+	Users         []UserDTO `json:"users" msgpack:"users" cbor:"users" xml:"user"`
+	NextPageToken string    `json:"next_page_token,omitempty" msgpack:"next_page_token,omitempty" cbor:"next_page_token,omitempty" xml:"next_page_token,omitempty"`
+}
+
+// TransitionRequestDTO is the optional body of a lifecycle command
+type TransitionRequestDTO struct {
+	XMLName xml.Name `json:"-" msgpack:"-" cbor:"-" xml:"transition"`
+	Reason  string   `json:"reason" msgpack:"reason" cbor:"reason" xml:"reason"`
+}
+
+// StatusHistoryDTO is the response of the status history of a user
+type StatusHistoryDTO struct {
+	XMLName     xml.Name              `json:"-" msgpack:"-" cbor:"-" xml:"status_history"`
+	UserID      string                `json:"user_id" msgpack:"user_id" cbor:"user_id" xml:"user_id"`
+	Status      string                `json:"status" msgpack:"status" cbor:"status" xml:"status"`
+	Transitions []StatusTransitionDTO `json:"transitions" msgpack:"transitions" cbor:"transitions" xml:"transitions>transition"`
+}
+
+// StatusTransitionDTO is a change of the status of a user, From is empty for the creation of the user
+type StatusTransitionDTO struct {
+	From   string    `json:"from,omitempty" msgpack:"from,omitempty" cbor:"from,omitempty" xml:"from,omitempty"`
+	To     string    `json:"to" msgpack:"to" cbor:"to" xml:"to"`
+	At     time.Time `json:"at" msgpack:"at" cbor:"at" xml:"at"`
+	Actor  string    `json:"actor,omitempty" msgpack:"actor,omitempty" cbor:"actor,omitempty" xml:"actor,omitempty"`
+	Reason string    `json:"reason,omitempty" msgpack:"reason,omitempty" cbor:"reason,omitempty" xml:"reason,omitempty"`
+}
+
+// FromEntity converts the status history of a userDomain.User to a StatusHistoryDTO
+func (s *StatusHistoryDTO) FromEntity(user *userDomain.User) {
+	s.UserID = user.ID
+	s.Status = string(user.Status)
+	s.Transitions = make([]StatusTransitionDTO, 0, len(user.StatusHistory))
+	for _, transition := range user.StatusHistory {
+		s.Transitions = append(s.Transitions, StatusTransitionDTO{
+			From:   string(transition.From),
+			To:     string(transition.To),
+			At:     transition.At,
+			Actor:  transition.Actor,
+			Reason: transition.Reason,
+		})
+	}
+}
+
+// DuplicatesDTO is the response of the possible duplicates of a user
+type DuplicatesDTO struct {
+	XMLName    xml.Name                `json:"-" msgpack:"-" cbor:"-" xml:"duplicates"`
+	UserID     string                  `json:"user_id" msgpack:"user_id" cbor:"user_id" xml:"user_id"`
+	Candidates []DuplicateCandidateDTO `json:"candidates" msgpack:"candidates" cbor:"candidates" xml:"candidates>candidate"`
+}
+
+// DuplicateCandidateDTO is a user scored as a possible duplicate and the reasons of its score
+type DuplicateCandidateDTO struct {
+	User             UserDTO `json:"user" msgpack:"user" cbor:"user" xml:"user"`
+	Score            float64 `json:"score" msgpack:"score" cbor:"score" xml:"score"`
+	NameSimilarity   float64 `json:"name_similarity" msgpack:"name_similarity" cbor:"name_similarity" xml:"name_similarity"`
+	EmailMatch       bool    `json:"email_match" msgpack:"email_match" cbor:"email_match" xml:"email_match"`
+	DateOfBirthMatch bool    `json:"date_of_birth_match" msgpack:"date_of_birth_match" cbor:"date_of_birth_match" xml:"date_of_birth_match"`
+}
+
+// MergeRequestDTO is the request merging duplicate users into a survivor
+type MergeRequestDTO struct {
+	XMLName      xml.Name       `json:"-" msgpack:"-" cbor:"-" xml:"merge"`
+	SurvivorID   string         `json:"survivor_id" msgpack:"survivor_id" cbor:"survivor_id" xml:"survivor_id"`
+	DuplicateIDs []string       `json:"duplicate_ids" msgpack:"duplicate_ids" cbor:"duplicate_ids" xml:"duplicate_ids>id"`
+	Fields       MergeFieldsDTO `json:"fields" msgpack:"fields" cbor:"fields" xml:"fields"`
+}
+
+// MergeFieldsDTO are the rules picking the merged fields: survivor, non_empty, newest or the id of a merged user.
+// Fields without a rule keep the value of the survivor.
+type MergeFieldsDTO struct {
+	FirstName string `json:"first_name,omitempty" msgpack:"first_name,omitempty" cbor:"first_name,omitempty" xml:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty" msgpack:"last_name,omitempty" cbor:"last_name,omitempty" xml:"last_name,omitempty"`
+	Email     string `json:"email,omitempty" msgpack:"email,omitempty" cbor:"email,omitempty" xml:"email,omitempty"`
+	// DateOfBirth also picks the age of legacy users
+	DateOfBirth string `json:"date_of_birth,omitempty" msgpack:"date_of_birth,omitempty" cbor:"date_of_birth,omitempty" xml:"date_of_birth,omitempty"`
+	Phone       string `json:"phone,omitempty" msgpack:"phone,omitempty" cbor:"phone,omitempty" xml:"phone,omitempty"`
+	Address     string `json:"address,omitempty" msgpack:"address,omitempty" cbor:"address,omitempty" xml:"address,omitempty"`
+	Locale      string `json:"locale,omitempty" msgpack:"locale,omitempty" cbor:"locale,omitempty" xml:"locale,omitempty"`
+	TimeZone    string `json:"time_zone,omitempty" msgpack:"time_zone,omitempty" cbor:"time_zone,omitempty" xml:"time_zone,omitempty"`
+}
+
+// ToEntity converts a MergeRequestDTO to a merge request
+func (m *MergeRequestDTO) ToEntity() userDomain.MergeRequest {
+	rules := make(map[string]string)
+	for field, rule := range map[string]string{
+		userDomain.FieldFirstName:   m.Fields.FirstName,
+		userDomain.FieldLastName:    m.Fields.LastName,
+		userDomain.FieldEmail:       m.Fields.Email,
+		userDomain.FieldDateOfBirth: m.Fields.DateOfBirth,
+		userDomain.FieldPhone:       m.Fields.Phone,
+		userDomain.FieldAddress:     m.Fields.Address,
+		userDomain.FieldLocale:      m.Fields.Locale,
+		userDomain.FieldTimeZone:    m.Fields.TimeZone,
+	} {
+		if rule != "" {
+			rules[field] = rule
+		}
+	}
+	return userDomain.MergeRequest{SurvivorID: m.SurvivorID, DuplicateIDs: m.DuplicateIDs, Rules: rules}
+}
+
+// FromCandidates converts the duplicate candidates of a user to a DuplicatesDTO with the candidates' ages at now,
+// scores are rounded to two decimals
+func (d *DuplicatesDTO) FromCandidates(userID string, candidates []userDomain.DuplicateCandidate, now time.Time) {
+	d.UserID = userID
+	d.Candidates = make([]DuplicateCandidateDTO, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidateDTO := DuplicateCandidateDTO{
+			Score:            math.Round(candidate.Score*100) / 100,
+			NameSimilarity:   math.Round(candidate.NameSimilarity*100) / 100,
+			EmailMatch:       candidate.EmailMatch,
+			DateOfBirthMatch: candidate.DateOfBirthMatch,
+		}
+		candidateDTO.User.FromEntity(candidate.User, now)
+		d.Candidates = append(d.Candidates, candidateDTO)
+	}
+}
+
+// ImportReportDTO is the response of a bulk import
+type ImportReportDTO struct {
+	XMLName  xml.Name       `json:"-" msgpack:"-" cbor:"-" xml:"import_report"`
+	DryRun   bool           `json:"dry_run" msgpack:"dry_run" cbor:"dry_run" xml:"dry_run"`
+	Created  int            `json:"created" msgpack:"created" cbor:"created" xml:"created"`
+	Updated  int            `json:"updated" msgpack:"updated" cbor:"updated" xml:"updated"`
+	Rejected int            `json:"rejected" msgpack:"rejected" cbor:"rejected" xml:"rejected"`
+	Rows     []ImportRowDTO `json:"rows" msgpack:"rows" cbor:"rows" xml:"rows>row"`
+}
+
+// ImportRowDTO is the outcome of importing a single row
+type ImportRowDTO struct {
+	Line   int              `json:"line" msgpack:"line" cbor:"line" xml:"line"`
+	ID     string           `json:"id,omitempty" msgpack:"id,omitempty" cbor:"id,omitempty" xml:"id,omitempty"`
+	Status string           `json:"status" msgpack:"status" cbor:"status" xml:"status"`
+	Errors []ImportErrorDTO `json:"errors,omitempty" msgpack:"errors,omitempty" cbor:"errors,omitempty" xml:"errors>error,omitempty"`
+	// Warnings are the concerns about an accepted row, such as its possible duplicates
+	Warnings []ImportErrorDTO `json:"warnings,omitempty" msgpack:"warnings,omitempty" cbor:"warnings,omitempty" xml:"warnings>warning,omitempty"`
+}
+
+// ImportErrorDTO is a reason a row was rejected or a warning about an accepted row
+type ImportErrorDTO struct {
+	Code    string `json:"code" msgpack:"code" cbor:"code" xml:"code"`
+	Message string `json:"message" msgpack:"message" cbor:"message" xml:"message"`
+	Field   string `json:"field,omitempty" msgpack:"field,omitempty" cbor:"field,omitempty" xml:"field,omitempty"`
+	// params localize the message and are not sent
+	params map[string]string
+}
+
+// FromReport converts a userApplication.ImportReport to an ImportReportDTO
+func (r *ImportReportDTO) FromReport(report *userApplication.ImportReport) {
+	r.DryRun = report.DryRun
+	r.Created = report.Created
+	r.Updated = report.Updated
+	r.Rejected = report.Rejected
+	r.Rows = make([]ImportRowDTO, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		r.Rows = append(r.Rows, ImportRowDTO{
+			Line:     row.Line,
+			ID:       row.ID,
+			Status:   string(row.Status),
+			Errors:   newImportErrorDTOs(row.Errors),
+			Warnings: newImportErrorDTOs(row.Warnings),
+		})
+	}
+}
+
+func newImportErrorDTOs(importErrors []userApplication.ImportError) []ImportErrorDTO {
+	var importErrorDTOs []ImportErrorDTO
+	for _, importError := range importErrors {
+		importErrorDTOs = append(importErrorDTOs, ImportErrorDTO{
+			Code:    importError.Code,
+			Message: importError.Message,
+			Field:   importError.Field,
+			params:  importError.Params,
+		})
+	}
+	return importErrorDTOs
+}
+
+// Localize translates the messages of the row errors and warnings, messages without a translation are kept
+func (r *ImportReportDTO) Localize(localizer i18n.Localizer) {
+	for _, row := range r.Rows {
+		for _, importErrors := range [][]ImportErrorDTO{row.Errors, row.Warnings} {
+			for i, importError := range importErrors {
+				importErrors[i].Message = localizer.Message(importError.Code, importError.params, importError.Message)
+			}
+		}
+	}
+}
+
+// ValidationReportDTO is the response of a dry run validation
+type ValidationReportDTO struct {
+	XMLName xml.Name             `json:"-" msgpack:"-" cbor:"-" xml:"validation"`
+	Valid   bool                 `json:"valid" msgpack:"valid" cbor:"valid" xml:"valid"`
+	Errors  []ValidationErrorDTO `json:"errors" msgpack:"errors" cbor:"errors" xml:"errors>error"`
+}
+
+// ValidationErrorDTO is a failed validation rule, the value is empty unless rejected values are exposed
+type ValidationErrorDTO struct {
+	Field    string            `json:"field,omitempty" msgpack:"field,omitempty" cbor:"field,omitempty" xml:"field,omitempty"`
+	Code     string            `json:"code" msgpack:"code" cbor:"code" xml:"code"`
+	Message  string            `json:"message" msgpack:"message" cbor:"message" xml:"message"`
+	Value    string            `json:"value,omitempty" msgpack:"value,omitempty" cbor:"value,omitempty" xml:"value,omitempty"`
+	Params   map[string]string `json:"params,omitempty" msgpack:"params,omitempty" cbor:"params,omitempty" xml:"-"`
+	Severity string            `json:"severity" msgpack:"severity" cbor:"severity" xml:"severity"`
+}
+
+// AuditTrailDTO is the response of the audit trail of a user
+type AuditTrailDTO struct {
+	XMLName xml.Name         `json:"-" msgpack:"-" cbor:"-" xml:"audit_trail"`
+	UserID  string           `json:"user_id" msgpack:"user_id" cbor:"user_id" xml:"user_id"`
+	Records []AuditRecordDTO `json:"records" msgpack:"records" cbor:"records" xml:"records>record"`
+}
+
+// AuditRecordDTO is a single change made to a user
+type AuditRecordDTO struct {
+	ID        string           `json:"id" msgpack:"id" cbor:"id" xml:"id"`
+	Action    string           `json:"action" msgpack:"action" cbor:"action" xml:"action"`
+	Actor     string           `json:"actor" msgpack:"actor" cbor:"actor" xml:"actor"`
+	RequestID string           `json:"request_id,omitempty" msgpack:"request_id,omitempty" cbor:"request_id,omitempty" xml:"request_id,omitempty"`
+	Timestamp time.Time        `json:"timestamp" msgpack:"timestamp" cbor:"timestamp" xml:"timestamp"`
+	Changes   []FieldChangeDTO `json:"changes" msgpack:"changes" cbor:"changes" xml:"changes>change"`
+	// MergedIDs are the users merged into the user, on the merge records of a survivor
+	MergedIDs []string `json:"merged_ids,omitempty" msgpack:"merged_ids,omitempty" cbor:"merged_ids,omitempty" xml:"merged_ids>id,omitempty"`
+	// MergedInto is the survivor, on the merge record of a merged user
+	MergedInto string `json:"merged_into,omitempty" msgpack:"merged_into,omitempty" cbor:"merged_into,omitempty" xml:"merged_into,omitempty"`
+}
+
+// FieldChangeDTO is the before and after value of a changed field
+type FieldChangeDTO struct {
+	Field  string `json:"field" msgpack:"field" cbor:"field" xml:"field"`
+	Before string `json:"before" msgpack:"before" cbor:"before" xml:"before"`
+	After  string `json:"after" msgpack:"after" cbor:"after" xml:"after"`
+}
+
+// FromRecords converts the audit records of a user to an AuditTrailDTO
+func (a *AuditTrailDTO) FromRecords(userID string, records []*userDomain.AuditRecord) {
+	a.UserID = userID
+	a.Records = make([]AuditRecordDTO, 0, len(records))
+	for _, record := range records {
+		recordDTO := AuditRecordDTO{
+			ID:         record.ID,
+			Action:     string(record.Action),
+			Actor:      record.Actor,
+			RequestID:  record.RequestID,
+			Timestamp:  record.Timestamp,
+			Changes:    make([]FieldChangeDTO, 0, len(record.Changes)),
+			MergedIDs:  record.MergedIDs,
+			MergedInto: record.MergedInto,
+		}
+		for _, change := range record.Changes {
+			recordDTO.Changes = append(recordDTO.Changes, FieldChangeDTO{Field: change.Field, Before: change.Before, After: change.After})
+		}
+		a.Records = append(a.Records, recordDTO)
+	}
 }
@@ -0,0 +1,39 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// Duplicates is the api handler for the /v1/users/{id}/duplicates route.
+// It lists the users scoring as possible duplicates of the user best first, whatever the duplicate detection mode of saves.
+func (h Handler) Duplicates() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(duplicatesRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			id := mux.Vars(r)["id"]
+			candidates, err := h.userService.Duplicates(r.Context(), id)
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+
+			var duplicatesDTO DuplicatesDTO
+			duplicatesDTO.FromCandidates(id, candidates, h.now())
+			if err := codec.Write(w, responseCodec, http.StatusOK, duplicatesDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
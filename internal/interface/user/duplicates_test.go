@@ -0,0 +1,119 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestDuplicates(t *testing.T) {
+	tests := []struct {
+		name           string
+		duplicatesFunc func(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error)
+		expectedStatus int
+	}{
+		{
+			name: "candidates found",
+			duplicatesFunc: func(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error) {
+				return []userDomain.DuplicateCandidate{{
+					User:           &userDomain.User{ID: "2", FirstName: "Jon", LastName: "Doe"},
+					Score:          0.96666,
+					NameSimilarity: 0.96666,
+				}}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "user not found",
+			duplicatesFunc: func(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error) {
+				return nil, fmt.Errorf("service: %w", userDomain.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{DuplicatesFunc: test.duplicatesFunc}).Duplicates().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/1/duplicates", nil))
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+			var duplicates DuplicatesDTO
+			if err := json.NewDecoder(w.Body).Decode(&duplicates); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if duplicates.UserID != "1" || len(duplicates.Candidates) != 1 {
+				t.Fatalf("expected one candidate for user 1, got %+v", duplicates)
+			}
+			if candidate := duplicates.Candidates[0]; candidate.User.ID != "2" || candidate.Score != 0.97 || candidate.NameSimilarity != 0.97 {
+				t.Errorf("unexpected candidate %+v", candidate)
+			}
+		})
+	}
+}
+
+func TestSave_Duplicates(t *testing.T) {
+	candidate := userDomain.DuplicateCandidate{User: &userDomain.User{ID: "2", FirstName: "Jon", LastName: "Doe"}, Score: 0.97}
+	tests := []struct {
+		name           string
+		saveFunc       func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "advisory",
+			saveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+				domainShared.AddWarnings(ctx, userDomain.NewPossibleDuplicateWarning(candidate))
+				return user, nil
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `"warnings":[{"code":"POSSIBLE_DUPLICATE","message":"Der Benutzer ist ein mögliches Duplikat des Benutzers 2",` +
+				`"params":{"score":"0.97","user_id":"2"},"severity":"warning"}]`,
+		},
+		{
+			name: "blocking",
+			saveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+				return nil, fmt.Errorf("service: %w", &userDomain.DuplicateError{Candidates: []userDomain.DuplicateCandidate{candidate}})
+			},
+			expectedStatus: http.StatusConflict,
+			expectedBody:   `"detail":"der Benutzer ist ein mögliches Duplikat der Benutzer 2"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{SaveFunc: test.saveFunc}).Save().AddRoute(r)
+			req := httptest.NewRequest("POST", "/save", strings.NewReader(`{"id":"1","first_name":"John","last_name":"Doe"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", "de")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+			if contentLanguage := w.Header().Get("Content-Language"); contentLanguage != "de" {
+				t.Errorf("expected Content-Language de, got %q", contentLanguage)
+			}
+		})
+	}
+}
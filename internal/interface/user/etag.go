@@ -0,0 +1,128 @@
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+const (
+	etagHeader            = "ETag"
+	lastModifiedHeader    = "Last-Modified"
+	cacheControlHeader    = "Cache-Control"
+	ifMatchHeader         = "If-Match"
+	ifNoneMatchHeader     = "If-None-Match"
+	ifModifiedSinceHeader = "If-Modified-Since"
+)
+
+// entityTag returns the unquoted tag of the state of a user, its version or,
+// for users saved before versioning, a hash of its fields
+func entityTag(user *userDomain.User) string {
+	if user.Version > 0 {
+		return strconv.FormatInt(user.Version, 10)
+	}
+	h := sha256.New()
+	for _, field := range []string{user.ID, user.FirstName, user.LastName, user.Email, strconv.Itoa(user.Age)} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	// the tags of users without a date of birth are unchanged since it was added
+	if !user.DateOfBirth.IsZero() {
+		h.Write([]byte(userDomain.FormatDateOfBirth(user.DateOfBirth)))
+	}
+	return "h" + hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// representationTag returns the strong entity tag of the user encoded by the codec at now,
+// the state tag followed by the media subtype so the representations of a user have different tags,
+// and by the age computed from the date of birth, which changes without a save
+func representationTag(user *userDomain.User, responseCodec codec.Codec, now time.Time) string {
+	mediaType := responseCodec.MediaTypes()[0]
+	tag := entityTag(user) + "-" + mediaType[strings.Index(mediaType, "/")+1:]
+	if !user.DateOfBirth.IsZero() {
+		tag += "-" + strconv.Itoa(user.AgeAt(now))
+	}
+	return `"` + tag + `"`
+}
+
+// lastModified returns when the representation of the user at now last changed, the later of its last save and,
+// as the computed age changes then, its last birthday
+func lastModified(user *userDomain.User, now time.Time) time.Time {
+	if user.DateOfBirth.IsZero() {
+		return user.UpdatedAt
+	}
+	return latest(user.UpdatedAt, userDomain.LastBirthday(user.DateOfBirth, now))
+}
+
+func latest(a time.Time, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+// taggedState returns the state tag of an entity tag of any representation
+func taggedState(tag string) string {
+	tag = strings.TrimSuffix(strings.TrimPrefix(tag, `"`), `"`)
+	state, _, _ := strings.Cut(tag, "-")
+	return state
+}
+
+// writeValidators sets the headers clients use to revalidate the user encoded by the codec at now
+func writeValidators(w http.ResponseWriter, user *userDomain.User, responseCodec codec.Codec, now time.Time) {
+	w.Header().Set(etagHeader, representationTag(user, responseCodec, now))
+	if !user.UpdatedAt.IsZero() {
+		w.Header().Set(lastModifiedHeader, lastModified(user, now).UTC().Format(http.TimeFormat))
+	}
+}
+
+// checkIfMatch requires an If-Match header holding the entity tag of any representation of the user, or "*".
+// Weak tags never match.
+func checkIfMatch(r *http.Request, user *userDomain.User) error {
+	header := strings.TrimSpace(r.Header.Get(ifMatchHeader))
+	if header == "" {
+		return &shared.ProblemError{Problem: shared.NewProblem(http.StatusPreconditionRequired, "the If-Match header is required to change a user")}
+	}
+	if header == "*" {
+		return nil
+	}
+	state := entityTag(user)
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); strings.HasPrefix(tag, `"`) && taggedState(tag) == state {
+			return nil
+		}
+	}
+	return &shared.ProblemError{
+		Problem: shared.NewProblem(http.StatusPreconditionFailed, "the user was changed since it was read"),
+		Err:     userDomain.ErrVersionConflict,
+	}
+}
+
+// notModified reports whether the client's copy of the user encoded by the codec at now is current.
+// If-Modified-Since is only evaluated without If-None-Match, which is compared weakly.
+func notModified(r *http.Request, user *userDomain.User, responseCodec codec.Codec, now time.Time) bool {
+	if header := strings.TrimSpace(r.Header.Get(ifNoneMatchHeader)); header != "" {
+		if header == "*" {
+			return true
+		}
+		etag := representationTag(user, responseCodec, now)
+		for _, tag := range strings.Split(header, ",") {
+			if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+	if user.UpdatedAt.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get(ifModifiedSinceHeader))
+	return err == nil && !lastModified(user, now).Truncate(time.Second).After(since)
+}
@@ -0,0 +1,303 @@
+package user
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+)
+
+// newVersionedUserService creates a mock service holding a single user at version 3
+func newVersionedUserService() *mockUserApplicationService {
+	stored := userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 3}
+	find := func(ctx context.Context, id string) (*userDomain.User, error) {
+		found := stored
+		return &found, nil
+	}
+	update := func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+		if user.Version != stored.Version {
+			return nil, fmt.Errorf("service: %w", userDomain.ErrVersionConflict)
+		}
+		updated := *user
+		updated.Version = stored.Version + 1
+		return &updated, nil
+	}
+	return &mockUserApplicationService{FindFunc: find, SaveFunc: update, UpdateFunc: update}
+}
+
+func TestUpdate_IfMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		ifMatch        string
+		body           string
+		expectedStatus int
+		expectedETag   string
+		expectedBody   string
+	}{
+		{
+			name:           "replace at the current version",
+			method:         "PUT",
+			ifMatch:        `"3"`,
+			body:           `{"first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4-json"`,
+			expectedBody:   `"first_name":"Johnny"`,
+		},
+		{
+			name:           "patch at the current version",
+			method:         "PATCH",
+			ifMatch:        `"3"`,
+			body:           `{"age": 26}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4-json"`,
+			expectedBody:   `"first_name":"John"`,
+		},
+		{
+			name:           "patch any version",
+			method:         "PATCH",
+			ifMatch:        "*",
+			body:           `{"age": 26}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4-json"`,
+		},
+		{
+			name:           "replace with the tag of another representation",
+			method:         "PUT",
+			ifMatch:        `"3-xml"`,
+			body:           `{"first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4-json"`,
+			expectedBody:   `"first_name":"Johnny"`,
+		},
+		{
+			name:           "replace a stale version",
+			method:         "PUT",
+			ifMatch:        `"2"`,
+			body:           `{"first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "patch a stale version",
+			method:         "PATCH",
+			ifMatch:        `"2"`,
+			body:           `{"age": 26}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "weak entity tag",
+			method:         "PUT",
+			ifMatch:        `W/"3"`,
+			body:           `{"first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "missing If-Match",
+			method:         "PATCH",
+			body:           `{"age": 26}`,
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:           "id does not match the path",
+			method:         "PUT",
+			ifMatch:        `"3"`,
+			body:           `{"id": "2", "first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "save at the current version",
+			method:         "POST",
+			path:           "/save",
+			ifMatch:        `"3"`,
+			body:           `{"id": "1", "first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusOK,
+			expectedETag:   `"4-json"`,
+			expectedBody:   `"first_name":"Johnny"`,
+		},
+		{
+			name:           "save a stale version",
+			method:         "POST",
+			path:           "/save",
+			ifMatch:        `"2"`,
+			body:           `{"id": "1", "first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+		{
+			name:           "save an existing user without If-Match",
+			method:         "POST",
+			path:           "/save",
+			body:           `{"id": "1", "first_name": "Johnny", "last_name": "Doe", "email": "john@example.com", "age": 26}`,
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			handler := NewHandler(newVersionedUserService())
+			handler.Save().AddRoute(r)
+			handler.Replace().AddRoute(r)
+			handler.Patch().AddRoute(r)
+			path := test.path
+			if path == "" {
+				path = "/v1/users/1"
+			}
+			req := httptest.NewRequest(test.method, path, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			if test.ifMatch != "" {
+				req.Header.Set("If-Match", test.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if etag := w.Header().Get("ETag"); etag != test.expectedETag {
+				t.Errorf("expected ETag %q, got %q", test.expectedETag, etag)
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestFind_Conditional(t *testing.T) {
+	updatedAt := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	versioned := &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25, Version: 3, UpdatedAt: updatedAt}
+	legacy := &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}
+	// the user turned 24 on June 15, after the last save
+	born := &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Version: 3, UpdatedAt: updatedAt,
+		DateOfBirth: time.Date(2000, 6, 15, 0, 0, 0, 0, time.UTC)}
+	now := time.Date(2024, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                 string
+		user                 *userDomain.User
+		headers              map[string]string
+		expectedStatus       int
+		expectedETag         string
+		expectedLastModified string
+	}{
+		{
+			name:                 "unconditional read",
+			user:                 versioned,
+			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3-json"`,
+			expectedLastModified: "Wed, 01 May 2024 12:00:00 GMT",
+		},
+		{
+			name:                 "matching entity tag",
+			user:                 versioned,
+			headers:              map[string]string{"If-None-Match": `"2-json", W/"3-json"`},
+			expectedStatus:       http.StatusNotModified,
+			expectedETag:         `"3-json"`,
+			expectedLastModified: "Wed, 01 May 2024 12:00:00 GMT",
+		},
+		{
+			name:                 "stale entity tag wins over a current date",
+			user:                 versioned,
+			headers:              map[string]string{"If-None-Match": `"2-json"`, "If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"},
+			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3-json"`,
+			expectedLastModified: "Wed, 01 May 2024 12:00:00 GMT",
+		},
+		{
+			name:                 "not modified since",
+			user:                 versioned,
+			headers:              map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"},
+			expectedStatus:       http.StatusNotModified,
+			expectedETag:         `"3-json"`,
+			expectedLastModified: "Wed, 01 May 2024 12:00:00 GMT",
+		},
+		{
+			name:                 "modified since",
+			user:                 versioned,
+			headers:              map[string]string{"If-Modified-Since": "Wed, 01 May 2024 11:59:59 GMT"},
+			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3-json"`,
+			expectedLastModified: "Wed, 01 May 2024 12:00:00 GMT",
+		},
+		{
+			name:           "unversioned user is tagged with a content hash",
+			user:           legacy,
+			headers:        map[string]string{"If-None-Match": representationTag(legacy, codec.JSON(), now)},
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   representationTag(legacy, codec.JSON(), now),
+		},
+		{
+			name:                 "birthday changes the entity tag",
+			user:                 born,
+			headers:              map[string]string{"If-None-Match": `"3-json-23"`},
+			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3-json-24"`,
+			expectedLastModified: "Sat, 15 Jun 2024 00:00:00 GMT",
+		},
+		{
+			name:                 "birthday is a modification",
+			user:                 born,
+			headers:              map[string]string{"If-Modified-Since": "Wed, 01 May 2024 12:00:00 GMT"},
+			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3-json-24"`,
+			expectedLastModified: "Sat, 15 Jun 2024 00:00:00 GMT",
+		},
+		{
+			name:                 "not modified since the birthday",
+			user:                 born,
+			headers:              map[string]string{"If-None-Match": `"3-json-24"`},
+			expectedStatus:       http.StatusNotModified,
+			expectedETag:         `"3-json-24"`,
+			expectedLastModified: "Sat, 15 Jun 2024 00:00:00 GMT",
+		},
+		{
+			name:                 "other representations have other tags",
+			user:                 versioned,
+			headers:              map[string]string{"Accept": "application/xml", "If-None-Match": `"3-json"`},
+			expectedStatus:       http.StatusOK,
+			expectedETag:         `"3-xml"`,
+			expectedLastModified: "Wed, 01 May 2024 12:00:00 GMT",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{
+				FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return test.user, nil
+				},
+			}, WithCacheControl("private, max-age=60"), WithClock(func() time.Time { return now })).Find().AddRoute(r)
+			req := httptest.NewRequest("GET", "/find/1", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+			if test.expectedStatus == http.StatusNotModified && w.Body.Len() != 0 {
+				t.Errorf("expected an empty body, got %s", w.Body.String())
+			}
+			if etag := w.Header().Get("ETag"); etag != test.expectedETag {
+				t.Errorf("expected ETag %q, got %q", test.expectedETag, etag)
+			}
+			if lastModified := w.Header().Get("Last-Modified"); lastModified != test.expectedLastModified {
+				t.Errorf("expected Last-Modified %q, got %q", test.expectedLastModified, lastModified)
+			}
+			if cacheControl := w.Header().Get("Cache-Control"); cacheControl != "private, max-age=60" {
+				t.Errorf("expected Cache-Control to be set, got %q", cacheControl)
+			}
+		})
+	}
+}
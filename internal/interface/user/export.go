@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// exportFlushInterval is the number of users written between flushes to the client
+const exportFlushInterval = 500
+
+// Export is the api handler for the /v1/users:export route.
+// Users are streamed from a repository cursor in the format given by the format query parameter.
+func (h Handler) Export() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(exportRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			format, err := bulk.ParseFormat(r.URL.Query().Get("format"))
+			if err != nil {
+				problem := shared.NewProblem(http.StatusBadRequest, "request contains an invalid query parameter")
+				problem.InvalidParams = []shared.InvalidParam{{Name: "format", Reason: "must be one of csv, ndjson or parquet"}}
+				shared.WriteError(w, r, &shared.ProblemError{Problem: problem, Err: err})
+				return
+			}
+
+			filter, err := listFilterFromQuery(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			ctx := r.Context()
+			cursor, err := h.userService.Stream(ctx, filter)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			defer cursor.Close(ctx)
+
+			w.Header().Set("Content-Type", format.ContentType())
+			w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, format))
+			w.WriteHeader(http.StatusOK)
+
+			if err := writeExport(w, format, cursor, r, h.now()); err != nil {
+				if errors.Is(ctx.Err(), context.Canceled) {
+					// the client went away, there is nobody to report to
+					return
+				}
+				log.Printf(`{"level":"error","msg":"export failed","path":"%s","error":%q}`, r.URL.Path, err.Error())
+				// abort the response so the client does not mistake a truncated export for a complete one
+				panic(http.ErrAbortHandler)
+			}
+		},
+	}
+}
+
+func writeExport(w http.ResponseWriter, format bulk.Format, cursor userDomain.Cursor, r *http.Request, now time.Time) error {
+	writer, err := bulk.NewWriter(format, w, now)
+	if err != nil {
+		return err
+	}
+	flusher, _ := w.(http.Flusher)
+
+	written := 0
+	for cursor.Next(r.Context()) {
+		if err := writer.Write(cursor.User()); err != nil {
+			return fmt.Errorf("failed to write user: %w", err)
+		}
+		if written++; flusher != nil && written%exportFlushInterval == 0 {
+			flusher.Flush()
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+	return writer.Close()
+}
@@ -0,0 +1,154 @@
+package user
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/parquet-go/parquet-go"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+)
+
+type sliceCursor struct {
+	users []*userDomain.User
+	index int
+}
+
+func (c *sliceCursor) Next(ctx context.Context) bool {
+	if ctx.Err() != nil || c.index >= len(c.users) {
+		return false
+	}
+	c.index++
+	return true
+}
+func (c *sliceCursor) User() *userDomain.User          { return c.users[c.index-1] }
+func (c *sliceCursor) Err() error                      { return nil }
+func (c *sliceCursor) Close(ctx context.Context) error { return nil }
+
+func TestExport(t *testing.T) {
+	users := []*userDomain.User{
+		{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25},
+		{ID: "2", FirstName: "Jane", LastName: "Doe", Email: "jane@example.com", Age: 30},
+	}
+	tests := []struct {
+		name                string
+		target              string
+		expectedStatus      int
+		expectedContentType string
+		countUsers          func(t *testing.T, body []byte) int
+	}{
+		{
+			name:                "csv",
+			target:              "/v1/users:export?format=csv&last_name=Doe",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			countUsers: func(t *testing.T, body []byte) int {
+				records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+				if err != nil {
+					t.Fatalf("failed to read csv: %v", err)
+				}
+				return len(records) - 1
+			},
+		},
+		{
+			name:                "ndjson",
+			target:              "/v1/users:export?format=ndjson",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			countUsers: func(t *testing.T, body []byte) int {
+				count := 0
+				scanner := bufio.NewScanner(bytes.NewReader(body))
+				for scanner.Scan() {
+					var record bulk.Record
+					if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+						t.Fatalf("failed to unmarshal line: %v", err)
+					}
+					count++
+				}
+				return count
+			},
+		},
+		{
+			name:                "parquet",
+			target:              "/v1/users:export?format=parquet",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/vnd.apache.parquet",
+			countUsers: func(t *testing.T, body []byte) int {
+				records, err := parquet.Read[bulk.Record](bytes.NewReader(body), int64(len(body)))
+				if err != nil {
+					t.Fatalf("failed to read parquet: %v", err)
+				}
+				return len(records)
+			},
+		},
+		{
+			name:                "unknown format",
+			target:              "/v1/users:export?format=xlsx",
+			expectedStatus:      http.StatusBadRequest,
+			expectedContentType: "application/problem+json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := mux.NewRouter()
+			var gotFilter userDomain.ListFilter
+			userService := &mockUserApplicationService{
+				StreamFunc: func(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error) {
+					gotFilter = filter
+					return &sliceCursor{users: users}, nil
+				},
+			}
+			NewHandler(userService).Export().AddRoute(r)
+			r.ServeHTTP(w, httptest.NewRequest("GET", test.target, nil))
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != test.expectedContentType {
+				t.Errorf("expected Content-Type %s, got %s", test.expectedContentType, got)
+			}
+			if test.countUsers == nil {
+				return
+			}
+			if count := test.countUsers(t, w.Body.Bytes()); count != len(users) {
+				t.Errorf("expected %d exported users, got %d", len(users), count)
+			}
+			if test.name == "csv" && gotFilter.LastName != "Doe" {
+				t.Errorf("expected last_name filter Doe, got %q", gotFilter.LastName)
+			}
+		})
+	}
+}
+
+func TestExport_ClientDisconnect(t *testing.T) {
+	users := make([]*userDomain.User, 10)
+	for i := range users {
+		users[i] = &userDomain.User{ID: "1"}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	w := httptest.NewRecorder()
+	r := mux.NewRouter()
+	cursor := &sliceCursor{users: users}
+	userService := &mockUserApplicationService{
+		StreamFunc: func(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error) {
+			return cursor, nil
+		},
+	}
+	NewHandler(userService).Export().AddRoute(r)
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users:export?format=ndjson", nil).WithContext(ctx))
+
+	if cursor.index != 0 {
+		t.Errorf("expected the cursor not to be iterated after the client disconnected, iterated %d users", cursor.index)
+	}
+}
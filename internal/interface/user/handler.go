@@ -3,18 +3,35 @@ package user
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
 	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
 
 	"github.com/gorilla/mux"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/i18n"
 	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
 )
 
 const (
-	findRoute = "/find/{id}"
-	saveRoute = "/save"
+	findRoute     = "/find/{id}"
+	saveRoute     = "/save"
+	importRoute   = "/v1/users:import"
+	exportRoute   = "/v1/users:export"
+	validateRoute = "/v1/users:validate"
+	mergeRoute    = "/v1/users:merge"
+	userRoute     = "/v1/users/{id}"
+	auditRoute    = "/v1/users/{id}/audit"
+	usersRoute    = "/v1/users"
+	// statusHistoryRoute lists the status transitions of a user
+	statusHistoryRoute = "/v1/users/{id}/status-history"
+	// duplicatesRoute lists the possible duplicates of a user
+	duplicatesRoute = "/v1/users/{id}/duplicates"
 )
 
 type userApplicationService interface {
@@ -22,61 +39,382 @@ type userApplicationService interface {
 	Find(ctx context.Context, id string) (*userDomain.User, error)
 	// Save saves a user
 	Save(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	// Update replaces an existing user if it is still at the user's version
+	Update(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	// List lists the users matching the filter
+	List(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error)
+	// Stream returns a cursor over the users matching the filter
+	Stream(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error)
+	// Validate checks the user like a save without saving it, returning the failures of field or of every field
+	Validate(ctx context.Context, user *userDomain.User, field string) []domainShared.ValidationError
+	// Import validates and saves the users read from the reader
+	Import(ctx context.Context, reader userApplication.ImportRowReader, dryRun bool) (*userApplication.ImportReport, error)
+	// Transition changes the status of a user to the target status for the reason
+	Transition(ctx context.Context, id string, target userDomain.Status, reason string) (*userDomain.User, error)
+	// Delete deletes a user by id
+	Delete(ctx context.Context, id string) error
+	// AuditTrail returns the changes made to a user
+	AuditTrail(ctx context.Context, id string) ([]*userDomain.AuditRecord, error)
+	// Duplicates returns the users scoring as possible duplicates of a user, best first
+	Duplicates(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error)
+	// Merge merges duplicate users into a survivor and returns the merged survivor
+	Merge(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error)
 }
 
 // Handler is a handler for the user domain
 type Handler struct {
-	userService userApplicationService
+	userService    userApplicationService
+	codecs         *codec.Registry
+	cacheControl   string
+	rejectedValues bool
+	now            func() time.Time
+}
+
+// HandlerOption configures a Handler
+type HandlerOption func(h *Handler)
+
+// WithCacheControl sets the Cache-Control header of user reads, no header is sent when it is empty
+func WithCacheControl(cacheControl string) HandlerOption {
+	return func(h *Handler) {
+		h.cacheControl = cacheControl
+	}
+}
+
+// WithRejectedValues includes the rejected values in the invalid params of validation problems, they are redacted by default
+func WithRejectedValues() HandlerOption {
+	return func(h *Handler) {
+		h.rejectedValues = true
+	}
+}
+
+// WithClock sets the clock the ages of users are computed with, it is time.Now by default
+func WithClock(now func() time.Time) HandlerOption {
+	return func(h *Handler) {
+		h.now = now
+	}
 }
 
 // NewHandler creates a new handler for the user domain
-func NewHandler(userService userApplicationService) *Handler {
-	return &Handler{userService: userService}
+func NewHandler(userService userApplicationService, options ...HandlerOption) *Handler {
+	h := &Handler{
+		userService: userService,
+		codecs:      codec.NewDefaultRegistry(),
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(h)
+	}
+	return h
 }
 
-// Find is the api handler for the /find/{id} route
+// Find is the api handler for the /find/{id} route.
+// It answers 304 Not Modified when the If-None-Match or If-Modified-Since headers show the client's copy is current,
+// and redirects users merged into another user to their survivor.
 func (h Handler) Find() shared.Handler {
 	return shared.Handler{
 		Route: func(r *mux.Route) {
 			r.Path(findRoute).Methods("GET")
 		},
 		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
 			id := mux.Vars(r)["id"]
 			user, err := h.userService.Find(r.Context(), id)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				h.writeServiceError(w, r, err)
 				return
 			}
 
-			var userDTO UserDTO
-			userDTO.FromEntity(user)
+			now := h.now()
+			writeValidators(w, user, responseCodec, now)
+			if h.cacheControl != "" {
+				w.Header().Set(cacheControlHeader, h.cacheControl)
+			}
+			if notModified(r, user, responseCodec, now) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
 
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(userDTO)
+			var userDTO UserDTO
+			userDTO.FromEntity(user, now)
+			if err := codec.Write(w, responseCodec, http.StatusOK, userDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
 		},
 	}
 }
 
-// Save is the api handler for the /save route
+// Save is the api handler for the /save route.
+// It creates a user, or replaces the user whose entity tag the If-Match header matches.
+// The possible duplicates of the user are returned as warnings in advisory mode and rejected as conflicts in blocking mode.
 func (h Handler) Save() shared.Handler {
 	return shared.Handler{
 		Route: func(r *mux.Route) {
 			r.Path(saveRoute).Methods("POST")
 		},
 		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
 			var userRequest UserDTO
-			json.NewDecoder(r.Body).Decode(&userRequest)
-			user, err := h.userService.Save(r.Context(), userRequest.ToEntity())
+			if err := h.codecs.Decode(w, r, &userRequest); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			userToSave, err := userRequest.ToEntity()
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				shared.WriteError(w, r, err)
+				return
+			}
+			// without If-Match only a new user is saved, the replace only applies to the version the precondition was checked against
+			if r.Header.Get(ifMatchHeader) != "" {
+				currentUser, err := h.userService.Find(r.Context(), userToSave.ID)
+				if err != nil {
+					h.writeServiceError(w, r, err)
+					return
+				}
+				if err := checkIfMatch(r, currentUser); err != nil {
+					shared.WriteError(w, r, err)
+					return
+				}
+				userToSave.Version = currentUser.Version
+			}
+			ctx := domainShared.WithWarnings(r.Context())
+			user, err := h.userService.Save(ctx, userToSave)
+			if err != nil {
+				h.writeServiceError(w, r, err)
 				return
 			}
 			var userResponse UserDTO
-			userResponse.FromEntity(user)
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusOK)
-			json.NewEncoder(w).Encode(userResponse)
+			now := h.now()
+			userResponse.FromEntity(user, now)
+			userResponse.Warnings = h.warnings(w, r, domainShared.WarningsFromContext(ctx))
+			writeValidators(w, user, responseCodec, now)
+			if err := codec.Write(w, responseCodec, http.StatusOK, userResponse); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// Replace is the api handler for the PUT /v1/users/{id} route, it requires the If-Match header
+func (h Handler) Replace() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(userRoute).Methods("PUT")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			var userRequest UserDTO
+			if err := h.codecs.Decode(w, r, &userRequest); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			userToUpdate, err := userRequest.ToEntity()
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			id := mux.Vars(r)["id"]
+			if userRequest.ID != "" && userRequest.ID != id {
+				problem := shared.NewProblem(http.StatusBadRequest, "the user id does not match the path")
+				problem.InvalidParams = []shared.InvalidParam{{Name: "id", Reason: "must be empty or " + id}}
+				shared.WriteProblem(w, r, problem)
+				return
+			}
+			currentUser, err := h.userService.Find(r.Context(), id)
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+			if err := checkIfMatch(r, currentUser); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			// the update only applies to the version the precondition was checked against
+			userToUpdate.ID = id
+			userToUpdate.Version = currentUser.Version
+			h.writeUpdate(w, r, responseCodec, userToUpdate)
+		},
+	}
+}
+
+// Patch is the api handler for the PATCH /v1/users/{id} route, it requires the If-Match header
+func (h Handler) Patch() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(userRoute).Methods("PATCH")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			var patch UserPatchDTO
+			if err := h.codecs.Decode(w, r, &patch); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			currentUser, err := h.userService.Find(r.Context(), mux.Vars(r)["id"])
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+			if err := checkIfMatch(r, currentUser); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			// the patch only applies to the version it was read at, even with "*"
+			patched := *currentUser
+			if err := patch.Apply(&patched); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			h.writeUpdate(w, r, responseCodec, &patched)
+		},
+	}
+}
+
+// writeUpdate updates the user and writes it with its new entity tag and the warnings of the update
+func (h Handler) writeUpdate(w http.ResponseWriter, r *http.Request, responseCodec codec.Codec, userToUpdate *userDomain.User) {
+	ctx := domainShared.WithWarnings(r.Context())
+	user, err := h.userService.Update(ctx, userToUpdate)
+	if err != nil {
+		h.writeServiceError(w, r, err)
+		return
+	}
+	var userResponse UserDTO
+	now := h.now()
+	userResponse.FromEntity(user, now)
+	userResponse.Warnings = h.warnings(w, r, domainShared.WarningsFromContext(ctx))
+	writeValidators(w, user, responseCodec, now)
+	if err := codec.Write(w, responseCodec, http.StatusOK, userResponse); err != nil {
+		shared.WriteError(w, r, err)
+	}
+}
+
+// Delete is the api handler for the DELETE /v1/users/{id} route
+func (h Handler) Delete() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(userRoute).Methods("DELETE")
 		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			if err := h.userService.Delete(r.Context(), mux.Vars(r)["id"]); err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	}
+}
+
+// Message keys of the problems of the user api, name conflicts share the key of their validation error code
+const (
+	messageUserNotFound              = "USER_NOT_FOUND"
+	messageUserVersionConflict       = "USER_VERSION_CONFLICT"
+	messageUserNameCombinationExists = userDomain.ErrorNameCombinationExists
+	messageUserTransitionInvalid     = "USER_TRANSITION_INVALID"
+	messageUserPossibleDuplicate     = "USER_POSSIBLE_DUPLICATE"
+	messageUserMerged                = "USER_MERGED"
+	messageUserMergeInvalid          = "USER_MERGE_INVALID"
+)
+
+// writeServiceError writes a service error as a problem response, reporting unknown users as not found,
+// stale versions as failed preconditions, disallowed status transitions and possible duplicates as conflicts
+// and invalid users and merge requests as bad requests with an invalid param per validation error.
+// Reads of a user merged into another user are redirected to the same route of the survivor, other requests
+// report it as not found. The details are in the language of the Accept-Language header.
+func (h Handler) writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var mergedErr *userDomain.MergedError
+	if errors.As(err, &mergedErr) && r.Method == http.MethodGet && redirectMerged(w, r, mergedErr) {
+		return
+	}
+	localizer := i18n.FromRequest(r)
+	validationErrors := domainShared.ValidationErrors(err)
+	var mergeErr *userDomain.MergeError
+	var status int
+	var detail string
+	var invalidParams []shared.InvalidParam
+	switch {
+	case mergedErr != nil:
+		params := map[string]string{"survivor_id": mergedErr.MergedInto}
+		status, detail = http.StatusNotFound, localizer.Message(messageUserMerged, params, mergedErr.Error())
+	case errors.As(err, &mergeErr):
+		status, detail = http.StatusBadRequest, localizer.Message(messageUserMergeInvalid, nil, userDomain.ErrInvalidMerge.Error())
+		invalidParams = []shared.InvalidParam{{Name: mergeErr.Param, Reason: mergeErr.Reason}}
+	case errors.Is(err, userDomain.ErrNotFound):
+		status, detail = http.StatusNotFound, localizer.Message(messageUserNotFound, nil, "user not found")
+	case errors.Is(err, userDomain.ErrVersionConflict):
+		status, detail = http.StatusPreconditionFailed, localizer.Message(messageUserVersionConflict, nil, "the user was changed since it was read")
+	case errors.Is(err, userDomain.ErrInvalidTransition):
+		var transitionErr *userDomain.TransitionError
+		params := map[string]string{}
+		fallback := userDomain.ErrInvalidTransition.Error()
+		if errors.As(err, &transitionErr) {
+			params["from"], params["to"] = string(transitionErr.From), string(transitionErr.To)
+			fallback = transitionErr.Error()
+		}
+		status, detail = http.StatusConflict, localizer.Message(messageUserTransitionInvalid, params, fallback)
+	case errors.Is(err, userDomain.ErrPossibleDuplicate):
+		var duplicateErr *userDomain.DuplicateError
+		var ids []string
+		if errors.As(err, &duplicateErr) {
+			for _, candidate := range duplicateErr.Candidates {
+				ids = append(ids, candidate.User.ID)
+			}
+		}
+		params := map[string]string{"user_ids": strings.Join(ids, ", ")}
+		status, detail = http.StatusConflict, localizer.Message(messageUserPossibleDuplicate, params, userDomain.ErrPossibleDuplicate.Error())
+	case errors.Is(err, userDomain.ErrNameCombinationExists):
+		status, detail = http.StatusConflict, localizer.Message(messageUserNameCombinationExists, nil, userDomain.ErrNameCombinationExists.Error())
+	case len(validationErrors) > 0:
+		messages := make([]string, 0, len(validationErrors))
+		for _, validationError := range validationErrors {
+			message := localizer.ValidationError(validationError)
+			messages = append(messages, message)
+			if !h.rejectedValues {
+				validationError = validationError.Redacted()
+			}
+			invalidParams = append(invalidParams, shared.NewInvalidParam(validationError, message))
+		}
+		status, detail = http.StatusBadRequest, strings.Join(messages, "\n")
+	}
+	if status != 0 {
+		localizer.WriteHeaders(w.Header())
+		problem := shared.NewProblem(status, detail)
+		problem.InvalidParams = invalidParams
+		err = &shared.ProblemError{Problem: problem, Err: err}
+	}
+	shared.WriteError(w, r, err)
+}
+
+// redirectMerged redirects a request for a merged user to the same route and query for its survivor,
+// it returns false when the route has no user id to replace
+func redirectMerged(w http.ResponseWriter, r *http.Request, mergedErr *userDomain.MergedError) bool {
+	route := mux.CurrentRoute(r)
+	if route == nil || mux.Vars(r)["id"] != mergedErr.ID {
+		return false
+	}
+	location, err := route.URLPath("id", mergedErr.MergedInto)
+	if err != nil {
+		return false
 	}
+	location.RawQuery = r.URL.RawQuery
+	http.Redirect(w, r, location.String(), http.StatusMovedPermanently)
+	return true
 }
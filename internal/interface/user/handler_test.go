@@ -4,18 +4,37 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fxamacker/cbor/v2"
 	"github.com/gorilla/mux"
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
 	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 type mockUserApplicationService struct {
-	FindFunc func(ctx context.Context, id string) (*userDomain.User, error)
-	SaveFunc func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	FindFunc       func(ctx context.Context, id string) (*userDomain.User, error)
+	SaveFunc       func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	UpdateFunc     func(ctx context.Context, user *userDomain.User) (*userDomain.User, error)
+	ListFunc       func(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error)
+	StreamFunc     func(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error)
+	ImportFunc     func(ctx context.Context, reader userApplication.ImportRowReader, dryRun bool) (*userApplication.ImportReport, error)
+	ValidateFunc   func(ctx context.Context, user *userDomain.User, field string) []domainShared.ValidationError
+	DeleteFunc     func(ctx context.Context, id string) error
+	AuditFunc      func(ctx context.Context, id string) ([]*userDomain.AuditRecord, error)
+	TransitionFunc func(ctx context.Context, id string, target userDomain.Status, reason string) (*userDomain.User, error)
+	DuplicatesFunc func(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error)
+	MergeFunc      func(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error)
 }
 
 func (m *mockUserApplicationService) Find(ctx context.Context, id string) (*userDomain.User, error) {
@@ -26,6 +45,45 @@ func (m *mockUserApplicationService) Save(ctx context.Context, user *userDomain.
 	return m.SaveFunc(ctx, user)
 }
 
+func (m *mockUserApplicationService) Update(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+	return m.UpdateFunc(ctx, user)
+}
+
+func (m *mockUserApplicationService) List(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error) {
+	return m.ListFunc(ctx, filter)
+}
+
+func (m *mockUserApplicationService) Transition(ctx context.Context, id string, target userDomain.Status, reason string) (*userDomain.User, error) {
+	return m.TransitionFunc(ctx, id, target, reason)
+}
+
+func (m *mockUserApplicationService) Stream(ctx context.Context, filter userDomain.ListFilter) (userDomain.Cursor, error) {
+	return m.StreamFunc(ctx, filter)
+}
+
+func (m *mockUserApplicationService) Import(ctx context.Context, reader userApplication.ImportRowReader, dryRun bool) (*userApplication.ImportReport, error) {
+	return m.ImportFunc(ctx, reader, dryRun)
+}
+
+func (m *mockUserApplicationService) Validate(ctx context.Context, user *userDomain.User, field string) []domainShared.ValidationError {
+	return m.ValidateFunc(ctx, user, field)
+}
+
+func (m *mockUserApplicationService) Delete(ctx context.Context, id string) error {
+	return m.DeleteFunc(ctx, id)
+}
+
+func (m *mockUserApplicationService) AuditTrail(ctx context.Context, id string) ([]*userDomain.AuditRecord, error) {
+	return m.AuditFunc(ctx, id)
+}
+
+func (m *mockUserApplicationService) Duplicates(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error) {
+	return m.DuplicatesFunc(ctx, id)
+}
+func (m *mockUserApplicationService) Merge(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error) {
+	return m.MergeFunc(ctx, request)
+}
+
 func TestFind_HappyPath(t *testing.T) {
 	w := httptest.NewRecorder()
 	r := mux.NewRouter()
@@ -90,7 +148,7 @@ func TestSave_HappyPath(t *testing.T) {
 
 	// Create a UserDTO for the request body
 	var userRequest UserDTO
-	userRequest.FromEntity(serviceUser)
+	userRequest.FromEntity(serviceUser, time.Now())
 	body, err := json.Marshal(userRequest)
 	if err != nil {
 		t.Fatalf("failed to marshal userRequest: %v", err)
@@ -133,9 +191,385 @@ func TestSave_Error(t *testing.T) {
 	}
 	handler := NewHandler(userService)
 	handler.Save().AddRoute(r)
-	r.ServeHTTP(w, httptest.NewRequest("POST", "/save", nil))
+	req := httptest.NewRequest("POST", "/save", strings.NewReader(`{"id":"1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
 
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 }
+
+func TestSave_DateOfBirth(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name                string
+		body                string
+		expectedDateOfBirth time.Time
+		expectedAge         int
+	}{
+		{
+			name:                "date of birth",
+			body:                `{"first_name":"John","last_name":"Doe","date_of_birth":"2006-06-15"}`,
+			expectedDateOfBirth: time.Date(2006, 6, 15, 0, 0, 0, 0, time.UTC),
+			expectedAge:         18,
+		},
+		{
+			name:                "date of birth and age",
+			body:                `{"first_name":"John","last_name":"Doe","date_of_birth":"2006-06-16","age":30}`,
+			expectedDateOfBirth: time.Date(2006, 6, 16, 0, 0, 0, 0, time.UTC),
+			expectedAge:         17,
+		},
+		{
+			name:        "legacy age",
+			body:        `{"first_name":"John","last_name":"Doe","age":30}`,
+			expectedAge: 30,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := mux.NewRouter()
+			userService := &mockUserApplicationService{
+				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					if !user.DateOfBirth.Equal(test.expectedDateOfBirth) {
+						t.Errorf("expected date of birth %v, got %v", test.expectedDateOfBirth, user.DateOfBirth)
+					}
+					// the service drops the age of users with a date of birth
+					if !user.DateOfBirth.IsZero() {
+						user.Age = 0
+					}
+					return user, nil
+				},
+			}
+			NewHandler(userService, WithClock(func() time.Time { return now })).Save().AddRoute(r)
+
+			req := httptest.NewRequest("POST", "/save", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+			var userDTO UserDTO
+			if err := json.Unmarshal(w.Body.Bytes(), &userDTO); err != nil {
+				t.Fatalf("failed to unmarshal user: %v", err)
+			}
+			if userDTO.Age != test.expectedAge {
+				t.Errorf("expected age %d, got %d", test.expectedAge, userDTO.Age)
+			}
+			if expected := userDomain.FormatDateOfBirth(test.expectedDateOfBirth); userDTO.DateOfBirth != expected {
+				t.Errorf("expected date of birth %q, got %q", expected, userDTO.DateOfBirth)
+			}
+		})
+	}
+}
+
+func TestSave_Profile(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := mux.NewRouter()
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	userService := &mockUserApplicationService{
+		SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+			expected := userDomain.Address{Line1: "1 Main St", City: "Springfield", PostalCode: "12345", Country: "US"}
+			if user.Phone != "+14155550123" || user.Address != expected || user.Locale != "en-US" || user.TimeZone != "America/Chicago" {
+				t.Errorf("unexpected user profile %+v", user)
+			}
+			if !user.CreatedAt.IsZero() {
+				t.Errorf("expected the created time of the request to be ignored, got %v", user.CreatedAt)
+			}
+			user.CreatedAt, user.UpdatedAt = createdAt, createdAt
+			return user, nil
+		},
+	}
+	NewHandler(userService).Save().AddRoute(r)
+
+	body := `{"first_name":"John","last_name":"Doe","phone":"+14155550123","locale":"en-US","time_zone":"America/Chicago",` +
+		`"address":{"line1":"1 Main St","city":"Springfield","postal_code":"12345","country":"US"},"created_at":"2000-01-01T00:00:00Z"}`
+	req := httptest.NewRequest("POST", "/save", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var userDTO UserDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &userDTO); err != nil {
+		t.Fatalf("failed to unmarshal user: %v", err)
+	}
+	if userDTO.Address == nil || userDTO.Address.City != "Springfield" || userDTO.Phone != "+14155550123" {
+		t.Errorf("unexpected user profile %+v", userDTO)
+	}
+	if userDTO.CreatedAt == nil || !userDTO.CreatedAt.Equal(createdAt) || userDTO.UpdatedAt == nil || !userDTO.UpdatedAt.Equal(createdAt) {
+		t.Errorf("unexpected timestamps %v, %v", userDTO.CreatedAt, userDTO.UpdatedAt)
+	}
+}
+
+func TestSave_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedParam  string
+	}{
+		{
+			name:           "missing content type",
+			body:           `{"id":"1"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "malformed JSON",
+			contentType:    "application/json",
+			body:           `{"id":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "type mismatch",
+			contentType:    "application/json",
+			body:           `{"id":"1","age":"twenty"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedParam:  "age",
+		},
+		{
+			name:           "invalid date of birth",
+			contentType:    "application/json",
+			body:           `{"id":"1","date_of_birth":"01/02/2000"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedParam:  "date_of_birth",
+		},
+		{
+			name:           "unknown field",
+			contentType:    "application/json",
+			body:           `{"id":"1","nickname":"JD"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedParam:  "nickname",
+		},
+		{
+			name:           "trailing data",
+			contentType:    "application/json",
+			body:           `{"id":"1"}{"id":"2"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "body too large",
+			contentType:    "application/json",
+			body:           `{"id":"` + strings.Repeat("1", 2<<20) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := mux.NewRouter()
+			userService := &mockUserApplicationService{
+				SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+					t.Errorf("Save() should not be called for an invalid request")
+					return user, nil
+				},
+			}
+			NewHandler(userService).Save().AddRoute(r)
+
+			req := httptest.NewRequest("POST", "/save", strings.NewReader(test.body))
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+			var problem shared.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("failed to unmarshal problem: %v", err)
+			}
+			if problem.Status != test.expectedStatus {
+				t.Errorf("expected problem status %d, got %d", test.expectedStatus, problem.Status)
+			}
+			if test.expectedParam != "" && (len(problem.InvalidParams) != 1 || problem.InvalidParams[0].Name != test.expectedParam) {
+				t.Errorf("expected invalid param %q, got %v", test.expectedParam, problem.InvalidParams)
+			}
+		})
+	}
+}
+
+func TestFind_ContentNegotiation(t *testing.T) {
+	serviceUser := &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "john@example.com", Age: 25}
+	tests := []struct {
+		name                string
+		accept              string
+		expectedStatus      int
+		expectedContentType string
+		decode              func(body []byte, dto *UserDTO) error
+	}{
+		{
+			name:                "msgpack",
+			accept:              "application/msgpack",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/msgpack",
+			decode: func(body []byte, dto *UserDTO) error {
+				return msgpack.Unmarshal(body, dto)
+			},
+		},
+		{
+			name:                "cbor",
+			accept:              "application/cbor",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/cbor",
+			decode: func(body []byte, dto *UserDTO) error {
+				return cbor.Unmarshal(body, dto)
+			},
+		},
+		{
+			name:                "xml",
+			accept:              "application/xml",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/xml",
+			decode: func(body []byte, dto *UserDTO) error {
+				return xml.Unmarshal(body, dto)
+			},
+		},
+		{
+			name:                "not acceptable",
+			accept:              "text/html",
+			expectedStatus:      http.StatusNotAcceptable,
+			expectedContentType: "application/problem+json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := mux.NewRouter()
+			userService := &mockUserApplicationService{
+				FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+					return serviceUser, nil
+				},
+			}
+			NewHandler(userService).Find().AddRoute(r)
+
+			req := httptest.NewRequest("GET", "/find/1", nil)
+			req.Header.Set("Accept", test.accept)
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != test.expectedContentType {
+				t.Errorf("expected Content-Type %s, got %s", test.expectedContentType, got)
+			}
+			if test.decode == nil {
+				return
+			}
+			var userDTO UserDTO
+			if err := test.decode(w.Body.Bytes(), &userDTO); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if userDTO.ID != serviceUser.ID || userDTO.FirstName != serviceUser.FirstName || userDTO.Age != serviceUser.Age {
+				t.Errorf("expected user %v, got %v", serviceUser, userDTO)
+			}
+		})
+	}
+}
+
+func TestSave_XMLRequest(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := mux.NewRouter()
+	userService := &mockUserApplicationService{
+		SaveFunc: func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+			return user, nil
+		},
+	}
+	NewHandler(userService).Save().AddRoute(r)
+
+	body := `<user><id>1</id><first_name>John</first_name><last_name>Doe</last_name><email>john@example.com</email><age>25</age></user>`
+	req := httptest.NewRequest("POST", "/save", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Accept", "application/json")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var userDTO UserDTO
+	json.Unmarshal(w.Body.Bytes(), &userDTO)
+	if userDTO.ID != "1" || userDTO.FirstName != "John" || userDTO.Age != 25 {
+		t.Errorf("expected the XML user to be saved, got %v", userDTO)
+	}
+}
+
+func TestUpdate_LocalizedErrors(t *testing.T) {
+	tests := []struct {
+		name                    string
+		options                 []HandlerOption
+		acceptLanguage          string
+		updateErr               error
+		expectedStatus          int
+		expectedBody            string
+		expectedContentLanguage string
+	}{
+		{
+			name:           "validation errors in the accepted language",
+			acceptLanguage: "de-AT, en;q=0.5",
+			updateErr:      fmt.Errorf("service: %w", errors.Join(userDomain.NewAgeMinimumError(12, 18), userDomain.NewEmailRequiredError())),
+			expectedStatus: http.StatusBadRequest,
+			expectedBody: `"detail":"Der Benutzer erfüllt das Mindestalter von 18 nicht\nDie E-Mail-Adresse des Benutzers ist erforderlich",` +
+				`"instance":"/v1/users/1","invalid_params":[{"name":"age","reason":"Der Benutzer erfüllt das Mindestalter von 18 nicht",` +
+				`"code":"AGE_MINIMUM","params":{"min":"18"},"severity":"error"},{"name":"email",`,
+			expectedContentLanguage: "de",
+		},
+		{
+			name:                    "conflict in the accepted language",
+			acceptLanguage:          "es",
+			updateErr:               fmt.Errorf("service: %w", userDomain.ErrNameCombinationExists),
+			expectedStatus:          http.StatusConflict,
+			expectedBody:            `"detail":"ya existe un usuario con el mismo nombre y apellido"`,
+			expectedContentLanguage: "es",
+		},
+		{
+			name:                    "unsupported language falls back to English",
+			acceptLanguage:          "ja",
+			updateErr:               fmt.Errorf("service: %w", userDomain.NewAgeMinimumError(12, 18)),
+			expectedStatus:          http.StatusBadRequest,
+			expectedBody:            `"detail":"User does not meet minimum age requirement of 18"`,
+			expectedContentLanguage: "en",
+		},
+		{
+			name:                    "rejected values",
+			options:                 []HandlerOption{WithRejectedValues()},
+			updateErr:               fmt.Errorf("service: %w", userDomain.NewEmailFormatError("john")),
+			expectedStatus:          http.StatusBadRequest,
+			expectedBody:            `{"name":"email","reason":"User email must be properly formatted","code":"EMAIL_FORMAT","value":"john","severity":"error"}`,
+			expectedContentLanguage: "en",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			userService := newVersionedUserService()
+			userService.UpdateFunc = func(ctx context.Context, user *userDomain.User) (*userDomain.User, error) {
+				return nil, test.updateErr
+			}
+			NewHandler(userService, test.options...).Patch().AddRoute(r)
+			req := httptest.NewRequest("PATCH", "/v1/users/1", strings.NewReader(`{"age": 12}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", "*")
+			req.Header.Set("Accept-Language", test.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+			if contentLanguage := w.Header().Get("Content-Language"); contentLanguage != test.expectedContentLanguage {
+				t.Errorf("expected Content-Language %q, got %q", test.expectedContentLanguage, contentLanguage)
+			}
+		})
+	}
+}
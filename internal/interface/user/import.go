@@ -0,0 +1,105 @@
+package user
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/i18n"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// maxImportBytes is the largest import body accepted
+const maxImportBytes = 64 << 20
+
+// Import is the api handler for the /v1/users:import route.
+// It accepts text/csv or application/x-ndjson bodies and the dry_run query parameter.
+func (h Handler) Import() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(importRoute).Methods("POST")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			dryRun, err := parseBoolQuery(r, "dry_run")
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			reader, err := newImportReader(r.Header.Get("Content-Type"), http.MaxBytesReader(w, r.Body, maxImportBytes))
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			report, err := h.userService.Import(r.Context(), reader, dryRun)
+			if err != nil {
+				shared.WriteError(w, r, importError(err))
+				return
+			}
+			var reportDTO ImportReportDTO
+			reportDTO.FromReport(report)
+			localizer := i18n.FromRequest(r)
+			reportDTO.Localize(localizer)
+			localizer.WriteHeaders(w.Header())
+			if err := codec.Write(w, responseCodec, http.StatusOK, reportDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+func newImportReader(contentType string, body io.Reader) (userApplication.ImportRowReader, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return bulk.NewCSVReader(body), nil
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return bulk.NewNDJSONReader(body), nil
+	default:
+		return nil, &shared.ProblemError{Problem: shared.NewProblem(http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Content-Type %q is not supported, use text/csv or application/x-ndjson", contentType))}
+	}
+}
+
+// importError reports an import body or NDJSON line over its limit as too large
+func importError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytesErr):
+		return &shared.ProblemError{Problem: shared.NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("import body must not be larger than %d bytes", maxBytesErr.Limit)), Err: err}
+	case errors.Is(err, bufio.ErrTooLong):
+		return &shared.ProblemError{Problem: shared.NewProblem(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("import lines must not be longer than %d bytes", bulk.MaxNDJSONLineBytes)), Err: err}
+	default:
+		return err
+	}
+}
+
+func parseBoolQuery(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		problem := shared.NewProblem(http.StatusBadRequest, "request contains an invalid query parameter")
+		problem.InvalidParams = []shared.InvalidParam{{Name: name, Reason: "must be a boolean"}}
+		return false, &shared.ProblemError{Problem: problem, Err: err}
+	}
+	return b, nil
+}
@@ -0,0 +1,118 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	userApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/bulk"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		target         string
+		body           string
+		expectedStatus int
+		expectedDryRun bool
+		expectedRows   int
+	}{
+		{
+			name:           "csv import",
+			contentType:    "text/csv",
+			target:         "/v1/users:import",
+			expectedStatus: http.StatusOK,
+			expectedRows:   2,
+		},
+		{
+			name:           "ndjson dry run",
+			contentType:    "application/x-ndjson",
+			target:         "/v1/users:import?dry_run=true",
+			expectedStatus: http.StatusOK,
+			expectedDryRun: true,
+			expectedRows:   2,
+		},
+		{
+			name:           "unsupported content type",
+			contentType:    "application/json",
+			target:         "/v1/users:import",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:           "invalid dry run",
+			contentType:    "text/csv",
+			target:         "/v1/users:import?dry_run=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "ndjson line too long",
+			contentType:    "application/x-ndjson",
+			target:         "/v1/users:import",
+			body:           `{"id":"1","first_name":"` + strings.Repeat("a", bulk.MaxNDJSONLineBytes) + `"}` + "\n",
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	bodies := map[string]string{
+		"text/csv":             "id,first_name,last_name,email,age\n1,John,Doe,john@example.com,25\n2,Jane,Doe,jane@example.com,30\n",
+		"application/x-ndjson": `{"id":"1","first_name":"John"}` + "\n" + `{"id":"2","first_name":"Jane"}` + "\n",
+		"application/json":     `{}`,
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := mux.NewRouter()
+			userService := &mockUserApplicationService{
+				ImportFunc: func(ctx context.Context, reader userApplication.ImportRowReader, dryRun bool) (*userApplication.ImportReport, error) {
+					report := &userApplication.ImportReport{DryRun: dryRun}
+					for {
+						row, err := reader.Read()
+						if errors.Is(err, io.EOF) {
+							return report, nil
+						}
+						if err != nil {
+							return nil, err
+						}
+						report.Created++
+						report.Rows = append(report.Rows, userApplication.ImportRowResult{Line: row.Line, ID: row.User.ID, Status: userApplication.ImportStatusCreated})
+					}
+				},
+			}
+			NewHandler(userService).Import().AddRoute(r)
+
+			body := test.body
+			if body == "" {
+				body = bodies[test.contentType]
+			}
+			req := httptest.NewRequest("POST", test.target, strings.NewReader(body))
+			req.Header.Set("Content-Type", test.contentType)
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d", test.expectedStatus, w.Code)
+			}
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+			var report ImportReportDTO
+			if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to unmarshal report: %v", err)
+			}
+			if report.DryRun != test.expectedDryRun {
+				t.Errorf("expected dry run %v, got %v", test.expectedDryRun, report.DryRun)
+			}
+			if len(report.Rows) != test.expectedRows || report.Created != test.expectedRows {
+				t.Errorf("expected %d created rows, got %v", test.expectedRows, report)
+			}
+		})
+	}
+}
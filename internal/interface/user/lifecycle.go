@@ -0,0 +1,96 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// Verify is the api handler for the POST /v1/users/{id}:verify route, it changes a pending user to verified
+func (h Handler) Verify() shared.Handler {
+	return h.transition("verify", userDomain.StatusVerified)
+}
+
+// Activate is the api handler for the POST /v1/users/{id}:activate route,
+// it changes a verified or suspended user to active
+func (h Handler) Activate() shared.Handler {
+	return h.transition("activate", userDomain.StatusActive)
+}
+
+// Suspend is the api handler for the POST /v1/users/{id}:suspend route, it changes an active user to suspended
+func (h Handler) Suspend() shared.Handler {
+	return h.transition("suspend", userDomain.StatusSuspended)
+}
+
+// Offboard is the api handler for the POST /v1/users/{id}:offboard route, it changes any user but an offboarded one
+// to offboarded
+func (h Handler) Offboard() shared.Handler {
+	return h.transition("offboard", userDomain.StatusOffboarded)
+}
+
+// transition handles the command changing the status of a user to the target status.
+// The body is optional and may give the reason of the transition.
+func (h Handler) transition(command string, target userDomain.Status) shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(userRoute + ":" + command).Methods("POST")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			var request TransitionRequestDTO
+			if r.ContentLength != 0 || r.Header.Get("Content-Type") != "" {
+				if err := h.codecs.Decode(w, r, &request); err != nil {
+					shared.WriteError(w, r, err)
+					return
+				}
+			}
+			user, err := h.userService.Transition(r.Context(), mux.Vars(r)["id"], target, request.Reason)
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+			var userResponse UserDTO
+			now := h.now()
+			userResponse.FromEntity(user, now)
+			writeValidators(w, user, responseCodec, now)
+			if err := codec.Write(w, responseCodec, http.StatusOK, userResponse); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// StatusHistory is the api handler for the GET /v1/users/{id}/status-history route
+func (h Handler) StatusHistory() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(statusHistoryRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			user, err := h.userService.Find(r.Context(), mux.Vars(r)["id"])
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+			var historyDTO StatusHistoryDTO
+			historyDTO.FromEntity(user)
+			if err := codec.Write(w, responseCodec, http.StatusOK, historyDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
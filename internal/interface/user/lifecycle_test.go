@@ -0,0 +1,127 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestTransition(t *testing.T) {
+	tests := []struct {
+		name           string
+		target         string
+		body           string
+		transitionErr  error
+		expectedStatus userDomain.Status
+		expectedReason string
+		expectedCode   int
+		expectedBody   string
+	}{
+		{
+			name:           "verify with a reason",
+			target:         "/v1/users/1:verify",
+			body:           `{"reason": "documents checked"}`,
+			expectedStatus: userDomain.StatusVerified,
+			expectedReason: "documents checked",
+			expectedCode:   http.StatusOK,
+			expectedBody:   `"status":"verified"`,
+		},
+		{
+			name:           "suspend without a body",
+			target:         "/v1/users/1:suspend",
+			expectedStatus: userDomain.StatusSuspended,
+			expectedCode:   http.StatusOK,
+			expectedBody:   `"status":"suspended"`,
+		},
+		{
+			name:           "invalid transition",
+			target:         "/v1/users/1:activate",
+			transitionErr:  fmt.Errorf("service: %w", &userDomain.TransitionError{From: userDomain.StatusPending, To: userDomain.StatusActive}),
+			expectedStatus: userDomain.StatusActive,
+			expectedCode:   http.StatusConflict,
+			expectedBody:   `"detail":"the user status cannot change from pending to active"`,
+		},
+		{
+			name:           "user not found",
+			target:         "/v1/users/1:offboard",
+			transitionErr:  fmt.Errorf("service: %w", userDomain.ErrNotFound),
+			expectedStatus: userDomain.StatusOffboarded,
+			expectedCode:   http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			handler := NewHandler(&mockUserApplicationService{
+				TransitionFunc: func(ctx context.Context, id string, target userDomain.Status, reason string) (*userDomain.User, error) {
+					if id != "1" || target != test.expectedStatus || reason != test.expectedReason {
+						t.Errorf("Transition() called with %q, %q, %q, want 1, %q, %q", id, target, reason, test.expectedStatus, test.expectedReason)
+					}
+					if test.transitionErr != nil {
+						return nil, test.transitionErr
+					}
+					return &userDomain.User{ID: id, FirstName: "John", LastName: "Doe", Status: target, Version: 2}, nil
+				},
+			})
+			handler.Verify().AddRoute(r)
+			handler.Activate().AddRoute(r)
+			handler.Suspend().AddRoute(r)
+			handler.Offboard().AddRoute(r)
+			req := httptest.NewRequest("POST", test.target, strings.NewReader(test.body))
+			if test.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedCode {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedCode, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+			if test.expectedCode == http.StatusOK && w.Header().Get("ETag") == "" {
+				t.Error("expected an ETag header")
+			}
+		})
+	}
+}
+
+func TestStatusHistory(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r := mux.NewRouter()
+	NewHandler(&mockUserApplicationService{
+		FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			return &userDomain.User{ID: id, Status: userDomain.StatusVerified, StatusHistory: []userDomain.StatusTransition{
+				{To: userDomain.StatusPending, At: at, Actor: "admin"},
+				{From: userDomain.StatusPending, To: userDomain.StatusVerified, At: at.Add(time.Hour), Actor: "admin", Reason: "documents checked"},
+			}}, nil
+		},
+	}).StatusHistory().AddRoute(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users/1/status-history", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	var history StatusHistoryDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &history); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+	if history.UserID != "1" || history.Status != "verified" || len(history.Transitions) != 2 {
+		t.Fatalf("unexpected status history %+v", history)
+	}
+	expected := StatusTransitionDTO{From: "pending", To: "verified", At: at.Add(time.Hour), Actor: "admin", Reason: "documents checked"}
+	if history.Transitions[1] != expected {
+		t.Errorf("expected transition %+v, got %+v", expected, history.Transitions[1])
+	}
+}
@@ -0,0 +1,110 @@
+package user
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// List is the api handler for the GET /v1/users route, it lists the users matching the filters of the query by id
+// a page at a time. The next_page_token of a page is the page_token of the next one.
+func (h Handler) List() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(usersRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			filter, err := listFilterFromQuery(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			pageSize, afterID, err := pageFromQuery(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			// fetch one extra user to know whether there is a next page
+			filter.AfterID, filter.Limit = afterID, pageSize+1
+			users, err := h.userService.List(r.Context(), filter)
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+
+			listDTO := UserListDTO{Users: make([]UserDTO, 0, len(users))}
+			if len(users) > pageSize {
+				users = users[:pageSize]
+				listDTO.NextPageToken = base64.RawURLEncoding.EncodeToString([]byte(users[pageSize-1].ID))
+			}
+			now := h.now()
+			for _, user := range users {
+				var userDTO UserDTO
+				userDTO.FromEntity(user, now)
+				listDTO.Users = append(listDTO.Users, userDTO)
+			}
+			if err := codec.Write(w, responseCodec, http.StatusOK, listDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// pageFromQuery reads the page size and the id the page starts after from the page_size and page_token parameters
+func pageFromQuery(r *http.Request) (int, string, error) {
+	query := r.URL.Query()
+	pageSize := defaultPageSize
+	if value := query.Get("page_size"); value != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(value); err != nil || pageSize < 1 {
+			return 0, "", invalidQueryParam("page_size", "must be a positive integer")
+		}
+		pageSize = min(pageSize, maxPageSize)
+	}
+	afterID, err := base64.RawURLEncoding.DecodeString(query.Get("page_token"))
+	if err != nil {
+		return 0, "", invalidQueryParam("page_token", "must be the next_page_token of the previous page")
+	}
+	return pageSize, string(afterID), nil
+}
+
+// invalidQueryParam returns a bad request problem for an invalid query parameter
+func invalidQueryParam(name string, reason string) error {
+	problem := shared.NewProblem(http.StatusBadRequest, "request contains an invalid query parameter")
+	problem.InvalidParams = []shared.InvalidParam{{Name: name, Reason: reason}}
+	return &shared.ProblemError{Problem: problem}
+}
+
+// listFilterFromQuery reads the list filters from the query parameters, an unknown status is a bad request
+func listFilterFromQuery(r *http.Request) (userDomain.ListFilter, error) {
+	query := r.URL.Query()
+	filter := userDomain.ListFilter{
+		FirstName: query.Get("first_name"),
+		LastName:  query.Get("last_name"),
+		Email:     query.Get("email"),
+	}
+	if value := query.Get("status"); value != "" {
+		status, err := userDomain.ParseStatus(value)
+		if err != nil {
+			return userDomain.ListFilter{}, invalidQueryParam("status", "must be one of pending, verified, active, suspended or offboarded")
+		}
+		filter.Status = status
+	}
+	return filter, nil
+}
@@ -0,0 +1,87 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestList_Pagination(t *testing.T) {
+	users := []*userDomain.User{
+		{ID: "1", Status: userDomain.StatusActive},
+		{ID: "2", Status: userDomain.StatusPending},
+		{ID: "3", Status: userDomain.StatusActive},
+		{ID: "4", Status: userDomain.StatusActive},
+	}
+	r := mux.NewRouter()
+	NewHandler(&mockUserApplicationService{
+		ListFunc: func(ctx context.Context, filter userDomain.ListFilter) ([]*userDomain.User, error) {
+			var page []*userDomain.User
+			for _, user := range users {
+				if filter.Matches(user) && user.ID > filter.AfterID && len(page) < filter.Limit {
+					page = append(page, user)
+				}
+			}
+			return page, nil
+		},
+	}).List().AddRoute(r)
+
+	var ids []string
+	pageToken := ""
+	for pages := 0; pages < 5; pages++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users?status=active&page_size=2&page_token="+pageToken, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status code %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var page UserListDTO
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to decode the response: %v", err)
+		}
+		for _, user := range page.Users {
+			ids = append(ids, user.ID+":"+user.Status)
+		}
+		if pageToken = page.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	if expected := "1:active 3:active 4:active"; strings.Join(ids, " ") != expected {
+		t.Errorf("expected users %s, got %v", expected, ids)
+	}
+}
+
+func TestList_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		expectedBody string
+	}{
+		{name: "unknown status", query: "?status=deleted", expectedBody: `"name":"status"`},
+		{name: "page size not a number", query: "?page_size=ten", expectedBody: `"name":"page_size"`},
+		{name: "page size zero", query: "?page_size=0", expectedBody: `"name":"page_size"`},
+		{name: "page token not encoded", query: "?page_token=***", expectedBody: `"name":"page_token"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{}).List().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users"+test.query, nil))
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("expected status code %d, got %d", http.StatusBadRequest, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
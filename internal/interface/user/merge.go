@@ -0,0 +1,48 @@
+package user
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// Merge is the api handler for the POST /v1/users:merge route.
+// It merges the duplicate users into the survivor, picking each field by its rule, and returns the merged survivor
+// with the warnings of its checks. The merged users are gone afterwards and reads of them redirect to the survivor.
+func (h Handler) Merge() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(mergeRoute).Methods("POST")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			var request MergeRequestDTO
+			if err := h.codecs.Decode(w, r, &request); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			ctx := domainShared.WithWarnings(r.Context())
+			user, err := h.userService.Merge(ctx, request.ToEntity())
+			if err != nil {
+				h.writeServiceError(w, r, err)
+				return
+			}
+			var userResponse UserDTO
+			now := h.now()
+			userResponse.FromEntity(user, now)
+			userResponse.Warnings = h.warnings(w, r, domainShared.WarningsFromContext(ctx))
+			writeValidators(w, user, responseCodec, now)
+			if err := codec.Write(w, responseCodec, http.StatusOK, userResponse); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
@@ -0,0 +1,133 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name           string
+		mergeFunc      func(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error)
+		expectedStatus int
+		expectedParam  string
+	}{
+		{
+			name: "merged",
+			mergeFunc: func(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error) {
+				expected := userDomain.MergeRequest{SurvivorID: "1", DuplicateIDs: []string{"2", "3"},
+					Rules: map[string]string{userDomain.FieldEmail: "2", userDomain.FieldPhone: userDomain.MergeRuleNewest}}
+				if !reflect.DeepEqual(request, expected) {
+					return nil, fmt.Errorf("unexpected request %+v", request)
+				}
+				return &userDomain.User{ID: "1", FirstName: "John", LastName: "Doe", Email: "jon@example.com", Version: 5}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "invalid request",
+			mergeFunc: func(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error) {
+				return nil, fmt.Errorf("service: %w", &userDomain.MergeError{Param: "fields.email", Reason: "must be survivor"})
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedParam:  "fields.email",
+		},
+		{
+			name: "duplicate not found",
+			mergeFunc: func(ctx context.Context, request userDomain.MergeRequest) (*userDomain.User, error) {
+				return nil, fmt.Errorf("service: %w", userDomain.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{MergeFunc: test.mergeFunc}).Merge().AddRoute(r)
+			body := `{"survivor_id":"1","duplicate_ids":["2","3"],"fields":{"email":"2","phone":"newest"}}`
+			req := httptest.NewRequest("POST", "/v1/users:merge", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			switch test.expectedStatus {
+			case http.StatusOK:
+				var userDTO UserDTO
+				if err := json.NewDecoder(w.Body).Decode(&userDTO); err != nil || userDTO.ID != "1" || userDTO.Email != "jon@example.com" {
+					t.Errorf("expected the merged user 1, got %+v, %v", userDTO, err)
+				}
+				if etag := w.Header().Get("ETag"); etag != `"5-json"` {
+					t.Errorf("expected ETag \"5-json\", got %s", etag)
+				}
+			case http.StatusBadRequest:
+				var problem shared.Problem
+				if err := json.NewDecoder(w.Body).Decode(&problem); err != nil || len(problem.InvalidParams) != 1 || problem.InvalidParams[0].Name != test.expectedParam {
+					t.Errorf("expected an invalid %s, got %+v, %v", test.expectedParam, problem, err)
+				}
+			}
+		})
+	}
+}
+
+func TestMergedUser(t *testing.T) {
+	mergedErr := fmt.Errorf("service: %w", &userDomain.MergedError{ID: "2", MergedInto: "1"})
+	service := &mockUserApplicationService{
+		FindFunc: func(ctx context.Context, id string) (*userDomain.User, error) {
+			return nil, mergedErr
+		},
+		DuplicatesFunc: func(ctx context.Context, id string) ([]userDomain.DuplicateCandidate, error) {
+			return nil, mergedErr
+		},
+		DeleteFunc: func(ctx context.Context, id string) error {
+			return mergedErr
+		},
+	}
+	tests := []struct {
+		name             string
+		method           string
+		target           string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{name: "find redirects", method: "GET", target: "/find/2", expectedStatus: http.StatusMovedPermanently, expectedLocation: "/find/1"},
+		{name: "reads redirect with their query", method: "GET", target: "/v1/users/2/duplicates?x=y", expectedStatus: http.StatusMovedPermanently,
+			expectedLocation: "/v1/users/1/duplicates?x=y"},
+		{name: "writes are not found", method: "DELETE", target: "/v1/users/2", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			handler := NewHandler(service)
+			handler.Find().AddRoute(r)
+			handler.Duplicates().AddRoute(r)
+			handler.Delete().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(test.method, test.target, nil))
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if location := w.Header().Get("Location"); location != test.expectedLocation {
+				t.Errorf("expected Location %q, got %q", test.expectedLocation, location)
+			}
+			if test.expectedStatus == http.StatusNotFound && !strings.Contains(w.Body.String(), "the user was merged into the user 1") {
+				t.Errorf("expected the survivor in the detail, got %s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package user
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/i18n"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+// validatedFields are the fields the field query parameter of /v1/users:validate accepts
+var validatedFields = []string{userDomain.FieldFirstName, userDomain.FieldLastName, userDomain.FieldEmail, userDomain.FieldAge, userDomain.FieldDateOfBirth,
+	userDomain.FieldPhone, userDomain.FieldAddress, userDomain.FieldLocale, userDomain.FieldTimeZone}
+
+// Validate is the api handler for the /v1/users:validate route.
+// It checks the user like a save without saving it and answers 200 OK with every failure, valid or not.
+// A user with warnings only, such as possible duplicates in advisory mode, is valid.
+// The field query parameter restricts the failures to a single field for inline form checks.
+func (h Handler) Validate() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(validateRoute).Methods("POST")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			field := r.URL.Query().Get("field")
+			if field != "" && !slices.Contains(validatedFields, field) {
+				problem := shared.NewProblem(http.StatusBadRequest, "request contains an invalid query parameter")
+				problem.InvalidParams = []shared.InvalidParam{{Name: "field", Reason: "must be one of " + strings.Join(validatedFields, ", ")}}
+				shared.WriteProblem(w, r, problem)
+				return
+			}
+			var userRequest UserDTO
+			if err := h.codecs.Decode(w, r, &userRequest); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			userToValidate, err := userRequest.ToEntity()
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			failures := h.userService.Validate(r.Context(), userToValidate, field)
+			localizer := i18n.FromRequest(r)
+			// warnings do not make the user invalid
+			isError := func(failure domainShared.ValidationError) bool {
+				return failure.Severity != domainShared.SeverityWarning
+			}
+			reportDTO := ValidationReportDTO{Valid: !slices.ContainsFunc(failures, isError), Errors: h.validationErrorDTOs(localizer, failures)}
+			localizer.WriteHeaders(w.Header())
+			if err := codec.Write(w, responseCodec, http.StatusOK, reportDTO); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// validationErrorDTOs converts validation failures to DTOs with localized messages
+func (h Handler) validationErrorDTOs(localizer i18n.Localizer, failures []domainShared.ValidationError) []ValidationErrorDTO {
+	failureDTOs := make([]ValidationErrorDTO, 0, len(failures))
+	for _, failure := range failures {
+		if !h.rejectedValues {
+			failure = failure.Redacted()
+		}
+		failureDTOs = append(failureDTOs, ValidationErrorDTO{
+			Field:    failure.Field,
+			Code:     failure.Code,
+			Message:  localizer.ValidationError(failure),
+			Value:    failure.Value,
+			Params:   failure.Params,
+			Severity: string(failure.Severity),
+		})
+	}
+	return failureDTOs
+}
+
+// warnings converts the warnings of a request to DTOs in the language of the Accept-Language header, nil when there are none
+func (h Handler) warnings(w http.ResponseWriter, r *http.Request, warnings []domainShared.ValidationError) []ValidationErrorDTO {
+	if len(warnings) == 0 {
+		return nil
+	}
+	localizer := i18n.FromRequest(r)
+	localizer.WriteHeaders(w.Header())
+	return h.validationErrorDTOs(localizer, warnings)
+}
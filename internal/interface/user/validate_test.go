@@ -0,0 +1,102 @@
+package user
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		acceptLanguage string
+		failures       []domainShared.ValidationError
+		expectedField  string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "valid user",
+			body:           `{"first_name": "Jane", "last_name": "Doe", "email": "jane@example.com", "age": 30}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"valid":true,"errors":[]}`,
+		},
+		{
+			name:           "every failure",
+			body:           `{"first_name": "Jane", "email": "jane", "age": 12}`,
+			acceptLanguage: "fr",
+			failures: []domainShared.ValidationError{
+				userDomain.NewAgeMinimumError(12, 18),
+				userDomain.NewEmailFormatError("jane"),
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"valid":false,"errors":[` +
+				`{"field":"age","code":"AGE_MINIMUM","message":"L'utilisateur n'a pas l'âge minimum requis de 18 ans","params":{"min":"18"},"severity":"error"},` +
+				`{"field":"email","code":"EMAIL_FORMAT","message":"L'e-mail de l'utilisateur doit être correctement formaté","severity":"error"}]}`,
+		},
+		{
+			name: "warnings only",
+			body: `{"first_name": "Jon", "last_name": "Doe", "email": "jon@example.com", "age": 30}`,
+			failures: []domainShared.ValidationError{userDomain.NewPossibleDuplicateWarning(
+				userDomain.DuplicateCandidate{User: &userDomain.User{ID: "2"}, Score: 0.97})},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"valid":true,"errors":[{"code":"POSSIBLE_DUPLICATE","message":"User is a possible duplicate of user 2",` +
+				`"params":{"score":"0.97","user_id":"2"},"severity":"warning"}]}`,
+		},
+		{
+			name:           "single field",
+			query:          "?field=email",
+			body:           `{"email": "jane"}`,
+			failures:       []domainShared.ValidationError{userDomain.NewEmailFormatError("jane")},
+			expectedField:  userDomain.FieldEmail,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"code":"EMAIL_FORMAT"`,
+		},
+		{
+			name:           "unknown field",
+			query:          "?field=nickname",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"name":"field"`,
+		},
+		{
+			name:           "unknown body field",
+			body:           `{"nickname": "Janie"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockUserApplicationService{
+				ValidateFunc: func(ctx context.Context, user *userDomain.User, field string) []domainShared.ValidationError {
+					if field != test.expectedField {
+						t.Errorf("Validate() field = %q, want %q", field, test.expectedField)
+					}
+					return test.failures
+				},
+			}).Validate().AddRoute(r)
+			req := httptest.NewRequest("POST", "/v1/users:validate"+test.query, strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Accept-Language", test.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
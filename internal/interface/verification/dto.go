@@ -0,0 +1,30 @@
+package verification
+
+import (
+	"encoding/xml"
+	"time"
+
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+// VerificationSentDTO is the response of a sent verification
+type VerificationSentDTO struct {
+	XMLName   xml.Name  `json:"-" msgpack:"-" cbor:"-" xml:"verification"`
+	UserID    string    `json:"user_id" msgpack:"user_id" cbor:"user_id" xml:"user_id"`
+	ExpiresAt time.Time `json:"expires_at" msgpack:"expires_at" cbor:"expires_at" xml:"expires_at"`
+}
+
+// EmailVerificationDTO is the response of a verified email
+type EmailVerificationDTO struct {
+	XMLName    xml.Name  `json:"-" msgpack:"-" cbor:"-" xml:"email_verification"`
+	UserID     string    `json:"user_id" msgpack:"user_id" cbor:"user_id" xml:"user_id"`
+	Email      string    `json:"email" msgpack:"email" cbor:"email" xml:"email"`
+	VerifiedAt time.Time `json:"verified_at" msgpack:"verified_at" cbor:"verified_at" xml:"verified_at"`
+}
+
+// FromEntity converts the verified email of a userDomain.User to an EmailVerificationDTO
+func (e *EmailVerificationDTO) FromEntity(user *userDomain.User) {
+	e.UserID = user.ID
+	e.Email = user.Email
+	e.VerifiedAt = user.EmailVerifiedAt
+}
@@ -0,0 +1,133 @@
+// Package verification contains the api layer verifying that users own their email.
+package verification
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	verificationApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/verification"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/i18n"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+const (
+	sendVerificationRoute = "/v1/users/{id}:send-verification"
+	// verifyRoute is the endpoint linked in the verification emails
+	verifyRoute = "/verify"
+)
+
+// Message keys of the problems of the verification api
+const (
+	messageUserNotFound         = "USER_NOT_FOUND"
+	messageEmailMissing         = "EMAIL_MISSING"
+	messageEmailAlreadyVerified = "EMAIL_ALREADY_VERIFIED"
+	messageEmailChanged         = "EMAIL_CHANGED"
+	messageTokenInvalid         = "VERIFICATION_TOKEN_INVALID"
+	messageTokenExpired         = "VERIFICATION_TOKEN_EXPIRED"
+)
+
+type verificationApplicationService interface {
+	// Send emails a verification link to a user and returns when it expires
+	Send(ctx context.Context, id string) (time.Time, error)
+	// Verify verifies the email a token was sent to
+	Verify(ctx context.Context, token string) (*userDomain.User, error)
+}
+
+// Handler is a handler for the email verification
+type Handler struct {
+	verificationService verificationApplicationService
+	codecs              *codec.Registry
+}
+
+// NewHandler creates a new handler for the email verification
+func NewHandler(verificationService verificationApplicationService) *Handler {
+	return &Handler{
+		verificationService: verificationService,
+		codecs:              codec.NewDefaultRegistry(),
+	}
+}
+
+// SendVerification is the api handler for the POST /v1/users/{id}:send-verification route,
+// it emails a link verifying the current email of the user
+func (h Handler) SendVerification() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(sendVerificationRoute).Methods("POST")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			id := mux.Vars(r)["id"]
+			expiresAt, err := h.verificationService.Send(r.Context(), id)
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			response := VerificationSentDTO{UserID: id, ExpiresAt: expiresAt}
+			if err := codec.Write(w, responseCodec, http.StatusAccepted, response); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// Verify is the api handler for the GET /verify?token= route opened from the verification emails
+func (h Handler) Verify() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(verifyRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			user, err := h.verificationService.Verify(r.Context(), r.URL.Query().Get("token"))
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			var response EmailVerificationDTO
+			response.FromEntity(user)
+			if err := codec.Write(w, responseCodec, http.StatusOK, response); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// writeServiceError writes a service error as a problem response in the language of the Accept-Language header,
+// reporting unusable tokens as bad requests, expired ones as gone and emails that cannot be verified as conflicts
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	localizer := i18n.FromRequest(r)
+	var status int
+	var detail string
+	switch {
+	case errors.Is(err, userDomain.ErrNotFound):
+		status, detail = http.StatusNotFound, localizer.Message(messageUserNotFound, nil, "user not found")
+	case errors.Is(err, verificationApplication.ErrInvalidToken):
+		status, detail = http.StatusBadRequest, localizer.Message(messageTokenInvalid, nil, "the verification link is invalid")
+	case errors.Is(err, verificationApplication.ErrTokenExpired):
+		status, detail = http.StatusGone, localizer.Message(messageTokenExpired, nil, "the verification link expired, request a new one")
+	case errors.Is(err, userDomain.ErrEmailMissing):
+		status, detail = http.StatusConflict, localizer.Message(messageEmailMissing, nil, userDomain.ErrEmailMissing.Error())
+	case errors.Is(err, userDomain.ErrEmailAlreadyVerified):
+		status, detail = http.StatusConflict, localizer.Message(messageEmailAlreadyVerified, nil, userDomain.ErrEmailAlreadyVerified.Error())
+	case errors.Is(err, userDomain.ErrEmailChanged):
+		status, detail = http.StatusConflict, localizer.Message(messageEmailChanged, nil, userDomain.ErrEmailChanged.Error())
+	}
+	if status != 0 {
+		localizer.WriteHeaders(w.Header())
+		err = &shared.ProblemError{Problem: shared.NewProblem(status, detail), Err: err}
+	}
+	shared.WriteError(w, r, err)
+}
@@ -0,0 +1,116 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	verificationApplication "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/application/verification"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+)
+
+type mockVerificationApplicationService struct {
+	SendFunc   func(ctx context.Context, id string) (time.Time, error)
+	VerifyFunc func(ctx context.Context, token string) (*userDomain.User, error)
+}
+
+func (m *mockVerificationApplicationService) Send(ctx context.Context, id string) (time.Time, error) {
+	return m.SendFunc(ctx, id)
+}
+
+func (m *mockVerificationApplicationService) Verify(ctx context.Context, token string) (*userDomain.User, error) {
+	return m.VerifyFunc(ctx, token)
+}
+
+func TestSendVerification(t *testing.T) {
+	expiresAt := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		sendErr        error
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "sent", expectedStatus: http.StatusAccepted, expectedBody: `{"user_id":"1","expires_at":"2024-05-02T12:00:00Z"}`},
+		{name: "user not found", sendErr: userDomain.ErrNotFound, expectedStatus: http.StatusNotFound},
+		{name: "already verified", sendErr: fmt.Errorf("verification: %w", userDomain.ErrEmailAlreadyVerified),
+			expectedStatus: http.StatusConflict, expectedBody: "already verified"},
+		{name: "mailer failure", sendErr: errors.New("mail: failed to connect"), expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockVerificationApplicationService{
+				SendFunc: func(ctx context.Context, id string) (time.Time, error) {
+					if id != "1" {
+						t.Errorf("Send() id = %q, want 1", id)
+					}
+					return expiresAt, test.sendErr
+				},
+			}).SendVerification().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("POST", "/v1/users/1:send-verification", nil))
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	verifiedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		verifyErr      error
+		acceptLanguage string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "verified", expectedStatus: http.StatusOK,
+			expectedBody: `{"user_id":"1","email":"jane@example.com","verified_at":"2024-05-01T12:00:00Z"}`},
+		{name: "invalid token", verifyErr: fmt.Errorf("verification: %w", verificationApplication.ErrInvalidToken),
+			expectedStatus: http.StatusBadRequest, expectedBody: "the verification link is invalid"},
+		{name: "expired token", verifyErr: fmt.Errorf("verification: %w", verificationApplication.ErrTokenExpired),
+			acceptLanguage: "de", expectedStatus: http.StatusGone, expectedBody: "der Bestätigungslink ist abgelaufen"},
+		{name: "email changed", verifyErr: fmt.Errorf("verification: %w", userDomain.ErrEmailChanged),
+			expectedStatus: http.StatusConflict, expectedBody: "changed since the verification was sent"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockVerificationApplicationService{
+				VerifyFunc: func(ctx context.Context, token string) (*userDomain.User, error) {
+					if token != "abc.def" {
+						t.Errorf("Verify() token = %q, want abc.def", token)
+					}
+					if test.verifyErr != nil {
+						return nil, test.verifyErr
+					}
+					return &userDomain.User{ID: "1", Email: "jane@example.com", EmailVerifiedAt: verifiedAt}, nil
+				},
+			}).Verify().AddRoute(r)
+			req := httptest.NewRequest("GET", "/verify?token=abc.def", nil)
+			req.Header.Set("Accept-Language", test.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
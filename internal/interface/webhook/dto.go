@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"encoding/xml"
+	"time"
+
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	webhookDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+// SubscriptionRequestDTO is the request to create a subscription
+type SubscriptionRequestDTO struct {
+	XMLName xml.Name `json:"-" msgpack:"-" cbor:"-" xml:"subscription"`
+	URL     string   `json:"url" msgpack:"url" cbor:"url" xml:"url"`
+	Events  []string `json:"events" msgpack:"events" cbor:"events" xml:"events>event"`
+	Secret  string   `json:"secret" msgpack:"secret" cbor:"secret" xml:"secret"`
+}
+
+// ToEntity converts a SubscriptionRequestDTO to a webhookDomain.Subscription
+func (s *SubscriptionRequestDTO) ToEntity() *webhookDomain.Subscription {
+	subscription := &webhookDomain.Subscription{URL: s.URL, Secret: s.Secret}
+	for _, eventType := range s.Events {
+		subscription.Events = append(subscription.Events, userDomain.EventType(eventType))
+	}
+	return subscription
+}
+
+// SubscriptionDTO is a subscription, the secret is only returned when the subscription is created
+type SubscriptionDTO struct {
+	XMLName   xml.Name  `json:"-" msgpack:"-" cbor:"-" xml:"subscription"`
+	ID        string    `json:"id" msgpack:"id" cbor:"id" xml:"id"`
+	URL       string    `json:"url" msgpack:"url" cbor:"url" xml:"url"`
+	Events    []string  `json:"events" msgpack:"events" cbor:"events" xml:"events>event"`
+	Secret    string    `json:"secret,omitempty" msgpack:"secret,omitempty" cbor:"secret,omitempty" xml:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at" msgpack:"created_at" cbor:"created_at" xml:"created_at"`
+}
+
+// FromEntity converts a webhookDomain.Subscription to a SubscriptionDTO without its secret
+func (s *SubscriptionDTO) FromEntity(subscription *webhookDomain.Subscription) {
+	s.ID = subscription.ID
+	s.URL = subscription.URL
+	s.CreatedAt = subscription.CreatedAt
+	s.Events = make([]string, 0, len(subscription.Events))
+	for _, eventType := range subscription.Events {
+		s.Events = append(s.Events, string(eventType))
+	}
+}
+
+// SubscriptionListDTO is a list of subscriptions
+type SubscriptionListDTO struct {
+	XMLName       xml.Name          `json:"-" msgpack:"-" cbor:"-" xml:"subscriptions"`
+	Subscriptions []SubscriptionDTO `json:"subscriptions" msgpack:"subscriptions" cbor:"subscriptions" xml:"subscription"`
+}
+
+// DeliveryListDTO is the delivery log of a subscription
+type DeliveryListDTO struct {
+	XMLName    xml.Name      `json:"-" msgpack:"-" cbor:"-" xml:"deliveries"`
+	Deliveries []DeliveryDTO `json:"deliveries" msgpack:"deliveries" cbor:"deliveries" xml:"delivery"`
+}
+
+// DeliveryDTO is a delivery and its attempts
+type DeliveryDTO struct {
+	ID            string       `json:"id" msgpack:"id" cbor:"id" xml:"id"`
+	EventID       string       `json:"event_id" msgpack:"event_id" cbor:"event_id" xml:"event_id"`
+	EventType     string       `json:"event_type" msgpack:"event_type" cbor:"event_type" xml:"event_type"`
+	Status        string       `json:"status" msgpack:"status" cbor:"status" xml:"status"`
+	NextAttemptAt *time.Time   `json:"next_attempt_at,omitempty" msgpack:"next_attempt_at,omitempty" cbor:"next_attempt_at,omitempty" xml:"next_attempt_at,omitempty"`
+	CreatedAt     time.Time    `json:"created_at" msgpack:"created_at" cbor:"created_at" xml:"created_at"`
+	Attempts      []AttemptDTO `json:"attempts" msgpack:"attempts" cbor:"attempts" xml:"attempts>attempt"`
+}
+
+// AttemptDTO is a single delivery attempt
+type AttemptDTO struct {
+	At         time.Time `json:"at" msgpack:"at" cbor:"at" xml:"at"`
+	StatusCode int       `json:"status_code,omitempty" msgpack:"status_code,omitempty" cbor:"status_code,omitempty" xml:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty" msgpack:"error,omitempty" cbor:"error,omitempty" xml:"error,omitempty"`
+}
+
+// FromDeliveries converts webhookDomain.Delivery values to a DeliveryListDTO
+func (d *DeliveryListDTO) FromDeliveries(deliveries []*webhookDomain.Delivery) {
+	d.Deliveries = make([]DeliveryDTO, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		deliveryDTO := DeliveryDTO{
+			ID:        delivery.ID,
+			EventID:   delivery.EventID,
+			EventType: string(delivery.EventType),
+			Status:    string(delivery.Status),
+			CreatedAt: delivery.CreatedAt,
+			Attempts:  make([]AttemptDTO, 0, len(delivery.Attempts)),
+		}
+		if delivery.Status == webhookDomain.DeliveryPending {
+			nextAttemptAt := delivery.NextAttemptAt
+			deliveryDTO.NextAttemptAt = &nextAttemptAt
+		}
+		for _, attempt := range delivery.Attempts {
+			deliveryDTO.Attempts = append(deliveryDTO.Attempts, AttemptDTO{At: attempt.At, StatusCode: attempt.StatusCode, Error: attempt.Error})
+		}
+		d.Deliveries = append(d.Deliveries, deliveryDTO)
+	}
+}
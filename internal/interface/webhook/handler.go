@@ -0,0 +1,205 @@
+// Package webhook contains the api layer for the webhook domain.
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	domainShared "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/shared"
+	webhookDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/codec"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/i18n"
+	"github.com/surajswarnapuri/ps-tag-onboarding-go/internal/interface/shared"
+)
+
+const (
+	webhooksRoute   = "/v1/webhooks"
+	webhookRoute    = "/v1/webhooks/{id}"
+	deliveriesRoute = "/v1/webhooks/{id}/deliveries"
+)
+
+// Message keys of the problems of the webhook api
+const (
+	messageWebhookInvalid  = "WEBHOOK_INVALID"
+	messageWebhookNotFound = "WEBHOOK_NOT_FOUND"
+)
+
+type webhookApplicationService interface {
+	// Subscribe creates a subscription
+	Subscribe(ctx context.Context, subscription *webhookDomain.Subscription) (*webhookDomain.Subscription, error)
+	// Find finds a subscription by id
+	Find(ctx context.Context, id string) (*webhookDomain.Subscription, error)
+	// List lists the subscriptions
+	List(ctx context.Context) ([]*webhookDomain.Subscription, error)
+	// Unsubscribe deletes a subscription
+	Unsubscribe(ctx context.Context, id string) error
+	// Deliveries returns the delivery log of a subscription
+	Deliveries(ctx context.Context, subscriptionID string) ([]*webhookDomain.Delivery, error)
+}
+
+// Handler is a handler for the webhook domain
+type Handler struct {
+	webhookService webhookApplicationService
+	codecs         *codec.Registry
+}
+
+// NewHandler creates a new handler for the webhook domain
+func NewHandler(webhookService webhookApplicationService) *Handler {
+	return &Handler{
+		webhookService: webhookService,
+		codecs:         codec.NewDefaultRegistry(),
+	}
+}
+
+// Create is the api handler for the POST /v1/webhooks route
+func (h Handler) Create() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(webhooksRoute).Methods("POST")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			var request SubscriptionRequestDTO
+			if err := h.codecs.Decode(w, r, &request); err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+
+			subscription, err := h.webhookService.Subscribe(r.Context(), request.ToEntity())
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			var response SubscriptionDTO
+			response.FromEntity(subscription)
+			response.Secret = subscription.Secret
+			if err := codec.Write(w, responseCodec, http.StatusCreated, response); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// List is the api handler for the GET /v1/webhooks route
+func (h Handler) List() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(webhooksRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			subscriptions, err := h.webhookService.List(r.Context())
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			response := SubscriptionListDTO{Subscriptions: make([]SubscriptionDTO, len(subscriptions))}
+			for i, subscription := range subscriptions {
+				response.Subscriptions[i].FromEntity(subscription)
+			}
+			if err := codec.Write(w, responseCodec, http.StatusOK, response); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// Find is the api handler for the GET /v1/webhooks/{id} route
+func (h Handler) Find() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(webhookRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			subscription, err := h.webhookService.Find(r.Context(), mux.Vars(r)["id"])
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			var response SubscriptionDTO
+			response.FromEntity(subscription)
+			if err := codec.Write(w, responseCodec, http.StatusOK, response); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// Delete is the api handler for the DELETE /v1/webhooks/{id} route
+func (h Handler) Delete() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(webhookRoute).Methods("DELETE")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			if err := h.webhookService.Unsubscribe(r.Context(), mux.Vars(r)["id"]); err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		},
+	}
+}
+
+// Deliveries is the api handler for the GET /v1/webhooks/{id}/deliveries route
+func (h Handler) Deliveries() shared.Handler {
+	return shared.Handler{
+		Route: func(r *mux.Route) {
+			r.Path(deliveriesRoute).Methods("GET")
+		},
+		Func: func(w http.ResponseWriter, r *http.Request) {
+			responseCodec, err := h.codecs.Negotiate(r)
+			if err != nil {
+				shared.WriteError(w, r, err)
+				return
+			}
+			deliveries, err := h.webhookService.Deliveries(r.Context(), mux.Vars(r)["id"])
+			if err != nil {
+				writeServiceError(w, r, err)
+				return
+			}
+			var response DeliveryListDTO
+			response.FromDeliveries(deliveries)
+			if err := codec.Write(w, responseCodec, http.StatusOK, response); err != nil {
+				shared.WriteError(w, r, err)
+			}
+		},
+	}
+}
+
+// writeServiceError writes a service error as a problem response,
+// reporting validation failures as bad requests and unknown subscriptions as not found.
+// The details are in the language of the Accept-Language header.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	localizer := i18n.FromRequest(r)
+	if validationErrors := domainShared.ValidationErrors(err); len(validationErrors) > 0 {
+		problem := shared.NewProblem(http.StatusBadRequest, localizer.Message(messageWebhookInvalid, nil, "webhook subscription is invalid"))
+		for _, validationError := range validationErrors {
+			// urls may hold credentials so rejected values are never sent back
+			problem.InvalidParams = append(problem.InvalidParams,
+				shared.NewInvalidParam(validationError.Redacted(), localizer.ValidationError(validationError)))
+		}
+		localizer.WriteHeaders(w.Header())
+		err = &shared.ProblemError{Problem: problem, Err: err}
+	} else if errors.Is(err, webhookDomain.ErrNotFound) {
+		localizer.WriteHeaders(w.Header())
+		err = &shared.ProblemError{Problem: shared.NewProblem(http.StatusNotFound,
+			localizer.Message(messageWebhookNotFound, nil, "webhook subscription not found")), Err: err}
+	}
+	shared.WriteError(w, r, err)
+}
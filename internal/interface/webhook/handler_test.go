@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	userDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/user"
+	webhookDomain "github.com/surajswarnapuri/ps-tag-onboarding-go/internal/domain/webhook"
+)
+
+type mockWebhookApplicationService struct {
+	SubscribeFunc   func(ctx context.Context, subscription *webhookDomain.Subscription) (*webhookDomain.Subscription, error)
+	FindFunc        func(ctx context.Context, id string) (*webhookDomain.Subscription, error)
+	ListFunc        func(ctx context.Context) ([]*webhookDomain.Subscription, error)
+	UnsubscribeFunc func(ctx context.Context, id string) error
+	DeliveriesFunc  func(ctx context.Context, subscriptionID string) ([]*webhookDomain.Delivery, error)
+}
+
+func (m *mockWebhookApplicationService) Subscribe(ctx context.Context, subscription *webhookDomain.Subscription) (*webhookDomain.Subscription, error) {
+	return m.SubscribeFunc(ctx, subscription)
+}
+
+func (m *mockWebhookApplicationService) Find(ctx context.Context, id string) (*webhookDomain.Subscription, error) {
+	return m.FindFunc(ctx, id)
+}
+
+func (m *mockWebhookApplicationService) List(ctx context.Context) ([]*webhookDomain.Subscription, error) {
+	return m.ListFunc(ctx)
+}
+
+func (m *mockWebhookApplicationService) Unsubscribe(ctx context.Context, id string) error {
+	return m.UnsubscribeFunc(ctx, id)
+}
+
+func (m *mockWebhookApplicationService) Deliveries(ctx context.Context, subscriptionID string) ([]*webhookDomain.Delivery, error) {
+	return m.DeliveriesFunc(ctx, subscriptionID)
+}
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		subscribeFunc  func(ctx context.Context, subscription *webhookDomain.Subscription) (*webhookDomain.Subscription, error)
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "subscription created with its secret",
+			body: `{"url": "https://partner.example.com/hooks", "events": ["user.created"]}`,
+			subscribeFunc: func(ctx context.Context, subscription *webhookDomain.Subscription) (*webhookDomain.Subscription, error) {
+				subscription.ID = "s1"
+				subscription.Secret = "generated-secret-0001"
+				return subscription, nil
+			},
+			expectedStatus: http.StatusCreated,
+			expectedBody:   `"secret":"generated-secret-0001"`,
+		},
+		{
+			name: "invalid subscription",
+			body: `{"url": "ftp://partner.example.com", "events": ["user.renamed"]}`,
+			subscribeFunc: func(ctx context.Context, subscription *webhookDomain.Subscription) (*webhookDomain.Subscription, error) {
+				return nil, fmt.Errorf("webhook: %w", subscription.Validate())
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `"name":"events"`,
+		},
+		{
+			name:           "unknown field",
+			body:           `{"uri": "https://partner.example.com/hooks"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockWebhookApplicationService{SubscribeFunc: test.subscribeFunc}).Create().AddRoute(r)
+			req := httptest.NewRequest("POST", "/v1/webhooks", strings.NewReader(test.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), test.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestList_OmitsSecrets(t *testing.T) {
+	r := mux.NewRouter()
+	NewHandler(&mockWebhookApplicationService{
+		ListFunc: func(ctx context.Context) ([]*webhookDomain.Subscription, error) {
+			return []*webhookDomain.Subscription{{ID: "s1", URL: "https://partner.example.com/hooks", Secret: "0123456789abcdef"}}, nil
+		},
+	}).List().AddRoute(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/webhooks", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status code %d, got %d", http.StatusOK, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "0123456789abcdef") {
+		t.Errorf("expected the secret to be omitted, got %s", w.Body.String())
+	}
+}
+
+func TestDeliveries(t *testing.T) {
+	attemptAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name           string
+		deliveriesFunc func(ctx context.Context, subscriptionID string) ([]*webhookDomain.Delivery, error)
+		expectedStatus int
+	}{
+		{
+			name: "delivery log",
+			deliveriesFunc: func(ctx context.Context, subscriptionID string) ([]*webhookDomain.Delivery, error) {
+				return []*webhookDomain.Delivery{{
+					ID: "s1-e1", SubscriptionID: subscriptionID, EventID: "e1", EventType: userDomain.EventUserCreated,
+					Status: webhookDomain.DeliveryPending, NextAttemptAt: attemptAt.Add(time.Minute),
+					Attempts: []webhookDomain.Attempt{{At: attemptAt, StatusCode: 500, Error: "unexpected status 500"}},
+				}}, nil
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "unknown subscription",
+			deliveriesFunc: func(ctx context.Context, subscriptionID string) ([]*webhookDomain.Delivery, error) {
+				return nil, fmt.Errorf("webhook: %w", webhookDomain.ErrNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := mux.NewRouter()
+			NewHandler(&mockWebhookApplicationService{DeliveriesFunc: test.deliveriesFunc}).Deliveries().AddRoute(r)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/webhooks/s1/deliveries", nil))
+
+			if w.Code != test.expectedStatus {
+				t.Fatalf("expected status code %d, got %d: %s", test.expectedStatus, w.Code, w.Body.String())
+			}
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+			var log DeliveryListDTO
+			if err := json.NewDecoder(w.Body).Decode(&log); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(log.Deliveries) != 1 || len(log.Deliveries[0].Attempts) != 1 || log.Deliveries[0].NextAttemptAt == nil {
+				t.Fatalf("expected one pending delivery with one attempt, got %+v", log)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	r := mux.NewRouter()
+	NewHandler(&mockWebhookApplicationService{
+		UnsubscribeFunc: func(ctx context.Context, id string) error { return nil },
+	}).Delete().AddRoute(r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("DELETE", "/v1/webhooks/s1", nil))
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected status code %d, got %d", http.StatusNoContent, w.Code)
+	}
+}